@@ -1,6 +1,9 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strings"
+)
 
 type Config struct {
 	Provider        string
@@ -8,6 +11,24 @@ type Config struct {
 	OpenAIAPIKey    string
 	AnthropicAPIKey string
 	Env             string
+	ProjectDir      string
+
+	// InternalDomains are domains whose hosts are redacted as internal
+	InternalDomains []string
+
+	// AuditLog is the path of the JSONL audit log; auditing is off when empty
+	AuditLog string
+	// AuditFullPrompt records full prompts instead of their hashes
+	AuditFullPrompt bool
+
+	// PolicyFile is an egress policy file used for every script; when empty,
+	// the policy.json of the project holding the script is used
+	PolicyFile string
+
+	AzureOpenAIAPIKey     string
+	AzureOpenAIEndpoint   string
+	AzureOpenAIDeployment string
+	AzureOpenAIAPIVersion string
 }
 
 func NewConfig() *Config {
@@ -22,12 +43,30 @@ func NewConfig() *Config {
 		geminiKey = os.Getenv("API_KEY")
 	}
 
+	projectDir := os.Getenv("GOPHERSCRIPT_DIR")
+	if projectDir == "" {
+		projectDir = ".gopherscript" // default project state directory
+	}
+
 	return &Config{
 		Provider:        provider,
 		GeminiAPIKey:    geminiKey,
 		OpenAIAPIKey:    os.Getenv("OPENAI_API_KEY"),
 		AnthropicAPIKey: os.Getenv("ANTHROPIC_API_KEY"),
 		Env:             os.Getenv("ENV"),
+		ProjectDir:      projectDir,
+
+		InternalDomains: splitList(os.Getenv("GOPHERSCRIPT_INTERNAL_DOMAINS")),
+
+		AuditLog:        os.Getenv("GOPHERSCRIPT_AUDIT_LOG"),
+		AuditFullPrompt: os.Getenv("GOPHERSCRIPT_AUDIT_PROMPT") == "full",
+
+		PolicyFile: os.Getenv("GOPHERSCRIPT_POLICY"),
+
+		AzureOpenAIAPIKey:     os.Getenv("AZURE_OPENAI_API_KEY"),
+		AzureOpenAIEndpoint:   os.Getenv("AZURE_OPENAI_ENDPOINT"),
+		AzureOpenAIDeployment: os.Getenv("AZURE_OPENAI_DEPLOYMENT"),
+		AzureOpenAIAPIVersion: os.Getenv("AZURE_OPENAI_API_VERSION"),
 	}
 }
 
@@ -40,7 +79,36 @@ func (c *Config) GetAPIKey(provider string) string {
 		return c.OpenAIAPIKey
 	case "claude":
 		return c.AnthropicAPIKey
+	case "azure-openai":
+		return c.AzureOpenAIAPIKey
+	default:
+		return ""
+	}
+}
+
+// APIKeyEnvVar returns the environment variable that holds the API key for the specified provider
+func APIKeyEnvVar(provider string) string {
+	switch provider {
+	case "gemini":
+		return "GEMINI_API_KEY (or API_KEY)"
+	case "openai":
+		return "OPENAI_API_KEY"
+	case "claude":
+		return "ANTHROPIC_API_KEY"
+	case "azure-openai":
+		return "AZURE_OPENAI_API_KEY"
 	default:
 		return ""
 	}
 }
+
+// splitList splits a comma-separated environment value, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// FileConfig is the optional project configuration stored in <ProjectDir>/config.json
+type FileConfig struct {
+	Scan         ScanConfig       `json:"scan"`
+	Dependencies DependencyConfig `json:"dependencies"`
+}
+
+// DependencyConfig restricts the imports of generated code. Mode is
+// "stdlib-only" (the default) or "allowlist".
+type DependencyConfig struct {
+	Mode    string        `json:"mode,omitempty"`
+	Modules []ModuleEntry `json:"modules,omitempty"`
+}
+
+// ModuleEntry is a module generated code may import
+type ModuleEntry struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+// ScanConfig configures the sensitive-data scanner
+type ScanConfig struct {
+	Allowlist []ScanAllowEntry `json:"allowlist"`
+}
+
+// ScanAllowEntry accepts scanner findings. Empty fields match anything.
+type ScanAllowEntry struct {
+	Rule  string `json:"rule,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// LoadFileConfig reads <dir>/config.json. A missing file yields an empty configuration.
+func LoadFileConfig(dir string) (*FileConfig, error) {
+	path := filepath.Join(dir, "config.json")
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &FileConfig{}, nil
+		}
+		return nil, fmt.Errorf("failed to read config file: %s: %w", path, err)
+	}
+
+	var fc FileConfig
+	if err := json.Unmarshal(data, &fc); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %s: %w", path, err)
+	}
+
+	return &fc, nil
+}
@@ -0,0 +1,154 @@
+package batch
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bonzonkim/gopher-script/internal/deps"
+	"github.com/bonzonkim/gopher-script/internal/metadata"
+	"github.com/bonzonkim/gopher-script/internal/notebook"
+	"github.com/bonzonkim/gopher-script/internal/portability"
+	"github.com/bonzonkim/gopher-script/internal/redact"
+	"github.com/bonzonkim/gopher-script/internal/taskfile"
+)
+
+// Job is a provider batch job submitted by GopherScript
+type Job struct {
+	ID          string     `json:"id"`
+	Provider    string     `json:"provider"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CollectedAt *time.Time `json:"collected_at,omitempty"`
+	GoVersion   string     `json:"go_version,omitempty"`
+	Entries     []Entry    `json:"entries"`
+}
+
+// Entry maps a request in the batch back to the script it was built from
+type Entry struct {
+	CustomID   string `json:"custom_id"`
+	InputPath  string `json:"input_path"`
+	OutputPath string `json:"output_path"`
+	ScriptType string `json:"script_type"`
+	InputHash  string `json:"input_hash,omitempty"`
+	PromptHash string `json:"prompt_hash,omitempty"`
+	Build      bool   `json:"build,omitempty"`
+	BinaryPath string `json:"binary_path,omitempty"`
+
+	// Metadata describes how the entry's prompt was built; the rest is
+	// filled in when the result is collected
+	Metadata *metadata.Metadata `json:"metadata,omitempty"`
+
+	// Script is the script as it was sent and the rest are the reports on
+	// it, kept so that collecting does not depend on the file since then
+	Script         string               `json:"script,omitempty"`
+	ScriptPackages []deps.ScriptPackage `json:"script_packages,omitempty"`
+	NonPortable    []portability.Issue  `json:"non_portable,omitempty"`
+	Tasks          *taskfile.File       `json:"tasks,omitempty"`
+	Notebook       *notebook.Notebook   `json:"notebook,omitempty"`
+
+	// Redactions are the values replaced in the script that was sent, and
+	// RedactPolicy how they are resolved in the Go code
+	Redactions   []redact.Redaction `json:"redactions,omitempty"`
+	RedactPolicy string             `json:"redact_policy,omitempty"`
+}
+
+// Entry returns the entry with the given custom ID
+func (j *Job) Entry(customID string) (*Entry, bool) {
+	for i := range j.Entries {
+		if j.Entries[i].CustomID == customID {
+			return &j.Entries[i], true
+		}
+	}
+	return nil, false
+}
+
+// CustomID returns the request ID used for the n-th script in a batch
+func CustomID(n int) string {
+	return fmt.Sprintf("script-%04d", n)
+}
+
+// Store persists batch jobs as JSON files in a directory
+type Store struct {
+	dir string
+}
+
+// NewStore creates a new Store rooted at dir
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// Save writes a job to the store, replacing any previous version
+func (s *Store) Save(job *Job) error {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create batch directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(job, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch job: %w", err)
+	}
+
+	// Jobs keep the scripts and their redacted values
+	if err := os.WriteFile(s.path(job.ID), data, 0600); err != nil {
+		return fmt.Errorf("failed to write batch job: %w", err)
+	}
+
+	return nil
+}
+
+// Load reads the job with the given ID
+func (s *Store) Load(id string) (*Job, error) {
+	data, err := os.ReadFile(s.path(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("batch job not found: %s", id)
+		}
+		return nil, fmt.Errorf("failed to read batch job: %w", err)
+	}
+
+	var job Job
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal batch job %s: %w", id, err)
+	}
+
+	return &job, nil
+}
+
+// List returns all stored jobs, newest first
+func (s *Store) List() ([]*Job, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read batch directory: %w", err)
+	}
+
+	var jobs []*Job
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".json" {
+			continue
+		}
+
+		job, err := s.Load(strings.TrimSuffix(e.Name(), ".json"))
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, job)
+	}
+
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+
+	return jobs, nil
+}
+
+// path returns the file path for a job ID
+func (s *Store) path(id string) string {
+	return filepath.Join(s.dir, filepath.Base(id)+".json")
+}
@@ -0,0 +1,104 @@
+package batch
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bonzonkim/gopher-script/internal/deps"
+	"github.com/bonzonkim/gopher-script/internal/notebook"
+	"github.com/bonzonkim/gopher-script/internal/portability"
+	"github.com/bonzonkim/gopher-script/internal/taskfile"
+)
+
+func TestStore_SaveLoad(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	job := &Job{
+		ID:        "batch_abc123",
+		Provider:  "openai",
+		CreatedAt: time.Now().UTC(),
+		Entries: []Entry{
+			{CustomID: CustomID(1), InputPath: "a.py", OutputPath: "a.go", ScriptType: "python"},
+		},
+	}
+
+	if err := store.Save(job); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load(job.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if loaded.Provider != "openai" || len(loaded.Entries) != 1 {
+		t.Errorf("Loaded job mismatch: %+v", loaded)
+	}
+
+	entry, ok := loaded.Entry("script-0001")
+	if !ok || entry.InputPath != "a.py" {
+		t.Errorf("Entry lookup failed: %+v", entry)
+	}
+}
+
+func TestStore_List(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	older := &Job{ID: "older", CreatedAt: time.Now().Add(-time.Hour)}
+	newer := &Job{ID: "newer", CreatedAt: time.Now()}
+	for _, job := range []*Job{older, newer} {
+		if err := store.Save(job); err != nil {
+			t.Fatalf("Save failed: %v", err)
+		}
+	}
+
+	jobs, err := store.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	if len(jobs) != 2 || jobs[0].ID != "newer" {
+		t.Errorf("Expected newest job first, got %+v", jobs)
+	}
+}
+
+func TestStore_LoadMissing(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	if _, err := store.Load("missing"); err == nil {
+		t.Error("Expected error for missing job, got nil")
+	}
+}
+
+func TestStore_SaveLoadReports(t *testing.T) {
+	store := NewStore(t.TempDir())
+
+	tasks, err := taskfile.Parse(taskfile.KindMake, "build:\n\tgo build ./...\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	job := &Job{
+		ID: "batch_reports",
+		Entries: []Entry{{
+			CustomID:       CustomID(1),
+			InputPath:      "Makefile",
+			Script:         "#!/bin/bash\ngo build ./...\n",
+			ScriptPackages: []deps.ScriptPackage{{Name: "axios", Version: "^1.6.0"}},
+			NonPortable:    []portability.Issue{{Line: 3, Command: "Get-WmiObject", Reason: "Windows only"}},
+			Tasks:          tasks,
+			Notebook:       &notebook.Notebook{Script: "print(1)\n", CodeCells: 1},
+		}},
+	}
+	if err := store.Save(job); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := store.Load(job.ID)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded.Entries[0], job.Entries[0]) {
+		t.Errorf("Loaded entry mismatch:\n got %+v\nwant %+v", loaded.Entries[0], job.Entries[0])
+	}
+}
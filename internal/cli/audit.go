@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bonzonkim/gopher-script/config"
+	"github.com/bonzonkim/gopher-script/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var (
+	auditSince   string
+	auditFile    string
+	auditOutcome string
+	auditUser    string
+	auditJSON    bool
+)
+
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Query the audit log of LLM exchanges.",
+		Long: `Query the audit log of source code sent to LLM providers.
+
+Auditing is enabled with --audit-log or the GOPHERSCRIPT_AUDIT_LOG environment
+variable. Each record holds the timestamp, user, input file and hash, provider,
+model, prompt (or its hash), response hash, token counts and outcome.
+Set GOPHERSCRIPT_AUDIT_PROMPT=full to record full prompts instead of hashes.
+
+Examples:
+  gopherscript audit --audit-log audit.jsonl                  # Show all records
+  gopherscript audit --since 24h --provider openai            # Recent OpenAI exchanges
+  gopherscript audit --file scripts/deploy.sh --json          # Records for one script as JSON`,
+		Args: cobra.NoArgs,
+		RunE: runAudit,
+	}
+
+	cmd.Flags().StringVar(&auditSince, "since", "", "Only show records newer than a duration (e.g. 24h) or date (YYYY-MM-DD)")
+	cmd.Flags().StringVar(&auditFile, "file", "", "Only show records for this input file")
+	cmd.Flags().StringVar(&auditOutcome, "outcome", "", "Only show records with this outcome (success, error, batch_submitted)")
+	cmd.Flags().StringVar(&auditUser, "user", "", "Only show records for this user")
+	cmd.Flags().BoolVar(&auditJSON, "json", false, "Print matching records as JSON lines")
+
+	return cmd
+}
+
+// auditLogPath returns the audit log path from the flag or the environment
+func auditLogPath(cfg *config.Config) string {
+	if auditLog != "" {
+		return auditLog
+	}
+	return cfg.AuditLog
+}
+
+// parseSince parses a duration relative to now or a calendar date
+func parseSince(s string) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(-d), nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", s, time.Local); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since value '%s': use a duration (24h) or a date (2006-01-02)", s)
+}
+
+func runAudit(cmd *cobra.Command, args []string) error {
+	cfg := config.NewConfig()
+
+	path := auditLogPath(cfg)
+	if path == "" {
+		return fmt.Errorf("no audit log configured: pass --audit-log or set GOPHERSCRIPT_AUDIT_LOG")
+	}
+
+	filter := llm.AuditFilter{
+		Provider:  provider,
+		InputPath: auditFile,
+		Outcome:   auditOutcome,
+		User:      auditUser,
+	}
+	if auditSince != "" {
+		since, err := parseSince(auditSince)
+		if err != nil {
+			return err
+		}
+		filter.Since = since
+	}
+
+	entries, err := llm.ReadAuditLog(path, filter)
+	if err != nil {
+		return err
+	}
+
+	if auditJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := encoder.Encode(e); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if len(entries) == 0 {
+		fmt.Fprintln(os.Stdout, "No matching audit records.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TIME\tUSER\tPROVIDER\tMODEL\tFILE\tTOKENS (IN/OUT)\tOUTCOME")
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%d/%d\t%s\n",
+			e.Timestamp.Local().Format("2006-01-02 15:04:05"), valueOrDash(e.User), e.Provider,
+			valueOrDash(e.Model), valueOrDash(e.InputPath), e.InputTokens, e.OutputTokens, e.Outcome)
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,263 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bonzonkim/gopher-script/config"
+	"github.com/bonzonkim/gopher-script/internal/batch"
+	"github.com/bonzonkim/gopher-script/internal/handler"
+	"github.com/bonzonkim/gopher-script/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+var (
+	batchOutputDir string
+	batchBuild     bool
+)
+
+func newBatchCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "batch",
+		Short: "Transpile many scripts through a provider batch API.",
+		Long: `Submit scripts as a single asynchronous batch job and collect the results later.
+
+Batch jobs are processed by the provider within 24 hours at a reduced price,
+which suits large migrations. Supported providers: openai, claude.
+
+Examples:
+  gopherscript batch submit scripts/*.py -p openai          # Submit a batch job
+  gopherscript batch submit scripts/*.sh -p claude --build  # Build the programs when collecting
+  gopherscript batch list                                   # List submitted jobs
+  gopherscript batch status batch_abc123                    # Check a job's progress
+  gopherscript batch collect batch_abc123                   # Write Go files for finished results`,
+	}
+
+	submitCmd := &cobra.Command{
+		Use:   "submit [files...]",
+		Short: "Submit scripts as a batch job.",
+		Args:  cobra.MinimumNArgs(1),
+		RunE:  runBatchSubmit,
+	}
+	submitCmd.Flags().StringVarP(&batchOutputDir, "output-dir", "o", "", "Directory for the generated Go files (default: next to each script)")
+	submitCmd.Flags().BoolVar(&batchBuild, "build", false, "Build each generated Go file into a binary when collecting")
+	submitCmd.Flags().BoolVar(&redactSecrets, "redact", false, "Replace secrets, internal hosts and IPs with placeholders before submitting")
+	submitCmd.Flags().StringVar(&redactPolicy, "redact-policy", "restore", "How to resolve redacted values in the Go code when collecting (restore, env)")
+	submitCmd.Flags().BoolVar(&allowSensitive, "allow-sensitive", false, "Submit scripts even when the sensitive-data scan finds something")
+	submitCmd.Flags().StringVar(&injectionMode, "injection", "warn", "How to handle possible prompt injection in the scripts (warn, block)")
+	submitCmd.Flags().StringVar(&scanFormat, "scan-format", "text", "Output format for sensitive-data findings (text, json)")
+
+	statusCmd := &cobra.Command{
+		Use:   "status [job-id]",
+		Short: "Show the provider status of a batch job.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runBatchStatus,
+	}
+
+	collectCmd := &cobra.Command{
+		Use:   "collect [job-id]",
+		Short: "Fetch the results of a finished batch job and write the Go files.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runBatchCollect,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List locally recorded batch jobs.",
+		Args:  cobra.NoArgs,
+		RunE:  runBatchList,
+	}
+
+	cmd.AddCommand(submitCmd, statusCmd, collectCmd, listCmd)
+
+	return cmd
+}
+
+// batchStore returns the store holding locally recorded batch jobs
+func batchStore(cfg *config.Config) *batch.Store {
+	return batch.NewStore(filepath.Join(cfg.ProjectDir, "batches"))
+}
+
+func runBatchSubmit(cmd *cobra.Command, args []string) error {
+	cfg := config.NewConfig()
+
+	selectedProvider := cfg.Provider
+	if provider != "" {
+		selectedProvider = provider
+	}
+
+	policy := redact.Policy(redactPolicy)
+	if !policy.IsValid() {
+		return fmt.Errorf("invalid redact policy '%s'. Valid policies: %s, %s", redactPolicy, redact.PolicyRestore, redact.PolicyEnv)
+	}
+
+	allowlist, err := scanAllowlist(cfg)
+	if err != nil {
+		return err
+	}
+
+	injectionPolicy, err := parseInjectionMode()
+	if err != nil {
+		return err
+	}
+
+	h, log, err := newHandler(cfg, selectedProvider)
+	if err != nil {
+		return err
+	}
+	defer log.Logger.Sync()
+
+	targetDir := filepath.Dir(args[0])
+	if batchOutputDir != "" {
+		targetDir = batchOutputDir
+	}
+	if h.GoVersion, err = targetGoVersion(log, targetDir); err != nil {
+		return err
+	}
+
+	job, err := h.SubmitBatch(handler.BatchSubmitOptions{
+		InputPaths: args,
+		OutputDir:  batchOutputDir,
+		Build:      batchBuild,
+
+		Redact:          redactSecrets,
+		RedactPolicy:    policy,
+		InternalDomains: cfg.InternalDomains,
+		AllowSensitive:  allowSensitive,
+		ScanAllowlist:   allowlist,
+		Injection:       injectionPolicy,
+	})
+	if err != nil {
+		reportBlocked(err)
+		return fmt.Errorf("batch submission failed: %w", err)
+	}
+
+	if err := batchStore(cfg).Save(job); err != nil {
+		return fmt.Errorf("batch %s was submitted but could not be recorded: %w", job.ID, err)
+	}
+
+	fmt.Fprintf(os.Stdout, "✅ Submitted batch job: %s (using %s, %d scripts)\n", job.ID, job.Provider, len(job.Entries))
+	fmt.Fprintf(os.Stdout, "   Collect results with: gopherscript batch collect %s\n", job.ID)
+
+	return nil
+}
+
+func runBatchStatus(cmd *cobra.Command, args []string) error {
+	cfg := config.NewConfig()
+
+	job, err := batchStore(cfg).Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	h, log, err := newHandler(cfg, job.Provider)
+	if err != nil {
+		return err
+	}
+	defer log.Logger.Sync()
+
+	status, err := h.BatchStatus(job)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stdout, "Batch job: %s (using %s)\n", job.ID, job.Provider)
+	fmt.Fprintf(os.Stdout, "   Status:  %s\n", status.State)
+	fmt.Fprintf(os.Stdout, "   Scripts: %d\n", len(job.Entries))
+	if status.Done {
+		fmt.Fprintf(os.Stdout, "   Ready to collect: gopherscript batch collect %s\n", job.ID)
+	}
+
+	return nil
+}
+
+func runBatchCollect(cmd *cobra.Command, args []string) error {
+	cfg := config.NewConfig()
+	store := batchStore(cfg)
+
+	job, err := store.Load(args[0])
+	if err != nil {
+		return err
+	}
+
+	h, log, err := newHandler(cfg, job.Provider)
+	if err != nil {
+		return err
+	}
+	defer log.Logger.Sync()
+
+	h.GoVersion = job.GoVersion
+	items, err := h.CollectBatch(job)
+	if err != nil {
+		return fmt.Errorf("batch collection failed: %w", err)
+	}
+
+	if err := store.Save(job); err != nil {
+		return err
+	}
+
+	var failed int
+	for _, item := range items {
+		if item.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stdout, "❌ %s: %v\n", item.InputPath, item.Err)
+			continue
+		}
+
+		fmt.Fprintf(os.Stdout, "✅ %s\n", item.InputPath)
+		fmt.Fprintf(os.Stdout, "   Go file: %s\n", item.Result.OutputPath)
+		if item.Result.MetadataPath != "" {
+			fmt.Fprintf(os.Stdout, "   Metadata: %s\n", item.Result.MetadataPath)
+		}
+		if item.Result.GoModPath != "" {
+			fmt.Fprintf(os.Stdout, "   go.mod:  %s (%s)\n", item.Result.GoModPath, moduleList(item.Result.Modules))
+		}
+		if item.Result.BinaryPath != "" {
+			fmt.Fprintf(os.Stdout, "   Binary:  %s\n", item.Result.BinaryPath)
+		}
+		printScriptPackages(item.Result.ScriptPackages)
+		printNonPortable(item.Result.NonPortable)
+		printTasks(item.Result.Tasks)
+		printNotebook(item.Result.Notebook)
+		printDivergences(item.Result.Divergences)
+		printCompatibility(item.Result.Compatibility, job.GoVersion)
+		if item.Result.CLIStyleIssue != "" {
+			fmt.Fprintf(os.Stdout, "⚠️  Generated code does not follow --cli-style %s: %s\n", h.CLIStyle, item.Result.CLIStyleIssue)
+		}
+		if item.RedactPolicy != "" {
+			printRedactionReport(item.Result.Redactions, item.RedactPolicy)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d scripts failed", failed, len(items))
+	}
+
+	return nil
+}
+
+func runBatchList(cmd *cobra.Command, args []string) error {
+	cfg := config.NewConfig()
+
+	jobs, err := batchStore(cfg).List()
+	if err != nil {
+		return err
+	}
+
+	if len(jobs) == 0 {
+		fmt.Fprintln(os.Stdout, "No batch jobs recorded.")
+		return nil
+	}
+
+	for _, job := range jobs {
+		state := "submitted"
+		if job.CollectedAt != nil {
+			state = "collected"
+		}
+		fmt.Fprintf(os.Stdout, "%s  %-12s  %3d scripts  %s  %s\n",
+			job.ID, job.Provider, len(job.Entries), job.CreatedAt.Local().Format("2006-01-02 15:04"), state)
+	}
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bonzonkim/gopher-script/internal/injection"
+)
+
+var injectionMode string
+
+// parseInjectionMode validates --injection
+func parseInjectionMode() (injection.Mode, error) {
+	mode := injection.Mode(injectionMode)
+	if !mode.IsValid() {
+		valid := make([]string, len(injection.ValidModes()))
+		for i, m := range injection.ValidModes() {
+			valid[i] = string(m)
+		}
+		return "", fmt.Errorf("invalid injection mode '%s'. Valid modes: %s", injectionMode, strings.Join(valid, ", "))
+	}
+	return mode, nil
+}
+
+// printInjections warns about script text that could steer the LLM
+func printInjections(findings []injection.Finding) {
+	if len(findings) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "⚠️  Sent despite %d possible prompt injection(s) (--injection warn):\n", len(findings))
+	injection.WriteText(os.Stdout, findings)
+}
+
+// printDivergences warns about behavior of the generated code that the
+// script does not show
+func printDivergences(divergences []injection.Divergence) {
+	if len(divergences) == 0 {
+		return
+	}
+
+	fmt.Fprintln(os.Stdout, "⚠️  Generated code does things the script does not; review it before running:")
+	for _, d := range divergences {
+		fmt.Fprintf(os.Stdout, "   %s\n", d)
+	}
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/bonzonkim/gopher-script/config"
+	"github.com/bonzonkim/gopher-script/internal/handler"
+	"github.com/bonzonkim/gopher-script/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+var promptTemplate string
+
+func newPromptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prompt [file]",
+		Short: "Print the prompt that would be sent for a script.",
+		Long: `Render the effective transpile prompt for a script without contacting the provider.
+
+Prompts are text/template templates. The built-in templates can be overridden by
+placing transpile.tmpl or refine.tmpl in .gopherscript/prompts, or by passing
+--prompt-template for the transpile prompt.
+
+Examples:
+  gopherscript prompt script.py                                  # Print the prompt
+  gopherscript prompt script.sh --redact                         # Print the redacted prompt
+  gopherscript prompt script.py --prompt-template house.tmpl     # Preview a custom template`,
+		Args: cobra.ExactArgs(1),
+		RunE: runPrompt,
+	}
+
+	cmd.Flags().BoolVar(&redactSecrets, "redact", false, "Replace secrets, internal hosts and IPs with placeholders")
+	cmd.Flags().BoolVar(&allowSensitive, "allow-sensitive", false, "Render the prompt even when the sensitive-data scan finds something")
+	cmd.Flags().StringVar(&injectionMode, "injection", "warn", "How to handle possible prompt injection in the script (warn, block)")
+
+	return cmd
+}
+
+func runPrompt(cmd *cobra.Command, args []string) error {
+	cfg := config.NewConfig()
+
+	selectedProvider := cfg.Provider
+	if provider != "" {
+		selectedProvider = provider
+	}
+
+	llmProvider, err := parseProvider(selectedProvider)
+	if err != nil {
+		return err
+	}
+
+	allowlist, err := scanAllowlist(cfg)
+	if err != nil {
+		return err
+	}
+
+	injectionPolicy, err := parseInjectionMode()
+	if err != nil {
+		return err
+	}
+
+	log := newLogger(cfg)
+	defer log.Logger.Sync()
+
+	h := handler.NewPromptHandler(log.Logger, clientConfig(cfg, llmProvider))
+	if err := configureHandler(h, cfg); err != nil {
+		return err
+	}
+	if h.GoVersion, err = targetGoVersion(log, filepath.Dir(args[0])); err != nil {
+		return err
+	}
+
+	prompt, err := h.Prompt(handler.TranspileOptions{
+		InputPath:       args[0],
+		Redact:          redactSecrets,
+		InternalDomains: cfg.InternalDomains,
+		AllowSensitive:  allowSensitive,
+		ScanAllowlist:   allowlist,
+		Injection:       injectionPolicy,
+	})
+	if err != nil {
+		reportBlocked(err)
+		return err
+	}
+
+	fmt.Fprintln(os.Stdout, prompt)
+	return nil
+}
+
+// promptTemplates loads the prompt templates, applying overrides from the
+// project prompts directory and then --prompt-template
+func promptTemplates(cfg *config.Config) (*llm.PromptTemplates, error) {
+	templates := llm.DefaultPromptTemplates()
+	if err := templates.LoadDir(filepath.Join(cfg.ProjectDir, "prompts")); err != nil {
+		return nil, err
+	}
+
+	if promptTemplate != "" {
+		if err := templates.LoadFile(llm.TemplateTranspile, promptTemplate); err != nil {
+			return nil, err
+		}
+	}
+
+	return templates, nil
+}
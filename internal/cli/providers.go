@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bonzonkim/gopher-script/config"
+	"github.com/bonzonkim/gopher-script/internal/llm"
+	"github.com/spf13/cobra"
+)
+
+func newProvidersCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "providers",
+		Short: "Show the configuration state of each LLM provider.",
+		Long: `Show whether each LLM provider has an API key, which base URL it talks to
+and which model GopherScript requests from it.
+
+Examples:
+  gopherscript providers              # Show configuration state
+  gopherscript providers check        # Verify credentials with an authenticated call
+  gopherscript providers models openai # List models available from a provider`,
+		Args: cobra.NoArgs,
+		RunE: runProviders,
+	}
+
+	checkCmd := &cobra.Command{
+		Use:   "check",
+		Short: "Verify each configured provider's credentials.",
+		Args:  cobra.NoArgs,
+		RunE:  runProvidersCheck,
+	}
+
+	modelsCmd := &cobra.Command{
+		Use:   "models [provider]",
+		Short: "List the models available from a provider.",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runProvidersModels,
+	}
+
+	cmd.AddCommand(checkCmd, modelsCmd)
+
+	return cmd
+}
+
+// providerBaseURL returns the base URL a provider is configured to use
+func providerBaseURL(cfg *config.Config, p llm.Provider) string {
+	if p == llm.ProviderAzureOpenAI {
+		return cfg.AzureOpenAIEndpoint
+	}
+	return p.DefaultBaseURL()
+}
+
+// providerModel returns the model a provider is configured to use
+func providerModel(cfg *config.Config, p llm.Provider) string {
+	if p == llm.ProviderAzureOpenAI {
+		return cfg.AzureOpenAIDeployment
+	}
+	return p.DefaultModel()
+}
+
+func runProviders(cmd *cobra.Command, args []string) error {
+	cfg := config.NewConfig()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PROVIDER\tDEFAULT\tAPI KEY\tBASE URL\tMODEL")
+
+	for _, p := range llm.ValidProviders() {
+		isDefault := ""
+		if string(p) == cfg.Provider {
+			isDefault = "*"
+		}
+
+		keyState := "missing (" + config.APIKeyEnvVar(string(p)) + ")"
+		if cfg.GetAPIKey(string(p)) != "" {
+			keyState = "set"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", p, isDefault, keyState,
+			valueOrDash(providerBaseURL(cfg, p)), valueOrDash(providerModel(cfg, p)))
+	}
+
+	return w.Flush()
+}
+
+func runProvidersCheck(cmd *cobra.Command, args []string) error {
+	cfg := config.NewConfig()
+	log := newLogger(cfg)
+	defer log.Logger.Sync()
+
+	var failed int
+	for _, p := range llm.ValidProviders() {
+		if cfg.GetAPIKey(string(p)) == "" {
+			fmt.Fprintf(os.Stdout, "⏭️  %-13s not configured (%s)\n", p, config.APIKeyEnvVar(string(p)))
+			continue
+		}
+
+		client, err := llm.NewClient(clientConfig(cfg, p), log.Logger)
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stdout, "❌ %-13s %v\n", p, err)
+			continue
+		}
+
+		lister, ok := client.(llm.ModelLister)
+		if !ok {
+			fmt.Fprintf(os.Stdout, "⏭️  %-13s check not supported\n", p)
+			continue
+		}
+
+		start := time.Now()
+		if _, err := lister.ListModels(); err != nil {
+			failed++
+			fmt.Fprintf(os.Stdout, "❌ %-13s %v\n", p, err)
+			continue
+		}
+
+		fmt.Fprintf(os.Stdout, "✅ %-13s ok (%s)\n", p, time.Since(start).Round(time.Millisecond))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d provider check(s) failed", failed)
+	}
+
+	return nil
+}
+
+func runProvidersModels(cmd *cobra.Command, args []string) error {
+	cfg := config.NewConfig()
+
+	p, err := parseProvider(args[0])
+	if err != nil {
+		return err
+	}
+
+	if cfg.GetAPIKey(string(p)) == "" {
+		return fmt.Errorf("%s environment variable is not set for provider '%s'", config.APIKeyEnvVar(string(p)), p)
+	}
+
+	log := newLogger(cfg)
+	defer log.Logger.Sync()
+
+	client, err := llm.NewClient(clientConfig(cfg, p), log.Logger)
+	if err != nil {
+		return err
+	}
+
+	lister, ok := client.(llm.ModelLister)
+	if !ok {
+		return fmt.Errorf("provider %s does not support listing models", p)
+	}
+
+	models, err := lister.ListModels()
+	if err != nil {
+		return fmt.Errorf("failed to list models: %w", err)
+	}
+
+	for _, m := range models {
+		fmt.Fprintln(os.Stdout, m)
+	}
+
+	return nil
+}
+
+// valueOrDash returns "-" for empty table cells
+func valueOrDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
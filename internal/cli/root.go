@@ -3,13 +3,26 @@ package cli
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/bonzonkim/gopher-script/config"
+	"github.com/bonzonkim/gopher-script/internal/clistyle"
+	"github.com/bonzonkim/gopher-script/internal/deps"
+	"github.com/bonzonkim/gopher-script/internal/examples"
+	"github.com/bonzonkim/gopher-script/internal/goversion"
 	"github.com/bonzonkim/gopher-script/internal/handler"
 	"github.com/bonzonkim/gopher-script/internal/llm"
 	"github.com/bonzonkim/gopher-script/internal/logger"
+	"github.com/bonzonkim/gopher-script/internal/notebook"
+	"github.com/bonzonkim/gopher-script/internal/policy"
+	"github.com/bonzonkim/gopher-script/internal/portability"
+	"github.com/bonzonkim/gopher-script/internal/redact"
+	"github.com/bonzonkim/gopher-script/internal/samplecheck"
+	"github.com/bonzonkim/gopher-script/internal/taskfile"
 	"github.com/spf13/cobra"
+	"go.uber.org/zap"
 )
 
 var (
@@ -18,13 +31,29 @@ var (
 	build      bool
 	verbose    bool
 	provider   string
+	retries    int
+	auditLog   string
+	goVersion  string
+
+	depsMode       string
+	allowedModules []string
+	cliStyle       string
+
+	maxExamples  int
+	instructions []string
+
+	redactSecrets bool
+	redactPolicy  string
+
+	sampleInputs []string
 )
 
 func NewRootCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "gopherscript [file]",
 		Short: "Converts a script file to Go.",
-		Long: `GopherScript is a tool to convert Python or Shell scripts into idiomatic Go code.
+		Long: `GopherScript is a tool to convert Python, Shell, Perl, Ruby, Node.js, PowerShell, awk or sed scripts, Jupyter notebooks, Makefiles or justfiles, and the scripts embedded in
+CI, Ansible or Kubernetes YAML files into idiomatic Go code.
 
 It uses LLM to intelligently transpile your scripts into
 standalone Go binaries.
@@ -33,11 +62,13 @@ Supported LLM providers:
   - gemini  (Google Gemini, default)
   - openai  (OpenAI GPT-4o)
   - claude  (Anthropic Claude)
+  - azure-openai (Azure OpenAI Service)
 
 Examples:
   gopherscript script.py                       # Convert using default provider (Gemini)
   gopherscript script.py --provider openai     # Convert using OpenAI GPT
   gopherscript script.py --provider claude     # Convert using Anthropic Claude
+  gopherscript script.py -p azure-openai       # Convert using an Azure OpenAI deployment
   gopherscript script.sh -o output.go          # Convert Shell script with custom output
   gopherscript script.py --build               # Convert and build binary
   gopherscript script.py -o main.go -b bin     # Convert with custom output and binary path`,
@@ -45,12 +76,34 @@ Examples:
 		RunE: runTranspile,
 	}
 
+	cmd.AddCommand(newBatchCmd())
+	cmd.AddCommand(newProvidersCmd())
+	cmd.AddCommand(newAuditCmd())
+	cmd.AddCommand(newScanCmd())
+	cmd.AddCommand(newPromptCmd())
+	cmd.AddCommand(newYAMLCmd())
+
 	// Add flags
 	cmd.Flags().StringVarP(&outputPath, "output", "o", "", "Output path for the generated Go file")
 	cmd.Flags().StringVarP(&binaryPath, "binary", "b", "", "Output path for the compiled binary (requires --build)")
 	cmd.Flags().BoolVar(&build, "build", false, "Build the generated Go code into a binary")
-	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
-	cmd.Flags().StringVarP(&provider, "provider", "p", "", "LLM provider to use (gemini, openai, claude)")
+	cmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
+	cmd.PersistentFlags().StringVarP(&provider, "provider", "p", "", "LLM provider to use (gemini, openai, claude, azure-openai)")
+	cmd.PersistentFlags().StringVar(&promptTemplate, "prompt-template", "", "Template file overriding the built-in transpile prompt")
+	cmd.PersistentFlags().IntVar(&maxExamples, "examples", examples.DefaultLimit, "Maximum number of few-shot examples in the prompt (0 disables them)")
+	cmd.PersistentFlags().StringVar(&goVersion, "go-version", "", "Go version the generated code must compile with (default: the target module's go directive or the installed Go)")
+	cmd.PersistentFlags().StringVar(&depsMode, "deps", "", "Dependency policy for generated code (stdlib-only, allowlist)")
+	cmd.PersistentFlags().StringArrayVar(&allowedModules, "allow-module", nil, "Module the generated code may import, as path@version (repeatable; implies --deps allowlist)")
+	cmd.PersistentFlags().StringVar(&cliStyle, "cli-style", "", "Command-line framework for the generated program (stdlib-flag, pflag, cobra, none)")
+	cmd.PersistentFlags().StringArrayVar(&instructions, "instructions", nil, "Additional requirement for the generated code (repeatable)")
+	cmd.PersistentFlags().StringVar(&auditLog, "audit-log", "", "Append a record of every LLM exchange to this JSONL file")
+	cmd.Flags().BoolVar(&redactSecrets, "redact", false, "Replace secrets, internal hosts and IPs with placeholders before sending the script")
+	cmd.Flags().StringVar(&redactPolicy, "redact-policy", "restore", "How to resolve redacted values in the Go code (restore, env)")
+	cmd.Flags().BoolVar(&allowSensitive, "allow-sensitive", false, "Send the script even when the sensitive-data scan finds something")
+	cmd.Flags().StringVar(&injectionMode, "injection", "warn", "How to handle possible prompt injection in the script (warn, block)")
+	cmd.Flags().StringVar(&scanFormat, "scan-format", "text", "Output format for sensitive-data findings (text, json)")
+	cmd.Flags().IntVar(&retries, "retries", 0, "Number of times to retry a failed LLM request")
+	cmd.Flags().StringArrayVar(&sampleInputs, "sample-input", nil, "Input file on which the Go program must match the original awk or sed script's output (repeatable)")
 
 	return cmd
 }
@@ -67,48 +120,33 @@ func runTranspile(cmd *cobra.Command, args []string) error {
 		selectedProvider = provider
 	}
 
-	// Validate provider
-	llmProvider := llm.Provider(selectedProvider)
-	if !llmProvider.IsValid() {
-		validProviders := make([]string, len(llm.ValidProviders()))
-		for i, p := range llm.ValidProviders() {
-			validProviders[i] = string(p)
-		}
-		return fmt.Errorf("invalid provider '%s'. Valid providers: %s", selectedProvider, strings.Join(validProviders, ", "))
+	policy := redact.Policy(redactPolicy)
+	if !policy.IsValid() {
+		return fmt.Errorf("invalid redact policy '%s'. Valid policies: %s, %s", redactPolicy, redact.PolicyRestore, redact.PolicyEnv)
 	}
 
-	// Get API key for selected provider
-	apiKey := cfg.GetAPIKey(selectedProvider)
-	if apiKey == "" {
-		var envVar string
-		switch llmProvider {
-		case llm.ProviderGemini:
-			envVar = "GEMINI_API_KEY (or API_KEY)"
-		case llm.ProviderOpenAI:
-			envVar = "OPENAI_API_KEY"
-		case llm.ProviderClaude:
-			envVar = "ANTHROPIC_API_KEY"
-		}
-		return fmt.Errorf("%s environment variable is not set for provider '%s'", envVar, selectedProvider)
+	allowlist, err := scanAllowlist(cfg)
+	if err != nil {
+		return err
 	}
 
-	// Determine environment for logger
-	env := cfg.Env
-	if env == "" {
-		env = "dev"
-	}
-	if verbose {
-		env = "dev"
+	injectionPolicy, err := parseInjectionMode()
+	if err != nil {
+		return err
 	}
 
-	// Initialize logger
-	log := logger.NewLogger(env)
+	h, log, err := newHandler(cfg, selectedProvider)
+	if err != nil {
+		return err
+	}
 	defer log.Logger.Sync()
 
-	// Create handler
-	h, err := handler.NewHandler(log.Logger, llmProvider, apiKey)
-	if err != nil {
-		return fmt.Errorf("failed to initialize handler: %w", err)
+	targetDir := filepath.Dir(inputPath)
+	if outputPath != "" {
+		targetDir = filepath.Dir(outputPath)
+	}
+	if h.GoVersion, err = targetGoVersion(log, targetDir); err != nil {
+		return err
 	}
 
 	// Run transpilation
@@ -117,20 +155,368 @@ func runTranspile(cmd *cobra.Command, args []string) error {
 		OutputPath: outputPath,
 		Build:      build,
 		BinaryPath: binaryPath,
+
+		Redact:          redactSecrets,
+		RedactPolicy:    policy,
+		InternalDomains: cfg.InternalDomains,
+
+		AllowSensitive: allowSensitive,
+		ScanAllowlist:  allowlist,
+		Injection:      injectionPolicy,
+
+		SampleInputs: sampleInputs,
 	}
 
 	result, err := h.Transpile(opts)
 	if err != nil {
+		reportBlocked(err)
 		return fmt.Errorf("transpilation failed: %w", err)
 	}
 
 	// Print success message
 	fmt.Fprintf(os.Stdout, "✅ Successfully transpiled: %s (using %s)\n", inputPath, selectedProvider)
 	fmt.Fprintf(os.Stdout, "   Go file: %s\n", result.OutputPath)
+	fmt.Fprintf(os.Stdout, "   Metadata: %s\n", result.MetadataPath)
+
+	if result.ConventionsPath != "" {
+		fmt.Fprintf(os.Stdout, "   Conventions: %s\n", result.ConventionsPath)
+	}
+
+	if result.GoModPath != "" {
+		fmt.Fprintf(os.Stdout, "   go.mod:  %s (%s)\n", result.GoModPath, moduleList(result.Modules))
+	}
 
 	if result.BinaryPath != "" {
 		fmt.Fprintf(os.Stdout, "   Binary:  %s\n", result.BinaryPath)
 	}
 
+	printScriptPackages(result.ScriptPackages)
+	printNonPortable(result.NonPortable)
+	printTasks(result.Tasks)
+	printNotebook(result.Notebook)
+	printSamples(result.Samples)
+
+	if len(result.Findings) > 0 {
+		fmt.Fprintf(os.Stdout, "⚠️  Sent despite %d sensitive finding(s) (--allow-sensitive):\n", len(result.Findings))
+		writeFindings(os.Stdout, result.Findings)
+	}
+
+	printInjections(result.Injections)
+	printDivergences(result.Divergences)
+
+	printCompatibility(result.Compatibility, h.GoVersion)
+
+	if result.CLIStyleIssue != "" {
+		fmt.Fprintf(os.Stdout, "⚠️  Generated code does not follow --cli-style %s: %s\n", h.CLIStyle, result.CLIStyleIssue)
+	}
+
+	if redactSecrets {
+		printRedactionReport(result.Redactions, policy)
+	}
+
 	return nil
 }
+
+// dependencyPolicy builds the dependency policy from the project
+// configuration file and the --deps and --allow-module flags
+func dependencyPolicy(cfg *config.Config) (*deps.Policy, error) {
+	fc, err := config.LoadFileConfig(cfg.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	p := deps.StdlibOnly()
+	if fc.Dependencies.Mode != "" {
+		p.Mode = deps.Mode(fc.Dependencies.Mode)
+	}
+	for _, m := range fc.Dependencies.Modules {
+		p.Modules = append(p.Modules, deps.Module{Path: m.Path, Version: m.Version})
+	}
+
+	for _, s := range allowedModules {
+		m, err := deps.ParseModule(s)
+		if err != nil {
+			return nil, err
+		}
+		p.Modules = append(p.Modules, m)
+		p.Mode = deps.ModeAllowlist
+	}
+	if depsMode != "" {
+		p.Mode = deps.Mode(depsMode)
+	}
+
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// parseCLIStyle validates --cli-style and allows the module the style
+// needs under the dependency policy
+func parseCLIStyle(p *deps.Policy) (clistyle.Style, error) {
+	if cliStyle == "" {
+		return "", nil
+	}
+
+	style := clistyle.Style(cliStyle)
+	if !style.IsValid() {
+		valid := make([]string, len(clistyle.ValidStyles()))
+		for i, s := range clistyle.ValidStyles() {
+			valid[i] = string(s)
+		}
+		return "", fmt.Errorf("invalid CLI style '%s'. Valid styles: %s", cliStyle, strings.Join(valid, ", "))
+	}
+
+	if m, ok := style.Module(); ok {
+		p.Allow(m)
+	}
+
+	return style, nil
+}
+
+// moduleList formats modules for display
+func moduleList(modules []deps.Module) string {
+	list := make([]string, len(modules))
+	for i, m := range modules {
+		list[i] = m.String()
+	}
+	return strings.Join(list, ", ")
+}
+
+// printScriptPackages lists the third-party packages the script imported,
+// flagging those its package.json does not declare
+func printScriptPackages(packages []deps.ScriptPackage) {
+	if len(packages) == 0 {
+		return
+	}
+
+	fmt.Fprintln(os.Stdout, "📦 Script packages replaced in the Go code:")
+	for _, p := range packages {
+		if p.Manifest == "" {
+			fmt.Fprintf(os.Stdout, "   - %s (not declared in package.json)\n", p.Name)
+			continue
+		}
+		fmt.Fprintf(os.Stdout, "   - %s (%s)\n", p, p.Manifest)
+	}
+}
+
+// printNonPortable warns about script commands that only work on the
+// platform the script was written for
+func printNonPortable(issues []portability.Issue) {
+	if len(issues) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "⚠️  Script uses %d command(s) without a portable Go equivalent; check how the Go code handles them:\n", len(issues))
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stdout, "   line %d: %s (%s)\n", issue.Line, issue.Command, issue.Reason)
+	}
+}
+
+// printTasks lists the Makefile or justfile tasks that became subcommands,
+// and the constructs of the file that were not converted
+func printTasks(tasks *taskfile.File) {
+	if tasks == nil {
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "🎯 Tasks converted to subcommands: %s (default: %s)\n", strings.Join(tasks.TargetNames(), ", "), tasks.Default)
+	if len(tasks.Notes) == 0 {
+		return
+	}
+	fmt.Fprintf(os.Stdout, "⚠️  %d %s construct(s) were not converted; check the Go code:\n", len(tasks.Notes), tasks.Kind)
+	for _, note := range tasks.Notes {
+		fmt.Fprintf(os.Stdout, "   %s\n", note)
+	}
+}
+
+// printNotebook reports the notebook cells that were converted, the shell
+// commands they run and the IPython lines that were left out
+func printNotebook(nb *notebook.Notebook) {
+	if nb == nil {
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "📓 Notebook code cells converted: %d\n", nb.CodeCells)
+	if len(nb.Shell) > 0 {
+		fmt.Fprintf(os.Stdout, "⚠️  %d notebook shell command(s) run through subprocess; check how the Go code runs them:\n", len(nb.Shell))
+		for _, line := range nb.Shell {
+			fmt.Fprintf(os.Stdout, "   %s\n", line)
+		}
+	}
+	if len(nb.Skipped) > 0 {
+		fmt.Fprintf(os.Stdout, "   %d IPython line(s) left out:\n", len(nb.Skipped))
+		for _, line := range nb.Skipped {
+			fmt.Fprintf(os.Stdout, "   %s\n", line)
+		}
+	}
+}
+
+// printSamples reports whether the Go program matched the script's output
+// on each sample input
+func printSamples(results []samplecheck.Result) {
+	for _, r := range results {
+		if r.Match {
+			fmt.Fprintf(os.Stdout, "✅ Output matches the script on %s\n", r.Input)
+			continue
+		}
+
+		fmt.Fprintf(os.Stdout, "❌ Output differs from the script on %s\n", r.Input)
+		if r.Line > 0 {
+			fmt.Fprintf(os.Stdout, "   line %d: script %s, Go %s\n", r.Line, r.Want, r.Got)
+		}
+		if r.WantStatus != r.GotStatus {
+			fmt.Fprintf(os.Stdout, "   exit status: script %d, Go %d\n", r.WantStatus, r.GotStatus)
+		}
+	}
+}
+
+// printCompatibility warns about features that need a newer Go than the target version
+func printCompatibility(issues []goversion.Issue, version string) {
+	if len(issues) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "⚠️  Generated code may not compile with Go %s:\n", version)
+	for _, issue := range issues {
+		fmt.Fprintf(os.Stdout, "   %s\n", issue)
+	}
+}
+
+// targetGoVersion returns the Go version set with --go-version, or detects
+// the version for code written to dir. Detection failures disable the check.
+func targetGoVersion(log *logger.Logger, dir string) (string, error) {
+	if goVersion != "" {
+		if !goversion.Valid(goVersion) {
+			return "", fmt.Errorf("invalid Go version '%s'. Expected a version such as 1.22", goVersion)
+		}
+		return goVersion, nil
+	}
+
+	version, source, err := goversion.Detect(dir)
+	if err != nil {
+		log.Logger.Warn("Could not determine target Go version", zap.Error(err))
+		return "", nil
+	}
+
+	log.Logger.Info("Detected target Go version", zap.String("goVersion", version), zap.String("source", source))
+	return version, nil
+}
+
+// printRedactionReport lists the values that were kept from the LLM
+func printRedactionReport(redactions []redact.Redaction, policy redact.Policy) {
+	if len(redactions) == 0 {
+		fmt.Fprintln(os.Stdout, "🔒 Redaction: no sensitive values found")
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "🔒 Redacted %d value(s) before sending to the LLM (policy: %s):\n", len(redactions), policy)
+	for _, r := range redactions {
+		resolved := "restored"
+		if policy == redact.PolicyEnv {
+			resolved = "$" + r.EnvVar()
+		}
+		fmt.Fprintf(os.Stdout, "   line %-4d %-12s %-20s %s (x%d) -> %s\n",
+			r.Line, r.Kind, r.Placeholder, r.Masked(), r.Count, resolved)
+	}
+}
+
+// newHandler validates the provider, resolves its credentials and creates
+// a handler together with the logger it writes to
+func newHandler(cfg *config.Config, selectedProvider string) (*handler.Handler, *logger.Logger, error) {
+	// Validate provider
+	llmProvider, err := parseProvider(selectedProvider)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Get API key for selected provider
+	apiKey := cfg.GetAPIKey(selectedProvider)
+	if apiKey == "" {
+		return nil, nil, fmt.Errorf("%s environment variable is not set for provider '%s'", config.APIKeyEnvVar(selectedProvider), selectedProvider)
+	}
+
+	// Initialize logger
+	log := newLogger(cfg)
+
+	// Create handler
+	var middlewares []llm.Middleware
+	if retries > 0 {
+		middlewares = append(middlewares, llm.WithRetry(retries, 2*time.Second))
+	}
+
+	h, err := handler.NewHandler(log.Logger, clientConfig(cfg, llmProvider), middlewares...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize handler: %w", err)
+	}
+
+	if path := auditLogPath(cfg); path != "" {
+		h.Audit = llm.NewAuditLog(path, cfg.AuditFullPrompt)
+	}
+
+	if err := configureHandler(h, cfg); err != nil {
+		return nil, nil, err
+	}
+
+	return h, log, nil
+}
+
+// configureHandler applies the project's egress policy and prompt templates
+func configureHandler(h *handler.Handler, cfg *config.Config) error {
+	h.Policy = &policy.Resolver{File: cfg.PolicyFile, Name: filepath.Join(cfg.ProjectDir, "policy.json")}
+
+	var err error
+	if h.Prompts, err = promptTemplates(cfg); err != nil {
+		return err
+	}
+	if h.Dependencies, err = dependencyPolicy(cfg); err != nil {
+		return err
+	}
+	if h.CLIStyle, err = parseCLIStyle(h.Dependencies); err != nil {
+		return err
+	}
+	if err := h.Examples.LoadDir(filepath.Join(cfg.ProjectDir, "examples")); err != nil {
+		return err
+	}
+	h.MaxExamples = maxExamples
+	h.Instructions = instructions
+	return nil
+}
+
+// parseProvider validates a provider name
+func parseProvider(name string) (llm.Provider, error) {
+	llmProvider := llm.Provider(name)
+	if !llmProvider.IsValid() {
+		validProviders := make([]string, len(llm.ValidProviders()))
+		for i, p := range llm.ValidProviders() {
+			validProviders[i] = string(p)
+		}
+		return "", fmt.Errorf("invalid provider '%s'. Valid providers: %s", name, strings.Join(validProviders, ", "))
+	}
+	return llmProvider, nil
+}
+
+// newLogger creates the logger for the configured environment
+func newLogger(cfg *config.Config) *logger.Logger {
+	// Determine environment for logger
+	env := cfg.Env
+	if env == "" {
+		env = "dev"
+	}
+	if verbose {
+		env = "dev"
+	}
+
+	return logger.NewLogger(env)
+}
+
+// clientConfig builds the LLM client configuration for a provider
+func clientConfig(cfg *config.Config, p llm.Provider) llm.ClientConfig {
+	return llm.ClientConfig{
+		Provider:        p,
+		APIKey:          cfg.GetAPIKey(string(p)),
+		AzureEndpoint:   cfg.AzureOpenAIEndpoint,
+		AzureDeployment: cfg.AzureOpenAIDeployment,
+		AzureAPIVersion: cfg.AzureOpenAIAPIVersion,
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/bonzonkim/gopher-script/config"
+	"github.com/bonzonkim/gopher-script/internal/injection"
+	"github.com/bonzonkim/gopher-script/internal/scan"
+	"github.com/spf13/cobra"
+)
+
+var (
+	allowSensitive bool
+	scanFormat     string
+)
+
+func newScanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "scan [files...]",
+		Short: "Scan scripts for credentials and other sensitive data.",
+		Long: `Scan scripts for the same sensitive data that blocks a transpilation, without
+contacting any LLM provider. Exits with a non-zero status when anything is found,
+so it can be used as a pre-commit or CI check.
+
+Findings can be accepted with an allowlist in .gopherscript/config.json.
+
+Examples:
+  gopherscript scan deploy.sh                  # Report findings as text
+  gopherscript scan scripts/*.py --format json # Report findings as JSON`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: runScan,
+	}
+
+	cmd.Flags().StringVar(&scanFormat, "format", "text", "Output format for findings (text, json)")
+
+	return cmd
+}
+
+func runScan(cmd *cobra.Command, args []string) error {
+	cfg := config.NewConfig()
+
+	allowlist, err := scanAllowlist(cfg)
+	if err != nil {
+		return err
+	}
+
+	scanner := scan.NewScanner(cfg.InternalDomains, allowlist)
+
+	var findings []scan.Finding
+	for _, path := range args {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read file: %s: %w", path, err)
+		}
+		findings = append(findings, scanner.Scan(path, string(content))...)
+	}
+
+	if err := writeFindings(os.Stdout, findings); err != nil {
+		return err
+	}
+
+	if len(findings) > 0 {
+		return fmt.Errorf("found %d sensitive finding(s)", len(findings))
+	}
+	return nil
+}
+
+// scanAllowlist loads the scanner allowlist from the project configuration file
+func scanAllowlist(cfg *config.Config) ([]scan.AllowRule, error) {
+	fc, err := config.LoadFileConfig(cfg.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+
+	allow := make([]scan.AllowRule, len(fc.Scan.Allowlist))
+	for i, entry := range fc.Scan.Allowlist {
+		allow[i] = scan.AllowRule{Rule: entry.Rule, Path: entry.Path, Value: entry.Value}
+	}
+	return allow, nil
+}
+
+// writeFindings writes findings in the format selected with --format or --scan-format
+func writeFindings(w io.Writer, findings []scan.Finding) error {
+	switch scanFormat {
+	case "json":
+		return scan.WriteJSON(w, findings)
+	case "text", "":
+		return scan.WriteText(w, findings)
+	default:
+		return fmt.Errorf("invalid scan format '%s'. Valid formats: text, json", scanFormat)
+	}
+}
+
+// reportBlocked prints the findings behind a blocked transpilation
+func reportBlocked(err error) {
+	var blocked *scan.BlockedError
+	if errors.As(err, &blocked) {
+		fmt.Fprintln(os.Stderr, "🚫 Sensitive data found; nothing was sent to the LLM:")
+		writeFindings(os.Stderr, blocked.Findings)
+	}
+
+	var injected *injection.BlockedError
+	if errors.As(err, &injected) {
+		fmt.Fprintln(os.Stderr, "🚫 Possible prompt injection found; nothing was sent to the LLM:")
+		injection.WriteText(os.Stderr, injected.Findings)
+	}
+}
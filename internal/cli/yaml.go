@@ -0,0 +1,222 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/bonzonkim/gopher-script/config"
+	"github.com/bonzonkim/gopher-script/internal/embedded"
+	"github.com/bonzonkim/gopher-script/internal/handler"
+	"github.com/bonzonkim/gopher-script/internal/redact"
+	"github.com/spf13/cobra"
+)
+
+var (
+	yamlSelectors  []string
+	yamlList       bool
+	yamlOutputDir  string
+	yamlBuild      bool
+	yamlRewrite    string
+	yamlCommandDir string
+)
+
+func newYAMLCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "yaml [file]",
+		Short: "Transpile the scripts embedded in a YAML file.",
+		Long: `Find the scripts embedded in a YAML file and transpile each into its own Go program.
+
+Scripts are found in:
+  - GitHub Actions workflows and composite actions: the run of each step
+  - GitLab CI pipelines: the script, before_script and after_script of each job
+  - Ansible playbooks and task files: shell tasks
+  - Kubernetes manifests: container commands such as ["sh", "-c", "..."]
+
+Each script is written to a file with the options its runner adds, such as
+set -eo pipefail, and transpiled like any other script. Template expressions
+(${{ }} in workflows, {{ }} in Ansible) become environment variables, which
+the rewritten YAML file sets.
+
+--select picks scripts by path, e.g. jobs.build.steps[2].run, or
+jobs.*.steps[name=Deploy] for a step by name. A path to any other string in
+the file transpiles that string as a shell script.
+
+Examples:
+  gopherscript yaml .github/workflows/release.yml --list        # Show the scripts that would be converted
+  gopherscript yaml .github/workflows/release.yml --build       # Convert every run step and build the programs
+  gopherscript yaml .gitlab-ci.yml --select 'deploy'            # Convert the scripts of one job
+  gopherscript yaml cronjob.yaml --rewrite cronjob.go.yaml --command-dir /usr/local/bin`,
+		Args: cobra.ExactArgs(1),
+		RunE: runYAML,
+	}
+
+	cmd.Flags().StringArrayVar(&yamlSelectors, "select", nil, "Path of a script, or of a node whose scripts are converted (repeatable)")
+	cmd.Flags().BoolVar(&yamlList, "list", false, "List the scripts found without converting them")
+	cmd.Flags().StringVarP(&yamlOutputDir, "output-dir", "o", "", "Directory for the extracted scripts and Go files (default: <name>-scripts next to the YAML file)")
+	cmd.Flags().BoolVar(&yamlBuild, "build", false, "Build each generated Go file into a binary")
+	cmd.Flags().StringVar(&yamlRewrite, "rewrite", "", "Write a copy of the YAML file that runs the programs instead of the scripts")
+	cmd.Flags().StringVar(&yamlCommandDir, "command-dir", handler.DefaultCommandDir, "Directory the rewritten YAML file runs the programs from")
+	cmd.Flags().BoolVar(&redactSecrets, "redact", false, "Replace secrets, internal hosts and IPs with placeholders before sending the scripts")
+	cmd.Flags().StringVar(&redactPolicy, "redact-policy", "restore", "How to resolve redacted values in the Go code (restore, env)")
+	cmd.Flags().BoolVar(&allowSensitive, "allow-sensitive", false, "Send the scripts even when the sensitive-data scan finds something")
+	cmd.Flags().StringVar(&injectionMode, "injection", "warn", "How to handle possible prompt injection in the scripts (warn, block)")
+	cmd.Flags().StringVar(&scanFormat, "scan-format", "text", "Output format for sensitive-data findings (text, json)")
+
+	return cmd
+}
+
+func runYAML(cmd *cobra.Command, args []string) error {
+	inputPath := args[0]
+
+	if yamlList {
+		f, err := embedded.Load(inputPath, yamlSelectors)
+		if err != nil {
+			return err
+		}
+		printEmbeddedScripts(f)
+		return nil
+	}
+
+	cfg := config.NewConfig()
+
+	selectedProvider := cfg.Provider
+	if provider != "" {
+		selectedProvider = provider
+	}
+
+	policy := redact.Policy(redactPolicy)
+	if !policy.IsValid() {
+		return fmt.Errorf("invalid redact policy '%s'. Valid policies: %s, %s", redactPolicy, redact.PolicyRestore, redact.PolicyEnv)
+	}
+
+	allowlist, err := scanAllowlist(cfg)
+	if err != nil {
+		return err
+	}
+
+	injectionPolicy, err := parseInjectionMode()
+	if err != nil {
+		return err
+	}
+
+	h, log, err := newHandler(cfg, selectedProvider)
+	if err != nil {
+		return err
+	}
+	defer log.Logger.Sync()
+
+	outputDir := yamlOutputDir
+	if outputDir == "" {
+		outputDir = handler.DefaultEmbeddedOutputDir(inputPath)
+	}
+	if h.GoVersion, err = targetGoVersion(log, outputDir); err != nil {
+		return err
+	}
+
+	result, err := h.TranspileEmbedded(handler.EmbeddedOptions{
+		InputPath:   inputPath,
+		Selectors:   yamlSelectors,
+		OutputDir:   outputDir,
+		Build:       yamlBuild,
+		RewritePath: yamlRewrite,
+		CommandDir:  yamlCommandDir,
+
+		Script: handler.TranspileOptions{
+			Redact:          redactSecrets,
+			RedactPolicy:    policy,
+			InternalDomains: cfg.InternalDomains,
+
+			AllowSensitive: allowSensitive,
+			ScanAllowlist:  allowlist,
+			Injection:      injectionPolicy,
+		},
+	})
+	if result == nil {
+		return fmt.Errorf("transpilation failed: %w", err)
+	}
+
+	var failed int
+	for _, item := range result.Items {
+		if item.Err != nil {
+			failed++
+			fmt.Fprintf(os.Stdout, "❌ %s (line %d): %v\n", item.Script, item.Script.Line, item.Err)
+			reportBlocked(item.Err)
+			continue
+		}
+
+		fmt.Fprintf(os.Stdout, "✅ %s (line %d, using %s)\n", item.Script, item.Script.Line, selectedProvider)
+		fmt.Fprintf(os.Stdout, "   Script:  %s\n", item.ScriptPath)
+		fmt.Fprintf(os.Stdout, "   Go file: %s\n", item.Result.OutputPath)
+		fmt.Fprintf(os.Stdout, "   Metadata: %s\n", item.Result.MetadataPath)
+		if item.Result.GoModPath != "" {
+			fmt.Fprintf(os.Stdout, "   go.mod:  %s (%s)\n", item.Result.GoModPath, moduleList(item.Result.Modules))
+		}
+		if item.Result.BinaryPath != "" {
+			fmt.Fprintf(os.Stdout, "   Binary:  %s\n", item.Result.BinaryPath)
+		}
+		printEmbeddedEnv(item.Script.Env)
+		printNonPortable(item.Result.NonPortable)
+		if len(item.Result.Findings) > 0 {
+			fmt.Fprintf(os.Stdout, "⚠️  Sent despite %d sensitive finding(s) (--allow-sensitive):\n", len(item.Result.Findings))
+			writeFindings(os.Stdout, item.Result.Findings)
+		}
+		printInjections(item.Result.Injections)
+		printDivergences(item.Result.Divergences)
+		printCompatibility(item.Result.Compatibility, h.GoVersion)
+		if redactSecrets {
+			printRedactionReport(item.Result.Redactions, policy)
+		}
+	}
+
+	printEmbeddedNotes(result.File.Notes)
+
+	if result.RewritePath != "" {
+		fmt.Fprintf(os.Stdout, "📝 Rewritten YAML: %s (runs the programs from %s)\n", result.RewritePath, yamlCommandDir)
+	}
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d scripts failed", failed, len(result.Items))
+	}
+	return nil
+}
+
+// printEmbeddedScripts lists the scripts found in a YAML file
+func printEmbeddedScripts(f *embedded.File) {
+	if len(f.Scripts) == 0 {
+		fmt.Fprintln(os.Stdout, "No embedded scripts found.")
+	}
+	for _, s := range f.Scripts {
+		fmt.Fprintf(os.Stdout, "%s  %-6s  line %-4d  %s\n", s.FileName(), s.Shell, s.Line, s)
+		printEmbeddedEnv(s.Env)
+	}
+	printEmbeddedNotes(f.Notes)
+}
+
+// printEmbeddedEnv lists the template expressions a program reads from
+// the environment
+func printEmbeddedEnv(env []embedded.EnvVar) {
+	if len(env) == 0 {
+		return
+	}
+
+	fmt.Fprintln(os.Stdout, "   Template expressions read from the environment:")
+	for _, e := range env {
+		fmt.Fprintf(os.Stdout, "     %s=%s\n", e.Name, e.Expression)
+	}
+}
+
+// printEmbeddedNotes lists the scripts and constructs of a YAML file that
+// were left as they are
+func printEmbeddedNotes(notes []string) {
+	if len(notes) == 0 {
+		return
+	}
+
+	fmt.Fprintf(os.Stdout, "⚠️  %d embedded script(s) or construct(s) were not converted:\n", len(notes))
+	for _, note := range notes {
+		fmt.Fprintf(os.Stdout, "   %s\n", note)
+	}
+}
@@ -0,0 +1,122 @@
+package clistyle
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"strconv"
+
+	"github.com/bonzonkim/gopher-script/internal/deps"
+)
+
+// Style is the command-line framework the generated program uses
+type Style string
+
+const (
+	StyleStdlibFlag Style = "stdlib-flag"
+	StylePflag      Style = "pflag"
+	StyleCobra      Style = "cobra"
+	StyleNone       Style = "none"
+)
+
+const (
+	flagImport  = "flag"
+	pflagImport = "github.com/spf13/pflag"
+	cobraImport = "github.com/spf13/cobra"
+)
+
+// ValidStyles returns all supported styles
+func ValidStyles() []Style {
+	return []Style{StyleStdlibFlag, StylePflag, StyleCobra, StyleNone}
+}
+
+// IsValid checks if the style is supported
+func (s Style) IsValid() bool {
+	for _, valid := range ValidStyles() {
+		if s == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Module returns the third-party module the style needs, if any
+func (s Style) Module() (deps.Module, bool) {
+	switch s {
+	case StylePflag:
+		return deps.Module{Path: pflagImport, Version: "v1.0.10"}, true
+	case StyleCobra:
+		return deps.Module{Path: cobraImport, Version: "v1.10.1"}, true
+	}
+	return deps.Module{}, false
+}
+
+var guidance = map[Style]string{
+	StyleStdlibFlag: `- Use the standard library "flag" package and no other argument parsing library, even if the script takes no options, so that -h prints usage.
+- Define every option with flag.String, flag.Int, flag.Bool, flag.Duration (or their Var forms), keeping the script's defaults. Use the long option name without dashes (--dry-run becomes "dry-run") and register short aliases on the same variable.
+- Use each option's help text as the flag usage string.
+- Call flag.Parse() and read positional arguments from flag.Args(). Validate their number like the script does and, on a usage error, print usage to stderr and exit with status 2.
+- Set flag.Usage to print a usage line naming the positional arguments, the script's description and flag.PrintDefaults().
+- Note that flag stops parsing at the first positional argument; mention it in a comment if the script allowed options after positional arguments.`,
+
+	StylePflag: `- Use github.com/spf13/pflag for argument parsing and not the standard "flag" package, even if the script takes no options, so that --help prints usage.
+- Define every option with pflag.StringP, pflag.IntP, pflag.BoolP, pflag.DurationP (or their VarP forms), keeping the script's long names, single-letter shorthands and defaults.
+- Use each option's help text as the flag usage string; mark required options by validating them after parsing.
+- Call pflag.Parse() and read positional arguments from pflag.Args(). Validate their number like the script does and, on a usage error, print usage to stderr and exit with status 2.
+- Set pflag.Usage to print a usage line naming the positional arguments, the script's description and pflag.PrintDefaults().`,
+
+	StyleCobra: `- Use github.com/spf13/cobra for the command-line interface, even if the script takes no options, so that --help prints usage.
+- Build a root *cobra.Command with Use naming the program and its positional arguments, Short/Long from the script's description, an Args validator (cobra.ExactArgs, cobra.MinimumNArgs, cobra.RangeArgs) matching the positional arguments, and RunE that returns errors instead of exiting.
+- Define options with cmd.Flags().StringVarP, IntVarP, BoolVarP and DurationVarP, keeping long names, shorthands, defaults and help text; call MarkFlagRequired for required options.
+- Map sub-commands (argparse subparsers, click groups, case "$1" dispatch) to child commands added with AddCommand.
+- In main, call Execute on the root command and exit with status 1 when it returns an error.`,
+
+	StyleNone: `- Do not use any argument parsing package ("flag", pflag, cobra or others).
+- Read arguments from os.Args[1:] exactly as the script reads them ($1, "$@", sys.argv), and parse options by hand only where the script does.
+- Keep the script's usage messages and exit statuses for missing or invalid arguments.`,
+}
+
+// Guidance returns the prompt section describing how to map the script's
+// arguments to the style
+func Guidance(s Style) string {
+	return guidance[s]
+}
+
+// Check reports how goCode fails to use the style, or "" when it does
+func Check(goCode string, s Style) (string, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", goCode, parser.ImportsOnly)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse Go code: %w", err)
+	}
+
+	imports := make(map[string]bool)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		imports[path] = true
+	}
+
+	var want string
+	switch s {
+	case StyleStdlibFlag:
+		want = flagImport
+	case StylePflag:
+		want = pflagImport
+	case StyleCobra:
+		want = cobraImport
+	case StyleNone:
+	default:
+		return "", fmt.Errorf("invalid CLI style: %s", s)
+	}
+
+	if want != "" && !imports[want] {
+		return fmt.Sprintf("the program does not import %q", want), nil
+	}
+	for _, other := range []string{flagImport, pflagImport, cobraImport} {
+		if other != want && imports[other] {
+			return fmt.Sprintf("the program imports %q, which the %s style does not use", other, s), nil
+		}
+	}
+
+	return "", nil
+}
@@ -0,0 +1,60 @@
+package clistyle
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	const (
+		stdlib = "package main\n\nimport (\n\t\"flag\"\n\t\"fmt\"\n)\n\nfunc main() { flag.Parse(); fmt.Println(flag.Args()) }\n"
+		cobra  = "package main\n\nimport \"github.com/spf13/cobra\"\n\nfunc main() { _ = cobra.Command{} }\n"
+		mixed  = "package main\n\nimport (\n\t\"flag\"\n\t\"github.com/spf13/pflag\"\n)\n\nfunc main() {}\n"
+		plain  = "package main\n\nimport \"os\"\n\nfunc main() { _ = os.Args }\n"
+	)
+
+	tests := []struct {
+		name  string
+		code  string
+		style Style
+		want  string
+	}{
+		{"stdlib ok", stdlib, StyleStdlibFlag, ""},
+		{"cobra ok", cobra, StyleCobra, ""},
+		{"none ok", plain, StyleNone, ""},
+		{"pflag missing", stdlib, StylePflag, "does not import"},
+		{"pflag mixed with flag", mixed, StylePflag, `imports "flag"`},
+		{"none with flag", stdlib, StyleNone, `imports "flag"`},
+		{"cobra missing", plain, StyleCobra, "does not import"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Check(tt.code, tt.style)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+			if tt.want == "" && got != "" || tt.want != "" && !strings.Contains(got, tt.want) {
+				t.Errorf("Check() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStyles(t *testing.T) {
+	for _, s := range ValidStyles() {
+		if Guidance(s) == "" {
+			t.Errorf("Guidance(%s) is empty", s)
+		}
+	}
+
+	if Style("click").IsValid() {
+		t.Error("IsValid() should reject unknown styles")
+	}
+	if _, ok := StyleCobra.Module(); !ok {
+		t.Error("Module() should return the cobra module")
+	}
+	if _, ok := StyleStdlibFlag.Module(); ok {
+		t.Error("Module() should not return a module for stdlib-flag")
+	}
+}
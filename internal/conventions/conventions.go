@@ -0,0 +1,69 @@
+package conventions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxSize is the largest conventions file included in prompts
+const MaxSize = 64 << 10
+
+// FileNames are the conventions files looked for in each directory, in order
+var FileNames = []string{
+	"GOPHERSCRIPT.md",
+	filepath.Join(".gopherscript", "instructions.md"),
+}
+
+// File is a project conventions file
+type File struct {
+	Path    string
+	Content string
+}
+
+// Find looks for a conventions file in dir and its parents and returns the
+// nearest one, or nil when there is none
+func Find(dir string) (*File, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve directory: %s: %w", dir, err)
+	}
+
+	for {
+		for _, name := range FileNames {
+			f, err := load(filepath.Join(abs, name))
+			if err != nil || f != nil {
+				return f, err
+			}
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return nil, nil
+		}
+		abs = parent
+	}
+}
+
+// load reads a conventions file. A missing file yields nil.
+func load(path string) (*File, error) {
+	info, err := os.Stat(path)
+	if err != nil || info.IsDir() {
+		return nil, nil
+	}
+	if info.Size() > MaxSize {
+		return nil, fmt.Errorf("conventions file is too large: %s (%d bytes, max %d)", path, info.Size(), MaxSize)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conventions file: %s: %w", path, err)
+	}
+
+	content := strings.TrimSpace(string(data))
+	if content == "" {
+		return nil, nil
+	}
+	return &File{Path: path, Content: content}, nil
+}
@@ -0,0 +1,60 @@
+package conventions
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindWalksUp(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "scripts", "ops")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "GOPHERSCRIPT.md"), []byte("Use log/slog.\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Find(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f == nil || f.Content != "Use log/slog." || f.Path != filepath.Join(root, "GOPHERSCRIPT.md") {
+		t.Fatalf("Find() = %+v", f)
+	}
+}
+
+func TestFindPrefersNearest(t *testing.T) {
+	root := t.TempDir()
+	nested := filepath.Join(root, "scripts")
+	if err := os.MkdirAll(filepath.Join(nested, ".gopherscript"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "GOPHERSCRIPT.md"), []byte("root"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(nested, ".gopherscript", "instructions.md"), []byte("nested"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := Find(nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f == nil || f.Content != "nested" {
+		t.Fatalf("Find() = %+v, want nested conventions", f)
+	}
+}
+
+func TestFindTooLarge(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "GOPHERSCRIPT.md"), []byte(strings.Repeat("x", MaxSize+1)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Find(dir); err == nil || !strings.Contains(err.Error(), "too large") {
+		t.Fatalf("Find() error = %v, want too large", err)
+	}
+}
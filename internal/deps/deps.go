@@ -0,0 +1,251 @@
+package deps
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Mode selects which imports generated code may use
+type Mode string
+
+const (
+	// ModeStdlibOnly allows only standard library packages
+	ModeStdlibOnly Mode = "stdlib-only"
+	// ModeAllowlist allows the standard library and the listed modules
+	ModeAllowlist Mode = "allowlist"
+)
+
+// IsValid checks if the mode is supported
+func (m Mode) IsValid() bool {
+	return m == ModeStdlibOnly || m == ModeAllowlist
+}
+
+// Module is a Go module at a specific version
+type Module struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+}
+
+func (m Module) String() string {
+	return m.Path + "@" + m.Version
+}
+
+// ParseModule parses "path@version"
+func ParseModule(s string) (Module, error) {
+	path, version, ok := strings.Cut(s, "@")
+	if !ok || path == "" || version == "" {
+		return Module{}, fmt.Errorf("invalid module '%s'. Expected path@version, e.g. github.com/spf13/cobra@v1.8.0", s)
+	}
+	return Module{Path: path, Version: version}, nil
+}
+
+var versionPattern = regexp.MustCompile(`^v\d+\.\d+\.\d+(?:[-+][0-9A-Za-z.+-]+)?$`)
+
+// Policy restricts the imports of generated code
+type Policy struct {
+	Mode    Mode
+	Modules []Module
+}
+
+// StdlibOnly returns a policy that allows only the standard library
+func StdlibOnly() *Policy {
+	return &Policy{Mode: ModeStdlibOnly}
+}
+
+// Validate checks the mode and the allowlisted modules
+func (p *Policy) Validate() error {
+	if !p.Mode.IsValid() {
+		return fmt.Errorf("invalid dependency mode '%s'. Valid modes: %s, %s", p.Mode, ModeStdlibOnly, ModeAllowlist)
+	}
+	for _, m := range p.Modules {
+		if m.Path == "" || IsStdlib(m.Path) {
+			return fmt.Errorf("invalid module path '%s' in dependency allowlist", m.Path)
+		}
+		if !versionPattern.MatchString(m.Version) {
+			return fmt.Errorf("module %s in dependency allowlist needs a semantic version such as v1.2.3, got '%s'", m.Path, m.Version)
+		}
+	}
+	return nil
+}
+
+// Allow adds a module to the allowlist unless a version of it is already
+// allowed. A stdlib-only policy becomes an allowlist of just that module.
+func (p *Policy) Allow(m Module) {
+	if p.Mode != ModeAllowlist {
+		p.Mode = ModeAllowlist
+		p.Modules = nil
+	}
+	for _, allowed := range p.Modules {
+		if allowed.Path == m.Path {
+			return
+		}
+	}
+	p.Modules = append(p.Modules, m)
+}
+
+// Instruction describes the policy for the transpile prompt
+func (p *Policy) Instruction() string {
+	if p == nil {
+		return ""
+	}
+	if p.Mode != ModeAllowlist || len(p.Modules) == 0 {
+		return "Use only the Go standard library; do not import any third-party module"
+	}
+
+	paths := make([]string, len(p.Modules))
+	for i, m := range p.Modules {
+		paths[i] = m.String()
+	}
+	return "Prefer the Go standard library; the only third-party modules you may import are " + strings.Join(paths, ", ")
+}
+
+// Violation is an import that the policy does not allow
+type Violation struct {
+	Line   int    `json:"line"`
+	Import string `json:"import"`
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("line %d: %s", v.Line, v.Import)
+}
+
+// ViolationError is returned when generated code imports disallowed packages
+type ViolationError struct {
+	Violations []Violation
+}
+
+func (e *ViolationError) Error() string {
+	imports := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		imports[i] = v.String()
+	}
+	return "generated code imports packages not allowed by the dependency policy: " + strings.Join(imports, "; ")
+}
+
+// Check inspects the imports of goCode. It returns the imports the policy
+// does not allow and the allowlisted modules the code requires.
+func (p *Policy) Check(goCode string) ([]Violation, []Module, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", goCode, parser.ImportsOnly)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse Go code: %w", err)
+	}
+
+	var violations []Violation
+	required := make(map[string]Module)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		if IsStdlib(path) {
+			continue
+		}
+
+		if m, ok := p.module(path); ok {
+			required[m.Path] = m
+			continue
+		}
+		violations = append(violations, Violation{Line: fset.Position(spec.Pos()).Line, Import: path})
+	}
+
+	modules := make([]Module, 0, len(required))
+	for _, m := range required {
+		modules = append(modules, m)
+	}
+	sort.Slice(modules, func(i, j int) bool { return modules[i].Path < modules[j].Path })
+
+	return violations, modules, nil
+}
+
+// module returns the allowlisted module providing the import path
+func (p *Policy) module(importPath string) (Module, bool) {
+	if p.Mode != ModeAllowlist {
+		return Module{}, false
+	}
+	for _, m := range p.Modules {
+		if importPath == m.Path || strings.HasPrefix(importPath, m.Path+"/") {
+			return m, true
+		}
+	}
+	return Module{}, false
+}
+
+// IsStdlib reports whether an import path belongs to the standard library,
+// whose paths have no dot in their first element
+func IsStdlib(importPath string) bool {
+	first, _, _ := strings.Cut(importPath, "/")
+	return !strings.Contains(first, ".")
+}
+
+// GoMod renders a go.mod file for a module requiring the given modules
+func GoMod(modulePath, goVersion string, modules []Module) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "module %s\n", modulePath)
+	if goVersion != "" {
+		fmt.Fprintf(&sb, "\ngo %s\n", goVersion)
+	}
+
+	if len(modules) > 0 {
+		sb.WriteString("\nrequire (\n")
+		for _, m := range modules {
+			fmt.Fprintf(&sb, "\t%s %s\n", m.Path, m.Version)
+		}
+		sb.WriteString(")\n")
+	}
+
+	return sb.String()
+}
+
+// ParseGoMod returns the module path and requirements of a go.mod, in the
+// form GoMod writes or go mod tidy leaves it
+func ParseGoMod(content string) (string, []Module) {
+	var modulePath string
+	var modules []Module
+	inRequire := false
+	for _, line := range strings.Split(content, "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+
+		switch {
+		case len(fields) == 0:
+		case inRequire:
+			if fields[0] == ")" {
+				inRequire = false
+			} else if len(fields) >= 2 {
+				modules = append(modules, Module{Path: fields[0], Version: fields[1]})
+			}
+		case fields[0] == "module" && len(fields) == 2:
+			modulePath = strings.Trim(fields[1], `"`)
+		case fields[0] == "require" && len(fields) == 2 && fields[1] == "(":
+			inRequire = true
+		case fields[0] == "require" && len(fields) >= 3:
+			modules = append(modules, Module{Path: fields[1], Version: fields[2]})
+		}
+	}
+	return modulePath, modules
+}
+
+// MergeModules adds modules to existing. A module already in existing takes
+// the version from modules.
+func MergeModules(existing, modules []Module) []Module {
+	merged := append([]Module(nil), existing...)
+	for _, m := range modules {
+		found := false
+		for i := range merged {
+			if merged[i].Path == m.Path {
+				merged[i].Version = m.Version
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, m)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,159 @@
+package deps
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const code = `package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+)
+
+func main() {}
+`
+
+func TestCheck_StdlibOnly(t *testing.T) {
+	violations, modules, err := StdlibOnly().Check(code)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if len(modules) != 0 {
+		t.Errorf("Check() modules = %v, want none", modules)
+	}
+	if len(violations) != 3 {
+		t.Fatalf("Check() violations = %v, want 3", violations)
+	}
+	if violations[0].Import != "github.com/spf13/cobra" || violations[0].Line != 7 {
+		t.Errorf("Check() violations[0] = %+v", violations[0])
+	}
+}
+
+func TestCheck_Allowlist(t *testing.T) {
+	p := &Policy{Mode: ModeAllowlist, Modules: []Module{
+		{Path: "github.com/spf13/pflag", Version: "v1.0.5"},
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+	}}
+
+	violations, modules, err := p.Check(code)
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+
+	if len(violations) != 1 || violations[0].Import != "gopkg.in/yaml.v3" {
+		t.Errorf("Check() violations = %v, want gopkg.in/yaml.v3", violations)
+	}
+	if len(modules) != 2 || modules[0].Path != "github.com/spf13/cobra" {
+		t.Errorf("Check() modules = %v, want cobra and pflag", modules)
+	}
+}
+
+func TestCheck_Subpackage(t *testing.T) {
+	p := &Policy{Mode: ModeAllowlist, Modules: []Module{{Path: "golang.org/x/sync", Version: "v0.7.0"}}}
+
+	violations, modules, err := p.Check("package main\n\nimport (\n\t\"golang.org/x/sync/errgroup\"\n\t\"golang.org/x/syncx\"\n)\n")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(modules) != 1 || len(violations) != 1 || violations[0].Import != "golang.org/x/syncx" {
+		t.Errorf("Check() = %v, %v", violations, modules)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{"stdlib", Policy{Mode: ModeStdlibOnly}, false},
+		{"allowlist", Policy{Mode: ModeAllowlist, Modules: []Module{{Path: "github.com/a/b", Version: "v1.2.3"}}}, false},
+		{"bad mode", Policy{Mode: "anything"}, true},
+		{"no version", Policy{Mode: ModeAllowlist, Modules: []Module{{Path: "github.com/a/b"}}}, true},
+		{"stdlib module", Policy{Mode: ModeAllowlist, Modules: []Module{{Path: "fmt", Version: "v1.0.0"}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGoMod(t *testing.T) {
+	got := GoMod("backup", "1.22", []Module{{Path: "github.com/spf13/cobra", Version: "v1.8.0"}})
+	want := "module backup\n\ngo 1.22\n\nrequire (\n\tgithub.com/spf13/cobra v1.8.0\n)\n"
+	if got != want {
+		t.Errorf("GoMod() = %q, want %q", got, want)
+	}
+}
+
+func TestParseGoMod(t *testing.T) {
+	content := "// Generated\n\nmodule backup\n\ngo 1.22\n\nrequire github.com/spf13/cobra v1.8.0\n\nrequire (\n\tgopkg.in/yaml.v3 v3.0.1\n\tgithub.com/spf13/pflag v1.0.5 // indirect\n)\n"
+	path, modules := ParseGoMod(content)
+	if path != "backup" {
+		t.Errorf("ParseGoMod() module = %q, want backup", path)
+	}
+
+	want := []Module{
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+		{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"},
+		{Path: "github.com/spf13/pflag", Version: "v1.0.5"},
+	}
+	if !reflect.DeepEqual(modules, want) {
+		t.Errorf("ParseGoMod() modules = %+v, want %+v", modules, want)
+	}
+}
+
+func TestMergeModules(t *testing.T) {
+	existing := []Module{{Path: "gopkg.in/yaml.v3", Version: "v3.0.0"}, {Path: "github.com/spf13/cobra", Version: "v1.8.0"}}
+	got := MergeModules(existing, []Module{{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"}, {Path: "github.com/fatih/color", Version: "v1.16.0"}})
+
+	want := []Module{
+		{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"},
+		{Path: "github.com/spf13/cobra", Version: "v1.8.0"},
+		{Path: "github.com/fatih/color", Version: "v1.16.0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeModules() = %+v, want %+v", got, want)
+	}
+	if existing[0].Version != "v3.0.0" {
+		t.Error("MergeModules() modified existing")
+	}
+}
+
+func TestParseModule(t *testing.T) {
+	m, err := ParseModule("github.com/spf13/cobra@v1.8.0")
+	if err != nil || m.Path != "github.com/spf13/cobra" || m.Version != "v1.8.0" {
+		t.Errorf("ParseModule() = %+v, %v", m, err)
+	}
+	if _, err := ParseModule("github.com/spf13/cobra"); err == nil {
+		t.Error("ParseModule() without a version should fail")
+	}
+	if !strings.Contains(StdlibOnly().Instruction(), "standard library") {
+		t.Error("Instruction() should mention the standard library")
+	}
+}
+
+func TestAllow(t *testing.T) {
+	p := &Policy{Mode: ModeStdlibOnly, Modules: []Module{{Path: "gopkg.in/yaml.v3", Version: "v3.0.1"}}}
+	p.Allow(Module{Path: "github.com/spf13/cobra", Version: "v1.10.1"})
+	if p.Mode != ModeAllowlist || len(p.Modules) != 1 || p.Modules[0].Path != "github.com/spf13/cobra" {
+		t.Errorf("Allow() on stdlib-only = %+v", p)
+	}
+
+	p.Allow(Module{Path: "github.com/spf13/cobra", Version: "v1.0.0"})
+	if len(p.Modules) != 1 || p.Modules[0].Version != "v1.10.1" {
+		t.Errorf("Allow() should keep the configured version, got %+v", p.Modules)
+	}
+}
@@ -0,0 +1,165 @@
+package deps
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ScriptPackage is a third-party package imported by the script being
+// converted, which the generated code has to replace
+type ScriptPackage struct {
+	Name string `json:"name"`
+	// Version is the version range declared in Manifest, or "" when the
+	// package is not declared
+	Version  string `json:"version,omitempty"`
+	Manifest string `json:"manifest,omitempty"`
+}
+
+func (p ScriptPackage) String() string {
+	if p.Version == "" {
+		return p.Name
+	}
+	return p.Name + "@" + p.Version
+}
+
+var (
+	// nodeImport matches require(), import and export ... from specifiers
+	nodeImport = regexp.MustCompile(`\brequire\s*\(\s*['"]([^'"]+)['"]\s*\)|\bimport\s*\(\s*['"]([^'"]+)['"]\s*\)|\bimport\s+(type\s+)?(?:[\w*${},\s]+?\s+from\s+)?['"]([^'"]+)['"]|\bexport\s+(?:type\s+)?[\w*${},\s]+?\s+from\s+['"]([^'"]+)['"]`)
+
+	// nodeBuiltins are the Node.js core modules, which need no package
+	nodeBuiltins = map[string]bool{
+		"assert": true, "async_hooks": true, "buffer": true, "child_process": true, "cluster": true,
+		"console": true, "constants": true, "crypto": true, "dgram": true, "dns": true, "domain": true,
+		"events": true, "fs": true, "http": true, "http2": true, "https": true, "inspector": true,
+		"module": true, "net": true, "os": true, "path": true, "perf_hooks": true, "process": true,
+		"punycode": true, "querystring": true, "readline": true, "repl": true, "stream": true,
+		"string_decoder": true, "timers": true, "tls": true, "trace_events": true, "tty": true,
+		"url": true, "util": true, "v8": true, "vm": true, "wasi": true, "worker_threads": true, "zlib": true,
+	}
+)
+
+// packageJSON holds the dependency sections of a package.json file
+type packageJSON struct {
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// NodePackages returns the npm packages a Node.js script imports, with the
+// versions declared in the nearest package.json above the script
+func NodePackages(scriptPath, content string) ([]ScriptPackage, error) {
+	names := nodeImports(content)
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	manifest, declared, err := findPackageJSON(filepath.Dir(scriptPath))
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make([]ScriptPackage, len(names))
+	for i, name := range names {
+		packages[i] = ScriptPackage{Name: name}
+		if version, ok := declared[name]; ok {
+			packages[i].Version = version
+			packages[i].Manifest = manifest
+		}
+	}
+	return packages, nil
+}
+
+// nodeImports returns the sorted names of the third-party packages imported
+// by content, ignoring relative paths, URLs, type-only imports and core modules
+func nodeImports(content string) []string {
+	seen := make(map[string]bool)
+	for _, m := range nodeImport.FindAllStringSubmatch(content, -1) {
+		if m[3] != "" {
+			continue
+		}
+		for _, specifier := range []string{m[1], m[2], m[4], m[5]} {
+			if name := packageName(specifier); name != "" {
+				seen[name] = true
+			}
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// packageName returns the package an import specifier refers to, or ""
+// when it is not a third-party package
+func packageName(specifier string) string {
+	specifier = strings.TrimPrefix(specifier, "npm:")
+	if specifier == "" || strings.HasPrefix(specifier, ".") || strings.HasPrefix(specifier, "/") ||
+		strings.HasPrefix(specifier, "node:") || strings.Contains(specifier, ":") {
+		return ""
+	}
+
+	parts := strings.SplitN(specifier, "/", 3)
+	name := parts[0]
+	if strings.HasPrefix(name, "@") {
+		if len(parts) < 2 {
+			return ""
+		}
+		name += "/" + parts[1]
+	}
+
+	// Deno's npm: specifiers may pin a version, e.g. npm:chalk@5
+	if i := strings.LastIndex(name, "@"); i > 0 {
+		name = name[:i]
+	}
+
+	if nodeBuiltins[name] {
+		return ""
+	}
+	return name
+}
+
+// findPackageJSON finds the package.json nearest to dir and returns its
+// path and declared dependency versions. No file yields no dependencies.
+func findPackageJSON(dir string) (string, map[string]string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to resolve directory: %s: %w", dir, err)
+	}
+
+	for {
+		path := filepath.Join(abs, "package.json")
+		data, err := os.ReadFile(path)
+		if err == nil {
+			var pkg packageJSON
+			if err := json.Unmarshal(data, &pkg); err != nil {
+				return "", nil, fmt.Errorf("failed to parse package.json: %s: %w", path, err)
+			}
+
+			declared := make(map[string]string)
+			for _, section := range []map[string]string{pkg.PeerDependencies, pkg.OptionalDependencies, pkg.DevDependencies, pkg.Dependencies} {
+				for name, version := range section {
+					declared[name] = version
+				}
+			}
+			return path, declared, nil
+		}
+		if !os.IsNotExist(err) {
+			return "", nil, fmt.Errorf("failed to read package.json: %s: %w", path, err)
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", nil, nil
+		}
+		abs = parent
+	}
+}
@@ -0,0 +1,56 @@
+package deps
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNodeImports(t *testing.T) {
+	content := `#!/usr/bin/env node
+import fs from 'node:fs';
+import path from "path";
+import { Command } from 'commander';
+import chalk from "npm:chalk@5";
+import type { Config } from './types';
+import type { Options } from 'got';
+import './polyfill.js';
+const axios = require('axios');
+const get = require("lodash/get");
+const { parse } = await import('@babel/parser/lib');
+export { default as yaml } from 'js-yaml';
+`
+	want := []string{"@babel/parser", "axios", "chalk", "commander", "js-yaml", "lodash"}
+	if got := nodeImports(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("nodeImports() = %v, want %v", got, want)
+	}
+}
+
+func TestNodePackages(t *testing.T) {
+	root := t.TempDir()
+	scripts := filepath.Join(root, "scripts")
+	if err := os.MkdirAll(scripts, 0755); err != nil {
+		t.Fatal(err)
+	}
+	manifest := filepath.Join(root, "package.json")
+	pkg := `{"dependencies": {"axios": "^1.6.0"}, "devDependencies": {"commander": "11.1.0", "typescript": "^5"}}`
+	if err := os.WriteFile(manifest, []byte(pkg), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "const axios = require('axios');\nconst { program } = require('commander');\nconst chalk = require('chalk');\n"
+	got, err := NodePackages(filepath.Join(scripts, "sync.js"), content)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []ScriptPackage{
+		{Name: "axios", Version: "^1.6.0", Manifest: manifest},
+		{Name: "chalk"},
+		{Name: "commander", Version: "11.1.0", Manifest: manifest},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("NodePackages() = %v, want %v", got, want)
+	}
+}
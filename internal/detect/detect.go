@@ -0,0 +1,128 @@
+package detect
+
+import (
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Rule detects one kind of sensitive data. When Group is set only that
+// submatch is the sensitive value.
+type Rule struct {
+	ID          string
+	Description string
+	Pattern     *regexp.Regexp
+	Group       int
+}
+
+// Rule IDs that other packages refer to
+const (
+	PrivateKeyRuleID     = "private-key"
+	EmailRuleID          = "email"
+	InternalDomainRuleID = "internal-domain"
+	// EntropyRuleID is the rule ID of high-entropy strings, which are found
+	// by HighEntropy rather than a pattern
+	EntropyRuleID = "high-entropy-string"
+)
+
+// entropyThreshold is the Shannon entropy, in bits per character, above
+// which a token is treated as a secret
+const entropyThreshold = 4.3
+
+var (
+	// secretRules find credentials and personal data. Rules that find more
+	// specific values come first.
+	secretRules = []Rule{
+		{ID: PrivateKeyRuleID, Description: "Private key", Pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----(?:[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----)?`)},
+		{ID: "aws-access-key-id", Description: "AWS access key ID", Pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+		{ID: "aws-secret-access-key", Description: "AWS secret access key", Pattern: regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`), Group: 1},
+		{ID: "gcp-service-account", Description: "Google Cloud service account key", Pattern: regexp.MustCompile(`"type"\s*:\s*"service_account"`)},
+		{ID: "gcp-api-key", Description: "Google API key", Pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+		{ID: "azure-storage-key", Description: "Azure storage account key", Pattern: regexp.MustCompile(`AccountKey=([A-Za-z0-9+/=]{86,88})`), Group: 1},
+		{ID: "github-token", Description: "GitHub token", Pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{40,})\b`)},
+		{ID: "slack-token", Description: "Slack token", Pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}\b`)},
+		{ID: "anthropic-api-key", Description: "Anthropic API key", Pattern: regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_-]{20,}\b`)},
+		{ID: "openai-api-key", Description: "OpenAI API key", Pattern: regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_-]{20,}\b`)},
+		{ID: "jwt", Description: "JSON Web Token", Pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\b`)},
+		{ID: EmailRuleID, Description: "Email address", Pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}\b`)},
+	}
+
+	// defaultInternalSuffixes are domain suffixes treated as internal
+	defaultInternalSuffixes = []string{"internal", "local", "corp", "lan", "intranet", "private"}
+
+	// entropyCandidate matches tokens long enough to be checked for entropy
+	entropyCandidate = regexp.MustCompile(`[A-Za-z0-9+/=_-]{20,}`)
+)
+
+// Rules returns the rules for sensitive data, ending with the internal
+// hostname rule. internalDomains lists domains, such as
+// "corp.example.com", whose hosts are internal in addition to the default
+// suffixes.
+func Rules(internalDomains []string) []Rule {
+	// Configured domains come first so that the longest suffix wins
+	var suffixes []string
+	for _, d := range internalDomains {
+		if d = strings.Trim(strings.TrimSpace(d), "."); d != "" {
+			suffixes = append(suffixes, regexp.QuoteMeta(d))
+		}
+	}
+	suffixes = append(suffixes, defaultInternalSuffixes...)
+
+	rules := append([]Rule{}, secretRules...)
+	return append(rules, Rule{
+		ID:          InternalDomainRuleID,
+		Description: "Internal hostname",
+		Pattern:     regexp.MustCompile(`(?i)\b(?:[a-z0-9](?:[a-z0-9-]*[a-z0-9])?\.)+(?:` + strings.Join(suffixes, "|") + `)\b`),
+	})
+}
+
+// HighEntropy returns the start and end offsets of the tokens in content
+// that look like random secrets
+func HighEntropy(content string) [][2]int {
+	var tokens [][2]int
+	for _, m := range entropyCandidate.FindAllStringIndex(content, -1) {
+		if isHighEntropy(content[m[0]:m[1]]) {
+			tokens = append(tokens, [2]int{m[0], m[1]})
+		}
+	}
+	return tokens
+}
+
+// isHighEntropy reports whether token looks like a random secret rather
+// than an identifier, path or hash
+func isHighEntropy(token string) bool {
+	var hasUpper, hasLower, hasDigit bool
+	for _, c := range token {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			hasUpper = true
+		case c >= 'a' && c <= 'z':
+			hasLower = true
+		case c >= '0' && c <= '9':
+			hasDigit = true
+		}
+	}
+
+	// Hex digests and identifiers lack the character mix of encoded secrets
+	if !hasDigit || !(hasUpper && hasLower) {
+		return false
+	}
+
+	return shannonEntropy(token) >= entropyThreshold
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	counts := make(map[rune]int)
+	for _, c := range s {
+		counts[c]++
+	}
+
+	var entropy float64
+	n := float64(len(s))
+	for _, count := range counts {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,95 @@
+package embedded
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ansibleShellModules are the names a task runs the shell module by
+	ansibleShellModules = []string{"shell", "ansible.builtin.shell", "ansible.legacy.shell"}
+	// ansibleTaskLists are the keys holding tasks in a play or block
+	ansibleTaskLists = []string{"pre_tasks", "tasks", "post_tasks", "handlers", "block", "rescue", "always"}
+)
+
+// ansible finds the shell tasks of a playbook or task file
+func (p *finder) ansible() {
+	p.ansibleTasks(p.root)
+}
+
+func (p *finder) ansibleTasks(list *yaml.Node) {
+	for _, task := range items(list) {
+		for _, key := range ansibleTaskLists {
+			if tasks := lookup(task, key); tasks != nil {
+				p.ansibleTasks(tasks)
+			}
+		}
+		p.ansibleShell(task)
+	}
+}
+
+func (p *finder) ansibleShell(task *yaml.Node) {
+	var module *yaml.Node
+	for _, key := range ansibleShellModules {
+		if module = lookup(task, key); module != nil {
+			break
+		}
+	}
+	if module == nil {
+		return
+	}
+
+	// The script is the module's free-form value or its cmd parameter
+	script, args := module, lookup(task, "args")
+	if module.Kind == yaml.MappingNode {
+		script, args = lookup(module, "cmd"), module
+	}
+	if !isString(script) {
+		p.note(module, "shell task without a command string is not converted")
+		return
+	}
+
+	shell := "sh"
+	if executable := scalarValue(lookup(args, "executable")); executable != "" {
+		if shell = interpreterName(executable); shell == "" {
+			p.note(script, "shell task run by %s is not converted", executable)
+			return
+		}
+	}
+
+	body := script.Value
+	if jinjaStatement.MatchString(body) {
+		p.note(script, "Jinja {%% %%} statements change the script on each run, so it is not converted")
+		return
+	}
+
+	var env []EnvVar
+	if jinjaExpression.MatchString(body) {
+		if supportsEnv(shell) {
+			body, env = substitute(body, shell, jinjaExpression, envKeys(lookup(task, "environment")), func(expr string) string {
+				return "{{ " + expr + " }}"
+			})
+		} else {
+			p.note(script, "{{ }} expressions are left in the %s script", shell)
+		}
+	}
+
+	name := scalarValue(lookup(task, "name"))
+	if name == "" {
+		name = fmt.Sprintf("task-%d", len(p.scripts)+1)
+	}
+
+	p.add(&Script{
+		Name:    slug(name),
+		Kind:    KindAnsible,
+		Shell:   shell,
+		Content: scriptContent(shell, "", body),
+		Env:     env,
+		value:   script,
+		replace: func(command string) error {
+			setScalar(script, command)
+			return setEnv(task, "environment", env)
+		},
+	})
+}
@@ -0,0 +1,576 @@
+package embedded
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kind is the kind of YAML file a script is embedded in
+type Kind string
+
+const (
+	KindGitHubActions Kind = "github-actions"
+	KindGitLabCI      Kind = "gitlab-ci"
+	KindAnsible       Kind = "ansible"
+	KindKubernetes    Kind = "kubernetes"
+	// KindSelected is a string picked by --select that is not one of the
+	// scripts found for the file's kind
+	KindSelected Kind = "selected"
+)
+
+// File is a YAML file and the scripts embedded in it
+type File struct {
+	// Kind is the kind of the first document of a known kind
+	Kind    Kind
+	Scripts []*Script
+	// Notes are the scripts and constructs that were left as they are
+	Notes []string
+
+	docs []*yaml.Node
+}
+
+// Script is a script embedded in a YAML file
+type Script struct {
+	// Name is unique within the file and names the script and its program
+	Name string
+	// Path locates the script in its document, in --select syntax
+	Path string
+	// Document is the index of the document in a multi-document file
+	Document int
+	Line     int
+	Kind     Kind
+	// Shell is the interpreter that runs the script: bash, sh, pwsh,
+	// python, perl or node
+	Shell string
+	// Content is the script as a standalone file, starting with a shebang
+	Content string
+	// Env are the template expressions the script reads from environment
+	// variables instead
+	Env []EnvVar
+
+	value   *yaml.Node
+	replace func(command string) error
+}
+
+// EnvVar is a template expression passed to the program as an environment
+// variable
+type EnvVar struct {
+	Name       string `json:"name"`
+	Expression string `json:"expression"`
+}
+
+// FileName is the name the script is written to
+func (s *Script) FileName() string {
+	return s.Name + interpreters[s.Shell].ext
+}
+
+func (s *Script) String() string {
+	if s.Document > 0 {
+		return fmt.Sprintf("document %d: %s", s.Document+1, s.Path)
+	}
+	return s.Path
+}
+
+// interpreter is a program a YAML file may run its scripts with
+type interpreter struct {
+	shebang string
+	ext     string
+}
+
+var interpreters = map[string]interpreter{
+	"bash":   {"#!/usr/bin/env bash", ".sh"},
+	"sh":     {"#!/bin/sh", ".sh"},
+	"pwsh":   {"#!/usr/bin/env pwsh", ".ps1"},
+	"python": {"#!/usr/bin/env python3", ".py"},
+	"perl":   {"#!/usr/bin/env perl", ".pl"},
+	"node":   {"#!/usr/bin/env node", ".js"},
+}
+
+// interpreterName maps a command such as /usr/bin/python3 to the
+// interpreter it runs, or "" when it is not one the tool converts
+func interpreterName(command string) string {
+	switch base := path.Base(command); base {
+	case "bash", "sh", "pwsh", "perl", "node":
+		return base
+	case "ash", "dash", "zsh", "ksh":
+		return "sh"
+	case "powershell", "powershell.exe", "pwsh.exe":
+		return "pwsh"
+	case "python", "python3":
+		return "python"
+	}
+	return ""
+}
+
+// isShell reports whether the interpreter is a POSIX shell
+func isShell(name string) bool {
+	return name == "bash" || name == "sh"
+}
+
+// scriptContent builds the standalone script: the shebang, the lines the
+// YAML file's runner adds before the script, and the script itself
+func scriptContent(shell, prelude, body string) string {
+	var b strings.Builder
+	b.WriteString(interpreters[shell].shebang)
+	b.WriteString("\n")
+	if prelude != "" {
+		b.WriteString(prelude)
+		b.WriteString("\n")
+	}
+	b.WriteString(body)
+	if !strings.HasSuffix(body, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// Load reads and parses a YAML file
+func Load(filePath string, selectors []string) (*File, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	return Parse(data, selectors)
+}
+
+// Parse finds the scripts embedded in a YAML file. Without selectors it
+// returns every script found for the file's kind; with selectors it returns
+// the scripts at or under the selected nodes, and any other selected string
+// as a shell script.
+func Parse(data []byte, selectors []string) (*File, error) {
+	f := &File{}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yaml.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		f.docs = append(f.docs, &doc)
+	}
+
+	var found []*Script
+	for i, doc := range f.docs {
+		root := documentRoot(doc)
+		if root == nil {
+			continue
+		}
+		kind := detectKind(root)
+		if kind != "" && f.Kind == "" {
+			f.Kind = kind
+		}
+
+		p := &finder{file: f, doc: i, root: root}
+		switch kind {
+		case KindGitHubActions:
+			p.github()
+		case KindGitLabCI:
+			p.gitlab()
+		case KindAnsible:
+			p.ansible()
+		case KindKubernetes:
+			p.kubernetes()
+		}
+		found = append(found, p.scripts...)
+	}
+
+	if len(selectors) > 0 {
+		var err error
+		if found, err = f.selectScripts(found, selectors); err != nil {
+			return nil, err
+		}
+	}
+
+	f.Scripts = found
+	nameScripts(found)
+	return f, nil
+}
+
+// selectScripts keeps the scripts at or under a node a selector matches.
+// Selected strings that are not found scripts become shell scripts.
+func (f *File) selectScripts(found []*Script, selectors []string) ([]*Script, error) {
+	selected := map[*yaml.Node]bool{}
+	var strs []*Script
+
+	for _, s := range selectors {
+		sel, err := ParseSelector(s)
+		if err != nil {
+			return nil, err
+		}
+
+		var matched bool
+		for i, doc := range f.docs {
+			root := documentRoot(doc)
+			if root == nil {
+				continue
+			}
+			for _, n := range sel.Match(root) {
+				matched = true
+				if selected[n] {
+					continue
+				}
+				selected[n] = true
+				if n.Kind == yaml.ScalarNode && n.Tag == "!!str" && !isFound(found, n) {
+					strs = append(strs, selectedScript(i, root, n))
+				}
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("selector %s matches nothing in the YAML file", s)
+		}
+	}
+
+	var scripts []*Script
+	for _, s := range found {
+		root := documentRoot(f.docs[s.Document])
+		for _, n := range chain(root, s.value) {
+			if selected[n] {
+				scripts = append(scripts, s)
+				break
+			}
+		}
+	}
+	return append(scripts, strs...), nil
+}
+
+func isFound(found []*Script, n *yaml.Node) bool {
+	for _, s := range found {
+		if s.value == n {
+			return true
+		}
+	}
+	return false
+}
+
+// selectedScript makes a shell script of a selected string
+func selectedScript(doc int, root, n *yaml.Node) *Script {
+	p := pathTo(root, n)
+	return &Script{
+		Name:     slug(p),
+		Path:     p,
+		Document: doc,
+		Line:     n.Line,
+		Kind:     KindSelected,
+		Shell:    "sh",
+		Content:  scriptContent("sh", "", n.Value),
+		value:    n,
+		replace: func(command string) error {
+			setScalar(n, command)
+			return nil
+		},
+	}
+}
+
+// nameScripts makes the script names unique
+func nameScripts(scripts []*Script) {
+	seen := map[string]int{}
+	for _, s := range scripts {
+		if s.Name == "" {
+			s.Name = "script"
+		}
+		seen[s.Name]++
+		if n := seen[s.Name]; n > 1 {
+			s.Name = fmt.Sprintf("%s-%d", s.Name, n)
+		}
+	}
+}
+
+// Rewrite returns the YAML file with each of the scripts replaced by a run
+// of the program command returns for it. It changes the parsed file, so it
+// is called once.
+func (f *File) Rewrite(scripts []*Script, command func(*Script) string) ([]byte, error) {
+	for _, s := range scripts {
+		if err := s.replace(command(s)); err != nil {
+			return nil, fmt.Errorf("failed to rewrite %s: %w", s, err)
+		}
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	for _, doc := range f.docs {
+		if err := enc.Encode(doc); err != nil {
+			return nil, fmt.Errorf("failed to write YAML: %w", err)
+		}
+	}
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// detectKind works out what kind of file a document is from its top-level
+// structure
+func detectKind(root *yaml.Node) Kind {
+	switch root.Kind {
+	case yaml.MappingNode:
+		if lookup(root, "apiVersion") != nil && lookup(root, "kind") != nil {
+			return KindKubernetes
+		}
+		if jobs := lookup(root, "jobs"); jobs != nil && jobs.Kind == yaml.MappingNode {
+			return KindGitHubActions
+		}
+		if runs := lookup(root, "runs"); runs != nil && scalarValue(lookup(runs, "using")) == "composite" {
+			return KindGitHubActions
+		}
+		for _, job := range gitlabJobs(root) {
+			if lookup(job.value, "script") != nil {
+				return KindGitLabCI
+			}
+		}
+	case yaml.SequenceNode:
+		for _, item := range root.Content {
+			if resolve(item).Kind != yaml.MappingNode {
+				return ""
+			}
+		}
+		return KindAnsible
+	}
+	return ""
+}
+
+// finder collects the scripts of one document
+type finder struct {
+	file    *File
+	doc     int
+	root    *yaml.Node
+	scripts []*Script
+}
+
+// add records a script found at value
+func (p *finder) add(s *Script) {
+	s.Document = p.doc
+	s.Path = pathTo(p.root, s.value)
+	s.Line = s.value.Line
+	p.scripts = append(p.scripts, s)
+}
+
+// note records something left as it is
+func (p *finder) note(n *yaml.Node, format string, args ...any) {
+	where := pathTo(p.root, n)
+	if p.doc > 0 {
+		where = fmt.Sprintf("document %d: %s", p.doc+1, where)
+	}
+	p.file.Notes = append(p.file.Notes, fmt.Sprintf("%s (line %d): %s", where, n.Line, fmt.Sprintf(format, args...)))
+}
+
+// documentRoot returns the top-level node of a document, or nil when the
+// document is empty
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	return resolve(doc.Content[0])
+}
+
+// resolve follows an alias to the node it refers to
+func resolve(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// lookup returns the value of key in a mapping, or nil
+func lookup(m *yaml.Node, key string) *yaml.Node {
+	m = resolve(m)
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return resolve(m.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// remove deletes key from a mapping
+func remove(m *yaml.Node, key string) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			m.Content = append(m.Content[:i], m.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// pair is a key and value of a mapping
+type pair struct {
+	key   string
+	value *yaml.Node
+}
+
+// pairs returns the entries of a mapping in order
+func pairs(m *yaml.Node) []pair {
+	m = resolve(m)
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	var ps []pair
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		ps = append(ps, pair{m.Content[i].Value, resolve(m.Content[i+1])})
+	}
+	return ps
+}
+
+// items returns the entries of a sequence
+func items(n *yaml.Node) []*yaml.Node {
+	n = resolve(n)
+	if n == nil || n.Kind != yaml.SequenceNode {
+		return nil
+	}
+	list := make([]*yaml.Node, len(n.Content))
+	for i, item := range n.Content {
+		list[i] = resolve(item)
+	}
+	return list
+}
+
+// scalarValue returns the value of a scalar node, or ""
+func scalarValue(n *yaml.Node) string {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return ""
+	}
+	return n.Value
+}
+
+// isString reports whether n is a string scalar
+func isString(n *yaml.Node) bool {
+	return n != nil && n.Kind == yaml.ScalarNode && n.Tag == "!!str"
+}
+
+// setScalar makes n the plain string value
+func setScalar(n *yaml.Node, value string) {
+	*n = yaml.Node{
+		Kind:        yaml.ScalarNode,
+		Tag:         "!!str",
+		Value:       value,
+		HeadComment: n.HeadComment,
+		LineComment: n.LineComment,
+		FootComment: n.FootComment,
+	}
+}
+
+// stringNode makes a plain string node
+func stringNode(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+// setEnv adds name: value to the mapping under key in m, creating it when
+// needed
+func setEnv(m *yaml.Node, key string, env []EnvVar) error {
+	if len(env) == 0 {
+		return nil
+	}
+
+	mapping := lookup(m, key)
+	if mapping == nil {
+		mapping = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		m.Content = append(m.Content, stringNode(key), mapping)
+	}
+	if mapping.Kind != yaml.MappingNode {
+		return fmt.Errorf("%s is not a mapping, so %s cannot be added to it", key, envNames(env))
+	}
+
+	for _, e := range env {
+		if lookup(mapping, e.Name) != nil {
+			continue
+		}
+		mapping.Content = append(mapping.Content, stringNode(e.Name), stringNode(e.Expression))
+	}
+	return nil
+}
+
+func envNames(env []EnvVar) string {
+	names := make([]string, len(env))
+	for i, e := range env {
+		names[i] = e.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// chain returns the nodes from root down to target, or nil
+func chain(root, target *yaml.Node) []*yaml.Node {
+	if root == target {
+		return []*yaml.Node{root}
+	}
+	var children []*yaml.Node
+	switch root.Kind {
+	case yaml.MappingNode:
+		for i := 1; i < len(root.Content); i += 2 {
+			children = append(children, root.Content[i])
+		}
+	case yaml.SequenceNode:
+		children = root.Content
+	}
+	for _, child := range children {
+		if c := chain(resolve(child), target); c != nil {
+			return append([]*yaml.Node{root}, c...)
+		}
+	}
+	return nil
+}
+
+// pathTo formats the location of target in --select syntax, such as
+// jobs.build.steps[2].run
+func pathTo(root, target *yaml.Node) string {
+	var b strings.Builder
+	nodes := chain(root, target)
+	for i := 0; i+1 < len(nodes); i++ {
+		parent, child := nodes[i], nodes[i+1]
+		switch parent.Kind {
+		case yaml.MappingNode:
+			for j := 1; j < len(parent.Content); j += 2 {
+				if resolve(parent.Content[j]) == child {
+					if b.Len() > 0 {
+						b.WriteString(".")
+					}
+					b.WriteString(parent.Content[j-1].Value)
+					break
+				}
+			}
+		case yaml.SequenceNode:
+			for j, item := range parent.Content {
+				if resolve(item) == child {
+					fmt.Fprintf(&b, "[%d]", j)
+					break
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+var nonSlug = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug turns a step, task or container name into a file name
+func slug(s string) string {
+	s = strings.Trim(nonSlug.ReplaceAllString(strings.ToLower(s), "-"), "-")
+	if len(s) > 40 {
+		s = strings.TrimRight(s[:40], "-")
+	}
+	return s
+}
+
+// joinName joins the non-empty slugs of parts with dashes
+func joinName(parts ...string) string {
+	var list []string
+	for _, p := range parts {
+		if s := slug(p); s != "" {
+			list = append(list, s)
+		}
+	}
+	return strings.Join(list, "-")
+}
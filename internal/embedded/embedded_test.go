@@ -0,0 +1,385 @@
+package embedded
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testWorkflow = `name: release
+on:
+  push:
+    tags: ["v*"]
+defaults:
+  run:
+    shell: bash
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - uses: actions/checkout@v4
+      - name: Run tests
+        run: go test ./...
+      - id: package
+        env:
+          GOOS: linux
+        run: |
+          echo "packaging ${{ github.ref_name }}"
+          tar czf 'dist-${{ github.ref_name }}.tgz' dist
+  notify:
+    runs-on: windows-latest
+    steps:
+      - shell: pwsh
+        run: Write-Output "${{ needs.build.result }}"
+      - shell: cmd
+        run: echo done
+`
+
+func TestParse_GitHubActions(t *testing.T) {
+	f, err := Parse([]byte(testWorkflow), nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if f.Kind != KindGitHubActions {
+		t.Errorf("Kind = %q, want %q", f.Kind, KindGitHubActions)
+	}
+
+	if got, want := scriptNames(f), []string{"build-run-tests", "build-package", "notify-step-1"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("scripts = %v, want %v", got, want)
+	}
+
+	tests := f.Scripts[0]
+	if tests.Path != "jobs.build.steps[1].run" || tests.Line != 14 {
+		t.Errorf("path = %s (line %d), want jobs.build.steps[1].run (line 14)", tests.Path, tests.Line)
+	}
+	if want := "#!/usr/bin/env bash\nset -eo pipefail\ngo test ./...\n"; tests.Content != want {
+		t.Errorf("Content = %q, want %q", tests.Content, want)
+	}
+
+	pkg := f.Scripts[1]
+	for _, want := range []string{
+		`echo "packaging ${GITHUB_REF_NAME}"`,
+		`tar czf 'dist-'"${GITHUB_REF_NAME}"'.tgz' dist`,
+	} {
+		if !strings.Contains(pkg.Content, want) {
+			t.Errorf("Content = %q, want it to contain %q", pkg.Content, want)
+		}
+	}
+	if want := []EnvVar{{Name: "GITHUB_REF_NAME", Expression: "${{ github.ref_name }}"}}; !reflect.DeepEqual(pkg.Env, want) {
+		t.Errorf("Env = %v, want %v", pkg.Env, want)
+	}
+
+	pwsh := f.Scripts[2]
+	if pwsh.FileName() != "notify-step-1.ps1" || !strings.Contains(pwsh.Content, "$ErrorActionPreference = 'stop'\nWrite-Output \"${env:NEEDS_BUILD_RESULT}\"") {
+		t.Errorf("pwsh script %s = %q", pwsh.FileName(), pwsh.Content)
+	}
+
+	if len(f.Notes) != 1 || !strings.Contains(f.Notes[0], `shell "cmd"`) {
+		t.Errorf("Notes = %v, want the cmd step", f.Notes)
+	}
+}
+
+func TestRewrite_GitHubActions(t *testing.T) {
+	f, err := Parse([]byte(testWorkflow), []string{"jobs.build.steps[id=package]"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	out, err := f.Rewrite(f.Scripts, func(s *Script) string { return "bin/" + s.Name })
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+
+	for _, want := range []string{
+		"on:\n  push:",
+		"      - name: Run tests\n        run: go test ./...\n",
+		"      - id: package\n        env:\n          GOOS: linux\n          GITHUB_REF_NAME: ${{ github.ref_name }}\n        run: bin/build-package\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Rewrite() =\n%s\nwant it to contain %q", out, want)
+		}
+	}
+}
+
+func TestParse_GitLabCI(t *testing.T) {
+	const pipeline = `stages: [test]
+variables:
+  GO: "1.25"
+.setup: &setup
+  - apt-get update
+test:
+  stage: test
+  before_script: *setup
+  script:
+    - go vet ./...
+    - - go test ./...
+      - go build ./...
+  after_script: echo done
+lint:
+  script:
+    - !reference [.setup]
+`
+	f, err := Parse([]byte(pipeline), nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if f.Kind != KindGitLabCI {
+		t.Errorf("Kind = %q, want %q", f.Kind, KindGitLabCI)
+	}
+
+	if got, want := scriptNames(f), []string{"test", "test-after"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("scripts = %v, want %v", got, want)
+	}
+	if want := "#!/usr/bin/env bash\nset -eo pipefail\ngo vet ./...\ngo test ./...\ngo build ./...\n"; f.Scripts[0].Content != want {
+		t.Errorf("Content = %q, want %q", f.Scripts[0].Content, want)
+	}
+	if len(f.Notes) != 2 {
+		t.Errorf("Notes = %v, want the shared before_script and the !reference", f.Notes)
+	}
+
+	out, err := f.Rewrite(f.Scripts, func(s *Script) string { return "./" + s.Name })
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	if !strings.Contains(string(out), "  script:\n    - ./test\n  after_script: ./test-after\n") {
+		t.Errorf("Rewrite() =\n%s", out)
+	}
+}
+
+func TestParse_Ansible(t *testing.T) {
+	const playbook = `- hosts: web
+  tasks:
+    - name: Rotate logs
+      ansible.builtin.shell: find /var/log/{{ app_name }} -mtime +7 -delete
+    - block:
+        - shell:
+            cmd: echo '{{ inventory_hostname }}'
+            executable: /bin/bash
+          environment:
+            INVENTORY_HOSTNAME: other
+        - name: Templated
+          shell: "{% if debug %}set -x{% endif %}\nrun"
+    - name: Copy
+      copy:
+        src: a
+        dest: b
+`
+	f, err := Parse([]byte(playbook), nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if f.Kind != KindAnsible {
+		t.Errorf("Kind = %q, want %q", f.Kind, KindAnsible)
+	}
+
+	if got, want := scriptNames(f), []string{"rotate-logs", "task-2"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("scripts = %v, want %v", got, want)
+	}
+	if want := "#!/bin/sh\nfind /var/log/${APP_NAME} -mtime +7 -delete\n"; f.Scripts[0].Content != want {
+		t.Errorf("Content = %q, want %q", f.Scripts[0].Content, want)
+	}
+
+	echo := f.Scripts[1]
+	if echo.Shell != "bash" || !strings.Contains(echo.Content, `echo ''"${INVENTORY_HOSTNAME_2}"''`) {
+		t.Errorf("script = %s %q", echo.Shell, echo.Content)
+	}
+	if len(f.Notes) != 1 || !strings.Contains(f.Notes[0], "Jinja") {
+		t.Errorf("Notes = %v, want the templated task", f.Notes)
+	}
+
+	out, err := f.Rewrite(f.Scripts, func(s *Script) string { return "/opt/bin/" + s.Name })
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	for _, want := range []string{
+		"      ansible.builtin.shell: /opt/bin/rotate-logs\n      environment:\n        APP_NAME: '{{ app_name }}'\n",
+		"            cmd: /opt/bin/task-2\n",
+		"            INVENTORY_HOSTNAME_2: '{{ inventory_hostname }}'\n",
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Rewrite() =\n%s\nwant it to contain %q", out, want)
+		}
+	}
+}
+
+func TestParse_Kubernetes(t *testing.T) {
+	const manifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: settings
+---
+apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: backup
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          initContainers:
+            - name: wait
+              image: busybox
+              command: ["sh", "-ec", "until nc -z db 5432; do sleep 1; done"]
+          containers:
+            - name: dump
+              image: postgres
+              env:
+                - name: DB
+                  value: app
+              command: ["/bin/bash", "-c"]
+              args:
+                - |
+                  pg_dump "$(DB)" > /backup/$(date +%F).sql
+                  echo "cost: $$5"
+                - dump
+                - --verbose
+            - name: server
+              image: nginx
+              command: ["nginx", "-g", "daemon off;"]
+`
+	f, err := Parse([]byte(manifest), nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if f.Kind != KindKubernetes {
+		t.Errorf("Kind = %q, want %q", f.Kind, KindKubernetes)
+	}
+
+	if got, want := scriptNames(f), []string{"backup-wait", "backup-dump"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("scripts = %v, want %v", got, want)
+	}
+	if want := "#!/bin/sh\nset -e\nuntil nc -z db 5432; do sleep 1; done\n"; f.Scripts[0].Content != want {
+		t.Errorf("Content = %q, want %q", f.Scripts[0].Content, want)
+	}
+
+	dump := f.Scripts[1]
+	if dump.Document != 1 || dump.Path != "spec.jobTemplate.spec.template.spec.containers[0].args[0]" {
+		t.Errorf("location = %s", dump)
+	}
+	if want := "#!/usr/bin/env bash\npg_dump \"${DB}\" > /backup/$(date +%F).sql\necho \"cost: $5\"\n"; dump.Content != want {
+		t.Errorf("Content = %q, want %q", dump.Content, want)
+	}
+
+	out, err := f.Rewrite(f.Scripts, func(s *Script) string { return "/usr/local/bin/" + s.Name })
+	if err != nil {
+		t.Fatalf("Rewrite() error = %v", err)
+	}
+	for _, want := range []string{
+		"kind: ConfigMap\nmetadata:\n  name: settings\n---\n",
+		`command: ["/usr/local/bin/backup-wait"]`,
+		`command: ["/usr/local/bin/backup-dump", --verbose]`,
+		`command: ["nginx", "-g", "daemon off;"]`,
+	} {
+		if !strings.Contains(string(out), want) {
+			t.Errorf("Rewrite() =\n%s\nwant it to contain %q", out, want)
+		}
+	}
+	if strings.Contains(string(out), "args:") {
+		t.Errorf("Rewrite() kept args:\n%s", out)
+	}
+}
+
+func TestParse_Selectors(t *testing.T) {
+	const config = `deploy:
+  hooks:
+    - name: migrate
+      exec: ./manage.py migrate && echo ok
+    - name: restart
+      exec: systemctl restart app
+`
+	f, err := Parse([]byte(config), nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if f.Kind != "" || len(f.Scripts) != 0 {
+		t.Errorf("Parse() without selectors = %q %v, want nothing", f.Kind, scriptNames(f))
+	}
+
+	f, err = Parse([]byte(config), []string{"deploy.hooks[name=migrate].exec", "deploy.hooks[*].exec"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, want := scriptNames(f), []string{"deploy-hooks-0-exec", "deploy-hooks-1-exec"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("scripts = %v, want %v", got, want)
+	}
+	if s := f.Scripts[0]; s.Kind != KindSelected || s.Content != "#!/bin/sh\n./manage.py migrate && echo ok\n" {
+		t.Errorf("script = %s %q", s.Kind, s.Content)
+	}
+
+	if _, err := Parse([]byte(config), []string{"deploy.hooks[name=seed]"}); err == nil {
+		t.Error("Parse() with a selector matching nothing should fail")
+	}
+}
+
+func TestParse_SelectorPicksScriptsUnderNode(t *testing.T) {
+	f, err := Parse([]byte(testWorkflow), []string{"jobs.build"})
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, want := scriptNames(f), []string{"build-run-tests", "build-package"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("scripts = %v, want %v", got, want)
+	}
+}
+
+func TestParseSelector(t *testing.T) {
+	for _, text := range []string{"jobs.build.steps[2].run", "jobs.*.steps[name=Deploy]", "[0].tasks[*]", "a[x = 'y z']"} {
+		if _, err := ParseSelector(text); err != nil {
+			t.Errorf("ParseSelector(%q) error = %v", text, err)
+		}
+	}
+	for _, text := range []string{"", "jobs.", "jobs..run", "steps[2", "steps[-1]", "steps[=x]", "steps[2]x"} {
+		if _, err := ParseSelector(text); err == nil {
+			t.Errorf("ParseSelector(%q) should fail", text)
+		}
+	}
+}
+
+func TestParse_DuplicateNames(t *testing.T) {
+	const workflow = `jobs:
+  ci:
+    steps:
+      - name: Build
+        run: make
+      - name: build
+        run: make all
+`
+	f, err := Parse([]byte(workflow), nil)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got, want := scriptNames(f), []string{"ci-build", "ci-build-2"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("scripts = %v, want %v", got, want)
+	}
+}
+
+func TestGitHubShell(t *testing.T) {
+	tests := []struct {
+		spec    string
+		shell   string
+		prelude string
+	}{
+		{"", "bash", "set -e"},
+		{"bash", "bash", "set -eo pipefail"},
+		{"sh", "sh", "set -e"},
+		{"bash -l -x {0}", "bash", "set -x"},
+		{"/bin/zsh -o errexit {0}", "sh", "set -o errexit"},
+		{"python", "python", ""},
+		{"perl {0}", "perl", ""},
+		{"cmd", "", ""},
+	}
+	for _, tt := range tests {
+		shell, prelude := githubShell(tt.spec)
+		if shell != tt.shell || prelude != tt.prelude {
+			t.Errorf("githubShell(%q) = %q, %q, want %q, %q", tt.spec, shell, prelude, tt.shell, tt.prelude)
+		}
+	}
+}
+
+func scriptNames(f *File) []string {
+	var names []string
+	for _, s := range f.Scripts {
+		names = append(names, s.Name)
+	}
+	return names
+}
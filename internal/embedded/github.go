@@ -0,0 +1,144 @@
+package embedded
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// githubShells are the commands GitHub Actions runs the shell keywords
+// with; "" is a step without a shell
+var githubShells = map[string]string{
+	"":           "bash -e {0}",
+	"bash":       "bash --noprofile --norc -eo pipefail {0}",
+	"sh":         "sh -e {0}",
+	"pwsh":       "pwsh -command \". '{0}'\"",
+	"powershell": "powershell -command \". '{0}'\"",
+	"python":     "python {0}",
+}
+
+// github finds the run steps of a workflow's jobs, or of a composite
+// action
+func (p *finder) github() {
+	if runs := lookup(p.root, "runs"); runs != nil {
+		p.githubSteps("", lookup(runs, "steps"), "")
+	}
+
+	defaultShell := githubDefaultShell(p.root)
+	for _, job := range pairs(lookup(p.root, "jobs")) {
+		shell := defaultShell
+		if s := githubDefaultShell(job.value); s != "" {
+			shell = s
+		}
+		p.githubSteps(job.key, lookup(job.value, "steps"), shell)
+	}
+}
+
+// githubDefaultShell returns defaults.run.shell of a workflow or job
+func githubDefaultShell(n *yaml.Node) string {
+	return scalarValue(lookup(lookup(lookup(n, "defaults"), "run"), "shell"))
+}
+
+func (p *finder) githubSteps(job string, steps *yaml.Node, defaultShell string) {
+	for i, step := range items(steps) {
+		run := lookup(step, "run")
+		if !isString(run) {
+			continue
+		}
+
+		spec := defaultShell
+		if s := scalarValue(lookup(step, "shell")); s != "" {
+			spec = s
+		}
+		shell, prelude := githubShell(spec)
+		if shell == "" {
+			p.note(run, "steps with shell %q are not converted", spec)
+			continue
+		}
+
+		name := scalarValue(lookup(step, "id"))
+		if name == "" {
+			name = scalarValue(lookup(step, "name"))
+		}
+		if name == "" {
+			name = fmt.Sprintf("step-%d", i+1)
+		}
+
+		body := run.Value
+		var env []EnvVar
+		if githubExpression.MatchString(body) {
+			if supportsEnv(shell) {
+				body, env = substitute(body, shell, githubExpression, envKeys(lookup(step, "env")), func(expr string) string {
+					return "${{ " + expr + " }}"
+				})
+			} else {
+				p.note(run, "${{ }} expressions are left in the %s script", shell)
+			}
+		}
+
+		p.add(&Script{
+			Name:    joinName(job, name),
+			Kind:    KindGitHubActions,
+			Shell:   shell,
+			Content: scriptContent(shell, prelude, body),
+			Env:     env,
+			value:   run,
+			replace: func(command string) error {
+				setScalar(run, command)
+				// Composite actions need a shell on every step, and a
+				// python default would not run the program
+				if shellNode := lookup(step, "shell"); shellNode != nil {
+					setScalar(shellNode, "bash")
+				} else if !isShell(shell) {
+					step.Content = append(step.Content, stringNode("shell"), stringNode("bash"))
+				}
+				return setEnv(step, "env", env)
+			},
+		})
+	}
+}
+
+// githubShell returns the interpreter of a step's shell and the lines that
+// give the script the options GitHub Actions runs it with
+func githubShell(spec string) (shell, prelude string) {
+	template, ok := githubShells[spec]
+	if !ok {
+		template = spec
+	}
+
+	words := strings.Fields(template)
+	shell = interpreterName(words[0])
+	switch {
+	case isShell(shell):
+		if options := setOptions(words[1:]); len(options) > 0 {
+			prelude = "set " + strings.Join(options, " ")
+		}
+	case shell == "pwsh" && ok:
+		prelude = "$ErrorActionPreference = 'stop'"
+	}
+	return shell, prelude
+}
+
+// setOptions returns the shell options in args that set accepts, such as
+// -e and -o pipefail, leaving out invocation-only ones such as -l
+func setOptions(args []string) []string {
+	var options []string
+	for i := 0; i < len(args); i++ {
+		a := args[i]
+		switch {
+		case (a == "-o" || a == "+o") && i+1 < len(args):
+			options = append(options, a, args[i+1])
+			i++
+		case len(a) > 1 && (a[0] == '-' || a[0] == '+') && strings.Trim(a[1:], "abefhkmnptuvxBCEHPT") == "":
+			options = append(options, a)
+		case strings.HasPrefix(a, "-") && strings.HasSuffix(a, "o") && i+1 < len(args):
+			// -eo pipefail
+			if flags := a[1 : len(a)-1]; strings.Trim(flags, "abefhkmnptuvxBCEHPT") == "" {
+				options = append(options, a, args[i+1])
+				i++
+			}
+		}
+	}
+	return options
+}
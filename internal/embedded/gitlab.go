@@ -0,0 +1,100 @@
+package embedded
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// gitlabKeywords are the top-level keys of .gitlab-ci.yml that are not
+// jobs
+var gitlabKeywords = map[string]bool{
+	"default": true, "include": true, "stages": true, "variables": true, "workflow": true,
+	"image": true, "services": true, "cache": true, "before_script": true, "after_script": true,
+	"spec": true,
+}
+
+// gitlabScripts are the script keys of a job and the name suffix of each.
+// after_script runs in a shell of its own.
+var gitlabScripts = []struct {
+	key    string
+	suffix string
+}{
+	{"before_script", "before"},
+	{"script", ""},
+	{"after_script", "after"},
+}
+
+// gitlabJobs returns the jobs of a pipeline
+func gitlabJobs(root *yaml.Node) []pair {
+	var jobs []pair
+	for _, p := range pairs(root) {
+		if !gitlabKeywords[p.key] && p.value.Kind == yaml.MappingNode {
+			jobs = append(jobs, p)
+		}
+	}
+	return jobs
+}
+
+// gitlab finds the scripts of a pipeline's jobs
+func (p *finder) gitlab() {
+	for _, job := range gitlabJobs(p.root) {
+		for _, key := range gitlabScripts {
+			value := lookup(job.value, key.key)
+			if value == nil {
+				continue
+			}
+			if value.Anchor != "" {
+				p.note(value, "%s is shared through the anchor &%s and is not converted", key.key, value.Anchor)
+				continue
+			}
+
+			lines, ok := gitlabLines(value)
+			if !ok {
+				p.note(value, "%s uses !reference or other entries that are not strings and is not converted", key.key)
+				continue
+			}
+
+			p.add(&Script{
+				Name: joinName(job.key, key.suffix),
+				Kind: KindGitLabCI,
+				// The runner stops at the first failing command
+				Shell:   "bash",
+				Content: scriptContent("bash", "set -eo pipefail", strings.Join(lines, "\n")),
+				value:   value,
+				replace: func(command string) error {
+					if value.Kind == yaml.SequenceNode {
+						value.Content = []*yaml.Node{stringNode(command)}
+						return nil
+					}
+					setScalar(value, command)
+					return nil
+				},
+			})
+		}
+	}
+}
+
+// gitlabLines returns the commands of a script key, which is a string or a
+// list of strings and nested lists
+func gitlabLines(n *yaml.Node) ([]string, bool) {
+	if isString(n) {
+		return []string{n.Value}, true
+	}
+	if n.Kind != yaml.SequenceNode || n.Tag != "!!seq" {
+		return nil, false
+	}
+
+	var lines []string
+	for _, item := range n.Content {
+		if item.Kind == yaml.AliasNode {
+			return nil, false
+		}
+		nested, ok := gitlabLines(item)
+		if !ok {
+			return nil, false
+		}
+		lines = append(lines, nested...)
+	}
+	return lines, true
+}
@@ -0,0 +1,131 @@
+package embedded
+
+import (
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// containerLists are the keys of a pod spec holding containers
+var containerLists = map[string]bool{"containers": true, "initContainers": true, "ephemeralContainers": true}
+
+// kubernetesReference matches $(VAR), which Kubernetes replaces with the
+// container's variable before running the command, and $$, its escape
+var kubernetesReference = regexp.MustCompile(`\$\$|\$\(([A-Za-z_][A-Za-z0-9_]*)\)`)
+
+// kubernetes finds the containers of a manifest whose command runs an
+// inline script, such as ["sh", "-c", "..."]
+func (p *finder) kubernetes() {
+	p.kubernetesContainers(p.root, "")
+}
+
+// kubernetesContainers walks n for pod specs. object is the name of the
+// enclosing object, which List items and templates change.
+func (p *finder) kubernetesContainers(n *yaml.Node, object string) {
+	switch n.Kind {
+	case yaml.MappingNode:
+		if name := scalarValue(lookup(lookup(n, "metadata"), "name")); name != "" && lookup(n, "kind") != nil {
+			object = name
+		}
+		for _, e := range pairs(n) {
+			if containerLists[e.key] {
+				for _, c := range items(e.value) {
+					p.kubernetesContainer(c, object)
+				}
+				continue
+			}
+			p.kubernetesContainers(e.value, object)
+		}
+	case yaml.SequenceNode:
+		for _, item := range items(n) {
+			p.kubernetesContainers(item, object)
+		}
+	}
+}
+
+func (p *finder) kubernetesContainer(c *yaml.Node, object string) {
+	command := lookup(c, "command")
+	if command == nil {
+		return
+	}
+
+	argv := append(items(command), items(lookup(c, "args"))...)
+	if len(argv) < 3 {
+		return
+	}
+	for _, arg := range argv {
+		if !isString(arg) {
+			return
+		}
+	}
+
+	shell, flag := interpreterName(argv[0].Value), argv[1].Value
+	if shell == "" || !strings.HasPrefix(flag, "-") || strings.HasPrefix(flag, "--") || !strings.HasSuffix(flag, "c") {
+		return
+	}
+
+	var prelude string
+	if options := strings.TrimSuffix(flag[1:], "c"); options != "" {
+		if !isShell(shell) {
+			p.note(argv[1], "%s options %s are not converted", shell, flag)
+			return
+		}
+		prelude = "set -" + options
+	}
+
+	script := argv[2]
+	body := script.Value
+	if isShell(shell) {
+		body = kubernetesExpand(body, kubernetesEnv(c))
+	} else if kubernetesReference.MatchString(body) {
+		p.note(script, "$(VAR) references are left in the %s script", shell)
+	}
+
+	// After the script come $0 and the script's arguments
+	var extra []*yaml.Node
+	if len(argv) > 4 {
+		extra = argv[4:]
+	}
+
+	p.add(&Script{
+		Name:    joinName(object, scalarValue(lookup(c, "name"))),
+		Kind:    KindKubernetes,
+		Shell:   shell,
+		Content: scriptContent(shell, prelude, body),
+		value:   script,
+		replace: func(cmd string) error {
+			program := stringNode(cmd)
+			program.Style = argv[0].Style
+			command.Content = append([]*yaml.Node{program}, extra...)
+			remove(c, "args")
+			return nil
+		},
+	})
+}
+
+// kubernetesEnv returns the variables a container sets in env
+func kubernetesEnv(c *yaml.Node) map[string]bool {
+	names := map[string]bool{}
+	for _, e := range items(lookup(c, "env")) {
+		if name := scalarValue(lookup(e, "name")); name != "" {
+			names[name] = true
+		}
+	}
+	return names
+}
+
+// kubernetesExpand does what Kubernetes does to a command before running
+// it: $(VAR) of a defined variable becomes a shell reference to the same
+// environment variable, and $$ becomes $
+func kubernetesExpand(script string, defined map[string]bool) string {
+	return kubernetesReference.ReplaceAllStringFunc(script, func(m string) string {
+		if m == "$$" {
+			return "$"
+		}
+		if name := m[2 : len(m)-1]; defined[name] {
+			return "${" + name + "}"
+		}
+		return m
+	})
+}
@@ -0,0 +1,170 @@
+package embedded
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Selector picks nodes of a YAML document by path. A path is a list of
+// mapping keys separated by dots, each followed by any number of filters in
+// brackets:
+//
+//	jobs.build.steps[2].run      the run key of the third step
+//	jobs.*.steps[name=Deploy]    every step named Deploy, in any job
+//	[0].tasks[*]                 every task of the first play
+type Selector struct {
+	text     string
+	segments []segment
+}
+
+// segment is a mapping key, "*" for every value or "" for none, and the
+// filters applied after it
+type segment struct {
+	key     string
+	filters []filter
+}
+
+// filter picks entries of a sequence, or values of a mapping: by index,
+// all of them ("*"), or those whose field equals value
+type filter struct {
+	index int
+	all   bool
+	field string
+	value string
+}
+
+// ParseSelector parses a --select path
+func ParseSelector(text string) (*Selector, error) {
+	sel := &Selector{text: text}
+
+	rest := strings.TrimSpace(text)
+	if rest == "" {
+		return nil, fmt.Errorf("invalid selector: empty path")
+	}
+
+	for rest != "" {
+		var seg segment
+		end := strings.IndexAny(rest, ".[")
+		if end < 0 {
+			end = len(rest)
+		}
+		seg.key, rest = rest[:end], rest[end:]
+
+		for strings.HasPrefix(rest, "[") {
+			closeAt := strings.Index(rest, "]")
+			if closeAt < 0 {
+				return nil, fmt.Errorf("invalid selector %s: missing ]", text)
+			}
+			f, err := parseFilter(rest[1:closeAt])
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector %s: %w", text, err)
+			}
+			seg.filters = append(seg.filters, f)
+			rest = rest[closeAt+1:]
+		}
+
+		if seg.key == "" && len(seg.filters) == 0 {
+			return nil, fmt.Errorf("invalid selector %s: empty key", text)
+		}
+		sel.segments = append(sel.segments, seg)
+
+		if strings.HasPrefix(rest, ".") {
+			rest = rest[1:]
+			if rest == "" {
+				return nil, fmt.Errorf("invalid selector %s: trailing dot", text)
+			}
+		} else if rest != "" {
+			return nil, fmt.Errorf("invalid selector %s: unexpected %q", text, rest)
+		}
+	}
+
+	return sel, nil
+}
+
+func parseFilter(s string) (filter, error) {
+	s = strings.TrimSpace(s)
+	if s == "*" {
+		return filter{all: true}, nil
+	}
+	if field, value, ok := strings.Cut(s, "="); ok {
+		field, value = strings.TrimSpace(field), strings.TrimSpace(value)
+		if field == "" {
+			return filter{}, fmt.Errorf("missing field name in [%s]", s)
+		}
+		return filter{field: field, value: strings.Trim(value, `"'`)}, nil
+	}
+	index, err := strconv.Atoi(s)
+	if err != nil || index < 0 {
+		return filter{}, fmt.Errorf("[%s] is not an index, * or field=value", s)
+	}
+	return filter{index: index}, nil
+}
+
+func (s *Selector) String() string {
+	return s.text
+}
+
+// Match returns the nodes under root that the selector picks
+func (s *Selector) Match(root *yaml.Node) []*yaml.Node {
+	nodes := []*yaml.Node{resolve(root)}
+	for _, seg := range s.segments {
+		if seg.key != "" {
+			var next []*yaml.Node
+			for _, n := range nodes {
+				if seg.key == "*" {
+					for _, p := range pairs(n) {
+						next = append(next, p.value)
+					}
+				} else if v := lookup(n, seg.key); v != nil {
+					next = append(next, v)
+				}
+			}
+			nodes = next
+		}
+
+		for _, f := range seg.filters {
+			var next []*yaml.Node
+			for _, n := range nodes {
+				next = append(next, f.apply(n)...)
+			}
+			nodes = next
+		}
+	}
+	return nodes
+}
+
+func (f filter) apply(n *yaml.Node) []*yaml.Node {
+	var entries []*yaml.Node
+	switch n.Kind {
+	case yaml.SequenceNode:
+		entries = items(n)
+	case yaml.MappingNode:
+		if !f.all && f.field == "" {
+			return nil
+		}
+		for _, p := range pairs(n) {
+			entries = append(entries, p.value)
+		}
+	default:
+		return nil
+	}
+
+	switch {
+	case f.all:
+		return entries
+	case f.field != "":
+		var matched []*yaml.Node
+		for _, e := range entries {
+			if v := lookup(e, f.field); v != nil && v.Kind == yaml.ScalarNode && v.Value == f.value {
+				matched = append(matched, e)
+			}
+		}
+		return matched
+	case f.index < len(entries):
+		return []*yaml.Node{entries[f.index]}
+	}
+	return nil
+}
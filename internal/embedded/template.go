@@ -0,0 +1,131 @@
+package embedded
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// githubExpression matches ${{ github.ref }}
+	githubExpression = regexp.MustCompile(`\$\{\{\s*(.*?)\s*\}\}`)
+	// jinjaExpression matches {{ inventory_hostname }}
+	jinjaExpression = regexp.MustCompile(`\{\{-?\s*(.*?)\s*-?\}\}`)
+	// jinjaStatement matches {% if ... %}, which changes the script itself
+	jinjaStatement = regexp.MustCompile(`\{%.*?%\}`)
+
+	nonIdentifier = regexp.MustCompile(`[^A-Z0-9]+`)
+)
+
+// reservedEnv are environment variables a template expression must not
+// replace
+var reservedEnv = map[string]bool{
+	"PATH": true, "HOME": true, "USER": true, "SHELL": true, "PWD": true,
+	"IFS": true, "LANG": true, "TERM": true, "TMPDIR": true, "HOSTNAME": true,
+}
+
+// supportsEnv reports whether substitute can rewrite expressions in a
+// script for the interpreter. In other languages the expressions usually
+// sit inside string literals, where an environment lookup does not fit.
+func supportsEnv(shell string) bool {
+	return isShell(shell) || shell == "pwsh"
+}
+
+// substitute replaces the template expressions re matches in body with
+// references to environment variables, which the YAML file sets to the
+// expression instead. expression formats a matched expression for the YAML
+// file; names already in taken are not used.
+func substitute(body, shell string, re *regexp.Regexp, taken map[string]bool, expression func(string) string) (string, []EnvVar) {
+	var env []EnvVar
+	names := map[string]string{}
+	used := map[string]bool{}
+	for name := range taken {
+		used[name] = true
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, m := range re.FindAllStringSubmatchIndex(body, -1) {
+		expr := body[m[2]:m[3]]
+		name, ok := names[expr]
+		if !ok {
+			name = envName(expr, len(env)+1, used)
+			names[expr] = name
+			used[name] = true
+			env = append(env, EnvVar{Name: name, Expression: expression(expr)})
+		}
+
+		b.WriteString(body[last:m[0]])
+		b.WriteString(envReference(name, shell, singleQuoted(body, m[0])))
+		last = m[1]
+	}
+	b.WriteString(body[last:])
+
+	return b.String(), env
+}
+
+// envName derives a variable name from an expression, such as
+// INPUTS_VERSION from inputs.version
+func envName(expr string, n int, used map[string]bool) string {
+	name := strings.Trim(nonIdentifier.ReplaceAllString(strings.ToUpper(expr), "_"), "_")
+	if name == "" || len(name) > 40 || name[0] >= '0' && name[0] <= '9' {
+		name = fmt.Sprintf("EXPR_%d", n)
+	}
+	if reservedEnv[name] {
+		name += "_VALUE"
+	}
+
+	for base, i := name, 2; used[name]; i++ {
+		name = fmt.Sprintf("%s_%d", base, i)
+	}
+	return name
+}
+
+// envReference is how a script reads the variable name. A shell reference
+// inside single quotes closes and reopens them.
+func envReference(name, shell string, quoted bool) string {
+	if shell == "pwsh" {
+		return "${env:" + name + "}"
+	}
+	if quoted {
+		return `'"${` + name + `}"'`
+	}
+	return "${" + name + "}"
+}
+
+// singleQuoted reports whether offset pos of a shell script is inside
+// single quotes
+func singleQuoted(script string, pos int) bool {
+	var single, double, escaped, comment bool
+	for i := 0; i < pos; i++ {
+		c := script[i]
+		switch {
+		case comment:
+			comment = c != '\n'
+		case escaped:
+			escaped = false
+		case single:
+			single = c != '\''
+		case c == '\\':
+			escaped = true
+		case c == '"':
+			double = !double
+		case c == '\'' && !double:
+			single = true
+		case c == '#' && !double && (i == 0 || strings.ContainsRune(" \t\n;", rune(script[i-1]))):
+			comment = true
+		}
+	}
+	return single
+}
+
+// envKeys returns the names an env mapping already sets
+func envKeys(env *yaml.Node) map[string]bool {
+	keys := map[string]bool{}
+	for _, p := range pairs(env) {
+		keys[p.key] = true
+	}
+	return keys
+}
@@ -0,0 +1,339 @@
+package examples
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bonzonkim/gopher-script/internal/parser"
+	"github.com/bonzonkim/gopher-script/internal/taskfile"
+)
+
+// DefaultLimit is the number of examples included in a prompt by default
+const DefaultLimit = 3
+
+//go:embed library
+var libraryFS embed.FS
+
+// Example is a script and its idiomatic Go conversion
+type Example struct {
+	Name       string
+	ScriptType parser.ScriptType
+	Script     string
+	GoCode     string
+
+	constructs map[string]bool
+}
+
+// Library is a set of examples to choose few-shot prompts from
+type Library struct {
+	examples map[string]*Example
+}
+
+// construct is a language idiom detected in scripts
+type construct struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+var constructs = map[parser.ScriptType][]construct{
+	parser.ScriptTypePython: {
+		{"argparse", regexp.MustCompile(`\bargparse\b|\bsys\.argv\b|\boptparse\b`)},
+		{"subprocess", regexp.MustCompile(`\bsubprocess\.|\bos\.system\(|\bos\.popen\(`)},
+		{"file-walk", regexp.MustCompile(`\bos\.walk\(|\bglob\.|\.rglob\(|\bos\.listdir\(|\bos\.scandir\(`)},
+		{"file-copy", regexp.MustCompile(`\bshutil\.|\bos\.makedirs\(|\bos\.remove\(`)},
+		{"json", regexp.MustCompile(`\bjson\.`)},
+		{"http", regexp.MustCompile(`\brequests\.|\burllib\b|\bhttp\.client\b`)},
+		{"logging", regexp.MustCompile(`\blogging\.`)},
+		{"regex", regexp.MustCompile(`\bre\.(?:compile|match|search|sub|findall|finditer|split)\(`)},
+		{"env", regexp.MustCompile(`\bos\.environ\b|\bos\.getenv\(`)},
+		{"file-read", regexp.MustCompile(`\bopen\(`)},
+		{"exit-code", regexp.MustCompile(`\bsys\.exit\(|\breturncode\b`)},
+	},
+	parser.ScriptTypeShell: {
+		{"command-substitution", regexp.MustCompile("\\$\\(|`")},
+		{"trap", regexp.MustCompile(`\btrap\b`)},
+		{"temp-files", regexp.MustCompile(`\bmktemp\b`)},
+		{"find", regexp.MustCompile(`\bfind\s`)},
+		{"xargs", regexp.MustCompile(`\|\s*xargs\b|-exec\s`)},
+		{"getopts", regexp.MustCompile(`\bgetopts\b|\$OPTARG\b|\bshift\b`)},
+		{"args", regexp.MustCompile(`\$\{?[1-9]\b|\$#|"\$@"`)},
+		{"read-loop", regexp.MustCompile(`\bwhile\s+(?:IFS=\S*\s+)?read\b`)},
+		{"http", regexp.MustCompile(`\bcurl\s|\bwget\s`)},
+		{"json", regexp.MustCompile(`\bjq\s`)},
+		{"default-values", regexp.MustCompile(`\$\{\w+:[-=?]`)},
+		{"arithmetic", regexp.MustCompile(`\$\(\(`)},
+		{"errexit", regexp.MustCompile(`\bset\s+-\w*e|\bpipefail\b`)},
+		{"usage", regexp.MustCompile(`\busage\s*\(\)|>&2`)},
+		{"task-runner", regexp.MustCompile(`\bmake_target\b|\brun_recipe\b`)},
+	},
+	parser.ScriptTypePerl: {
+		{"args", regexp.MustCompile(`@ARGV|\$ARGV\[|\bGetopt::(?:Long|Std)\b`)},
+		{"file-read", regexp.MustCompile(`\bopen\s*\(?\s*(?:my\s+)?\$\w+\s*,\s*['"]<|<\$\w+>|<STDIN>|while\s*\(\s*<>`)},
+		{"file-write", regexp.MustCompile(`\bopen\s*\(?\s*(?:my\s+)?\$\w+\s*,\s*['"]>>?`)},
+		{"regex", regexp.MustCompile(`=~\s*(?:m|s|tr)?[/{!|#]|\bqr/`)},
+		{"hash-count", regexp.MustCompile(`\$\w+\{[^}]+\}\s*(?:\+\+|\+=)`)},
+		{"sort", regexp.MustCompile(`\bsort\s*(?:\{|keys\b)`)},
+		{"die", regexp.MustCompile(`\bor\s+die\b|\bdie\s`)},
+		{"subprocess", regexp.MustCompile("\\bsystem\\s*\\(|`|\\bqx[/{(]")},
+		{"file-walk", regexp.MustCompile(`\bFile::Find\b|\bglob\s*\(|\bopendir\b`)},
+		{"env", regexp.MustCompile(`\$ENV\{`)},
+	},
+	parser.ScriptTypeRuby: {
+		{"args", regexp.MustCompile(`\bARGV\b|\bOptionParser\b`)},
+		{"subprocess", regexp.MustCompile("`|%x[({]|\\bsystem\\b|\\bOpen3\\.|\\$\\?")},
+		{"file-read", regexp.MustCompile(`\bFile\.(?:read|readlines|foreach|open)\b|\bIO\.readlines\b`)},
+		{"file-write", regexp.MustCompile(`\bFile\.write\b|\bFileUtils\.`)},
+		{"file-walk", regexp.MustCompile(`\bDir\.(?:glob|each_child|children)\b|\bDir\[|\bFind\.find\b`)},
+		{"json", regexp.MustCompile(`\bJSON\.|require ['"]json['"]`)},
+		{"http", regexp.MustCompile(`\bNet::HTTP\b|\bURI\.open\b|\bHTTParty\b|\bFaraday\b`)},
+		{"blocks", regexp.MustCompile(`\.(?:each|map|select|reject|each_with_index|inject)\s*(?:do\b|\{)`)},
+		{"exit-code", regexp.MustCompile(`\babort\b|\bexit!?\b|\braise\b`)},
+		{"env", regexp.MustCompile(`\bENV\[|\bENV\.fetch\b`)},
+		{"rake-task", regexp.MustCompile(`(?m)^\s*task\s+:\w+|^\s*namespace\s+:\w+`)},
+	},
+	parser.ScriptTypeNode: {
+		{"args", regexp.MustCompile(`\bprocess\.argv\b|\byargs\b|\bcommander\b|\bparseArgs\b`)},
+		{"async", regexp.MustCompile(`\basync\b|\bawait\b|\.then\s*\(`)},
+		{"concurrency", regexp.MustCompile(`\bPromise\.(?:all|allSettled|race|any)\b`)},
+		{"http", regexp.MustCompile(`\bfetch\s*\(|\baxios\b|\bhttps?\.(?:get|request)\b`)},
+		{"subprocess", regexp.MustCompile(`\bchild_process\b|\b(?:exec|execSync|spawn|spawnSync|execFile)\s*\(`)},
+		{"file-read", regexp.MustCompile(`\breadFile(?:Sync)?\s*\(|\bcreateReadStream\b|\bexistsSync\b`)},
+		{"file-write", regexp.MustCompile(`\bwriteFile(?:Sync)?\s*\(|\bappendFile(?:Sync)?\s*\(|\bmkdir(?:Sync)?\s*\(`)},
+		{"json", regexp.MustCompile(`\bJSON\.(?:parse|stringify)\b`)},
+		{"env", regexp.MustCompile(`\bprocess\.env\b`)},
+		{"exit-code", regexp.MustCompile(`\bprocess\.exit(?:Code)?\b`)},
+		{"try-catch", regexp.MustCompile(`\bcatch\s*[({]`)},
+	},
+	parser.ScriptTypePwsh: {
+		{"args", regexp.MustCompile(`(?i)\bparam\s*\(|\[Parameter\(|\$args\b`)},
+		{"validation", regexp.MustCompile(`(?i)\[Validate(?:Set|Range|Pattern|NotNullOrEmpty)\(`)},
+		{"pipeline", regexp.MustCompile(`(?i)\|\s*(?:Where-Object|ForEach-Object|Select-Object|Sort-Object|Group-Object|Measure-Object|\?|%)\b`)},
+		{"file-walk", regexp.MustCompile(`(?i)\b(?:Get-ChildItem|gci|dir|ls)\b.*-Recurse\b`)},
+		{"file-read", regexp.MustCompile(`(?i)\b(?:Get-Content|Import-Csv|Test-Path)\b`)},
+		{"file-write", regexp.MustCompile(`(?i)\b(?:Set-Content|Add-Content|Out-File|Export-Csv|Remove-Item|New-Item|Copy-Item|Move-Item)\b`)},
+		{"http", regexp.MustCompile(`(?i)\b(?:Invoke-RestMethod|Invoke-WebRequest|irm|iwr)\b`)},
+		{"json", regexp.MustCompile(`(?i)\b(?:ConvertTo-Json|ConvertFrom-Json)\b`)},
+		{"error-action", regexp.MustCompile(`(?i)\$ErrorActionPreference\b|-ErrorAction\b`)},
+		{"try-catch", regexp.MustCompile(`(?i)\bcatch\s*(?:\[|\{)`)},
+		{"output", regexp.MustCompile(`(?i)\b(?:Format-Table|Format-List|Write-Host|Write-Warning)\b`)},
+		{"exit-code", regexp.MustCompile(`(?i)\bexit\s+\d|\$LASTEXITCODE\b`)},
+	},
+	parser.ScriptTypeAwk: {
+		{"field-split", regexp.MustCompile(`\bFS\s*=|-F\S|\$[1-9]\b|\$NF\b`)},
+		{"begin-end", regexp.MustCompile(`\b(?:BEGIN|END)\s*\{`)},
+		{"arrays", regexp.MustCompile(`\w+\[[^\]]+\]\s*(?:\+\+|\+=|=)|\bfor\s*\(\s*\w+\s+in\b|\bin\s+\w+\s*\)`)},
+		{"regex-rule", regexp.MustCompile(`(?m)^\s*/[^/]+/|~\s*/`)},
+		{"printf", regexp.MustCompile(`\bprintf\b`)},
+		{"variables", regexp.MustCompile(`-v\s+\w+=|\bsplit\s*\(`)},
+		{"next", regexp.MustCompile(`\bnext\b|\bNR\s*==`)},
+		{"redirect", regexp.MustCompile(`>\s*"/dev/stderr"|\|\s*"|\bclose\s*\(`)},
+		{"exit-code", regexp.MustCompile(`\bexit\s+\d`)},
+	},
+	parser.ScriptTypeSed: {
+		{"substitute", regexp.MustCompile(`(?m)(?:^|[;{}\s])s[/|#:,@!]`)},
+		{"range", regexp.MustCompile(`(?m)^\s*(?:/[^/]*/|\d+|\$)\s*,\s*(?:/[^/]*/|\d+|\$)`)},
+		{"groups", regexp.MustCompile(`\\\(|\\1`)},
+		{"delete", regexp.MustCompile(`(?m)/\s*d\s*$|^\s*\d*d\s*$`)},
+		{"print", regexp.MustCompile(`(?m)^#n|\bp\s*$|-n`)},
+		{"multiline", regexp.MustCompile(`(?m)^\s*[NDPGH]\s*$|\\n`)},
+		{"branch", regexp.MustCompile(`(?m)^\s*:\w+|\b[btT]\s+\w+`)},
+		{"line-number", regexp.MustCompile(`(?m)^\s*=\s*$`)},
+	},
+}
+
+// Default returns the built-in example library
+func Default() *Library {
+	l := &Library{examples: make(map[string]*Example)}
+	sub, err := fs.Sub(libraryFS, "library")
+	if err != nil {
+		panic(fmt.Sprintf("missing built-in example library: %v", err))
+	}
+	if err := l.load(sub); err != nil {
+		panic(fmt.Sprintf("invalid built-in example library: %v", err))
+	}
+	return l
+}
+
+// LoadDir adds the example pairs in dir, replacing built-in examples with
+// the same name. A pair is a script such as "name.py" or "name.sh" next to
+// "name.go" or "name.go.txt". A missing directory is not an error.
+func (l *Library) LoadDir(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to stat examples directory: %s: %w", dir, err)
+	}
+
+	if err := l.load(os.DirFS(dir)); err != nil {
+		return fmt.Errorf("failed to load examples: %s: %w", dir, err)
+	}
+	return nil
+}
+
+func (l *Library) load(fsys fs.FS) error {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || strings.HasSuffix(name, ".go") || strings.HasSuffix(name, ".go.txt") {
+			continue
+		}
+
+		scriptType := scriptTypeOf(name)
+		if scriptType == parser.ScriptTypeUnknown {
+			continue
+		}
+
+		base := strings.TrimSuffix(name, path.Ext(name))
+		goCode, err := readFirst(fsys, base+".go", base+".go.txt")
+		if err != nil {
+			return fmt.Errorf("example %s has no Go counterpart (%s.go or %s.go.txt)", name, base, base)
+		}
+
+		script, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+
+		// Task files are shown as the script they are converted through
+		content := string(script)
+		if kind := taskfile.Detect(name); kind != "" {
+			tasks, err := taskfile.Parse(kind, content)
+			if err != nil {
+				return fmt.Errorf("example %s: %w", name, err)
+			}
+			content = tasks.Script(name)
+		}
+
+		l.Add(&Example{
+			Name:       base,
+			ScriptType: scriptType,
+			Script:     content,
+			GoCode:     goCode,
+		})
+	}
+	return nil
+}
+
+// Add adds an example, replacing any example with the same name
+func (l *Library) Add(e *Example) {
+	e.constructs = make(map[string]bool)
+	for _, c := range Constructs(e.ScriptType, e.Script) {
+		e.constructs[c] = true
+	}
+	l.examples[e.Name] = e
+}
+
+// Len returns the number of examples in the library
+func (l *Library) Len() int {
+	return len(l.examples)
+}
+
+// Select returns up to limit examples of the script's type that share the
+// most constructs with it. Examples sharing no construct are never chosen.
+func (l *Library) Select(scriptType parser.ScriptType, content string, limit int) []*Example {
+	if l == nil || limit <= 0 {
+		return nil
+	}
+
+	found := Constructs(scriptType, content)
+
+	type candidate struct {
+		example *Example
+		score   int
+	}
+	var candidates []candidate
+	for _, e := range l.examples {
+		if e.ScriptType != scriptType {
+			continue
+		}
+
+		score := 0
+		for _, c := range found {
+			if e.constructs[c] {
+				score++
+			}
+		}
+		if score > 0 {
+			candidates = append(candidates, candidate{e, score})
+		}
+	}
+
+	// Prefer more shared constructs, then more focused examples
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if a.score != b.score {
+			return a.score > b.score
+		}
+		if len(a.example.constructs) != len(b.example.constructs) {
+			return len(a.example.constructs) < len(b.example.constructs)
+		}
+		return a.example.Name < b.example.Name
+	})
+
+	if len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	selected := make([]*Example, len(candidates))
+	for i, c := range candidates {
+		selected[i] = c.example
+	}
+	return selected
+}
+
+// Constructs returns the names of the idioms used in a script
+func Constructs(scriptType parser.ScriptType, content string) []string {
+	var found []string
+	for _, c := range constructs[scriptType] {
+		if c.pattern.MatchString(content) {
+			found = append(found, c.name)
+		}
+	}
+	return found
+}
+
+// scriptTypeOf determines the script type of an example from its extension
+func scriptTypeOf(name string) parser.ScriptType {
+	switch path.Ext(name) {
+	case ".py":
+		return parser.ScriptTypePython
+	case ".sh", ".bash", ".zsh", ".ksh", ".mk", ".just":
+		return parser.ScriptTypeShell
+	case ".pl", ".pm":
+		return parser.ScriptTypePerl
+	case ".rb", ".rake":
+		return parser.ScriptTypeRuby
+	case ".js", ".mjs", ".cjs", ".ts", ".mts", ".cts":
+		return parser.ScriptTypeNode
+	case ".ps1":
+		return parser.ScriptTypePwsh
+	case ".awk":
+		return parser.ScriptTypeAwk
+	case ".sed":
+		return parser.ScriptTypeSed
+	}
+	return parser.ScriptTypeUnknown
+}
+
+// readFirst returns the contents of the first of names that exists
+func readFirst(fsys fs.FS, names ...string) (string, error) {
+	var err error
+	for _, name := range names {
+		var data []byte
+		if data, err = fs.ReadFile(fsys, name); err == nil {
+			return string(data), nil
+		}
+	}
+	return "", err
+}
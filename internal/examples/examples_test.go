@@ -0,0 +1,114 @@
+package examples
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bonzonkim/gopher-script/internal/parser"
+)
+
+func TestDefault(t *testing.T) {
+	l := Default()
+	if l.Len() < 10 {
+		t.Fatalf("Default() has %d examples, want at least 10", l.Len())
+	}
+
+	for _, e := range l.examples {
+		if e.GoCode == "" || e.Script == "" {
+			t.Errorf("example %s is incomplete", e.Name)
+		}
+		if len(e.constructs) == 0 {
+			t.Errorf("example %s uses no known construct and can never be selected", e.Name)
+		}
+	}
+}
+
+func TestSelect(t *testing.T) {
+	l := Default()
+
+	tests := []struct {
+		name       string
+		scriptType parser.ScriptType
+		content    string
+		want       string
+	}{
+		{"argparse", parser.ScriptTypePython, "import argparse\np = argparse.ArgumentParser()\n", "argparse_cli"},
+		{"subprocess", parser.ScriptTypePython, "import subprocess\nr = subprocess.run(['ls'])\nprint(r.returncode)\n", "subprocess_run"},
+		{"trap", parser.ScriptTypeShell, "tmp=$(mktemp)\ntrap 'rm -f $tmp' EXIT\n", "trap_cleanup"},
+		{"find xargs", parser.ScriptTypeShell, "find . -name '*.bak' | xargs rm\n", "find_xargs"},
+		{"read loop", parser.ScriptTypeShell, "while IFS=: read -r a b; do echo $a; done < /etc/passwd\n", "read_loop"},
+		{"perl regex", parser.ScriptTypePerl, "open(my $in, '<', $f) or die;\nwhile (<$in>) { $n{$1}++ if /id=(\\d+)/ }\n", "regex_report"},
+		{"ruby optparse", parser.ScriptTypeRuby, "require 'optparse'\nOptionParser.new { |o| }.parse!\nout = `uname`\n", "optparse_deploy"},
+		{"ruby glob", parser.ScriptTypeRuby, "Dir.glob('*.json').each do |f|\n  JSON.parse(File.read(f))\nend\n", "dir_glob_json"},
+		{"node fetch", parser.ScriptTypeNode, "const res = await Promise.all(urls.map((u) => fetch(u)));\n", "fetch_status"},
+		{"powershell param", parser.ScriptTypePwsh, "param([Parameter(Mandatory)][string]$Path)\nGet-ChildItem $Path -Recurse | Where-Object { $_.Length -gt 0 } | Remove-Item\n", "param_cleanup"},
+		{"powershell rest", parser.ScriptTypePwsh, "try { $r = Invoke-RestMethod $url } catch { Write-Warning 'x' }\n$r | ConvertTo-Json\n", "rest_health"},
+		{"awk totals", parser.ScriptTypeAwk, "{ count[$1]++ }\nEND { for (k in count) printf \"%s %d\\n\", k, count[k] }\n", "status_totals"},
+		{"awk columns", parser.ScriptTypeAwk, "BEGIN { FS = \",\"; n = split(cols, w, \",\") }\nNR == 1 { next }\n{ print $1 }\n", "csv_columns"},
+		{"sed section", parser.ScriptTypeSed, "/^\\[db\\]/,/^\\[/{\n  s/^\\(\\w*\\)=/DB_\\1=/\n  p\n}\n", "ini_section"},
+		{"sed join", parser.ScriptTypeSed, ":a\n/,$/{\n  N\n  s/,\\n/, /\n  b a\n}\n=\n", "join_continuations"},
+		{"node child_process", parser.ScriptTypeNode, "const { execSync } = require('child_process');\nexecSync('git status');\nprocess.exit(1);\n", "git_changed"},
+		{"make targets", parser.ScriptTypeShell, "make_target() {\n\tcase $1 in\n\tbuild) target_build ;;\n\tesac\n}\nfor _goal in \"${_goals[@]}\"; do make_target \"$_goal\"; done\n", "release_tasks"},
+		{"perl getopt", parser.ScriptTypePerl, "use Getopt::Long;\nuse File::Find;\nGetOptions('v' => \\$v);\n", "getopt_find"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selected := l.Select(tt.scriptType, tt.content, DefaultLimit)
+			if len(selected) == 0 {
+				t.Fatal("Select() returned no examples")
+			}
+			if selected[0].Name != tt.want {
+				t.Errorf("Select()[0] = %s, want %s", selected[0].Name, tt.want)
+			}
+			for _, e := range selected {
+				if e.ScriptType != tt.scriptType {
+					t.Errorf("Select() returned %s example %s", e.ScriptType, e.Name)
+				}
+			}
+		})
+	}
+}
+
+func TestSelect_NoMatch(t *testing.T) {
+	if selected := Default().Select(parser.ScriptTypePython, "print(1 + 1)\n", DefaultLimit); len(selected) != 0 {
+		t.Errorf("Select() = %d examples, want none", len(selected))
+	}
+	if selected := Default().Select(parser.ScriptTypeShell, "trap 'x' EXIT\n", 0); len(selected) != 0 {
+		t.Errorf("Select() with limit 0 = %d examples, want none", len(selected))
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "house_logging.py"), []byte("import logging\nlogging.info('x')\n"), 0644)
+	os.WriteFile(filepath.Join(dir, "house_logging.go"), []byte("package main\n\nimport \"log/slog\"\n\nfunc main() { slog.Info(\"x\") }\n"), 0644)
+
+	l := Default()
+	before := l.Len()
+	if err := l.LoadDir(dir); err != nil {
+		t.Fatalf("LoadDir() error = %v", err)
+	}
+	if l.Len() != before+1 {
+		t.Errorf("Len() = %d, want %d", l.Len(), before+1)
+	}
+
+	selected := l.Select(parser.ScriptTypePython, "import logging\nlogging.warning('y')\n", 1)
+	if len(selected) != 1 || selected[0].Name != "house_logging" {
+		t.Errorf("Select() did not prefer the user example: %v", selected)
+	}
+
+	if err := l.LoadDir(filepath.Join(dir, "missing")); err != nil {
+		t.Errorf("LoadDir() on a missing directory error = %v", err)
+	}
+}
+
+func TestLoadDir_MissingGoCode(t *testing.T) {
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "orphan.sh"), []byte("trap 'x' EXIT\n"), 0644)
+
+	if err := Default().LoadDir(dir); err == nil {
+		t.Error("LoadDir() error = nil, want error for a script without Go code")
+	}
+}
@@ -0,0 +1,22 @@
+import argparse
+import sys
+
+
+def main():
+    parser = argparse.ArgumentParser(description="Greet users")
+    parser.add_argument("name", help="name to greet")
+    parser.add_argument("-n", "--count", type=int, default=1, help="number of greetings")
+    parser.add_argument("-v", "--verbose", action="store_true")
+    args = parser.parse_args()
+
+    if args.count < 1:
+        parser.error("--count must be positive")
+
+    for _ in range(args.count):
+        print(f"Hello, {args.name}!")
+    if args.verbose:
+        print("done", file=sys.stderr)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,8 @@
+#!/bin/bash
+set -euo pipefail
+
+branch=$(git rev-parse --abbrev-ref HEAD)
+count=$(git log --oneline | wc -l)
+today=$(date +%Y-%m-%d)
+
+echo "Branch $branch has $count commits as of $today"
@@ -0,0 +1,27 @@
+#!/usr/bin/awk -f
+# Print selected columns of a CSV file with a header, e.g.
+#   csv_columns.awk -v cols=name,email users.csv
+BEGIN {
+    FS = ","
+    OFS = "\t"
+    n = split(cols, wanted, ",")
+}
+
+NR == 1 {
+    for (i = 1; i <= NF; i++)
+        index_of[$i] = i
+    for (j = 1; j <= n; j++) {
+        if (!(wanted[j] in index_of)) {
+            printf "unknown column: %s\n", wanted[j] > "/dev/stderr"
+            exit 2
+        }
+    }
+    next
+}
+
+NF > 0 {
+    line = ""
+    for (j = 1; j <= n; j++)
+        line = line (j > 1 ? OFS : "") $(index_of[wanted[j]])
+    print line
+}
@@ -0,0 +1,8 @@
+#!/bin/bash
+set -euo pipefail
+
+: "${GITHUB_TOKEN:?GITHUB_TOKEN must be set}"
+
+curl -fsS -H "Authorization: token $GITHUB_TOKEN" \
+    "https://api.github.com/repos/$1/releases/latest" |
+    jq -r '.assets[] | "\(.name) \(.download_count)"'
@@ -0,0 +1,14 @@
+require 'json'
+require 'fileutils'
+
+src = ARGV[0] || '.'
+out = File.join(src, 'build')
+FileUtils.mkdir_p(out)
+
+manifest = Dir.glob(File.join(src, '**', '*.json')).sort.map do |path|
+  data = JSON.parse(File.read(path))
+  { 'file' => File.basename(path), 'name' => data.fetch('name', 'unknown') }
+end
+
+File.write(File.join(out, 'manifest.json'), JSON.pretty_generate(manifest))
+puts "wrote #{manifest.size} entries"
@@ -0,0 +1,27 @@
+#!/usr/bin/env node
+import { writeFile } from 'node:fs/promises';
+
+const urls = process.argv.slice(2);
+if (urls.length === 0) {
+  console.error('usage: fetch_status.mjs URL...');
+  process.exit(2);
+}
+
+const timeout = Number(process.env.TIMEOUT_MS ?? 5000);
+
+async function check(url) {
+  try {
+    const res = await fetch(url, { signal: AbortSignal.timeout(timeout) });
+    const body = await res.json();
+    return { url, status: res.status, version: body.version ?? null };
+  } catch (err) {
+    return { url, status: 0, error: err.message };
+  }
+}
+
+const results = await Promise.all(urls.map(check));
+await writeFile('status.json', JSON.stringify(results, null, 2));
+
+const failed = results.filter((r) => r.status !== 200);
+console.log(`${results.length - failed.length}/${results.length} healthy`);
+if (failed.length > 0) process.exit(1);
@@ -0,0 +1,6 @@
+#!/bin/sh
+set -e
+
+find "${1:-.}" -type f -name '*.tmp' -mtime +7 -print0 | xargs -0 rm -f
+find "${1:-.}" -type f -name '*.log' -exec gzip {} \;
+echo "cleanup complete"
@@ -0,0 +1,21 @@
+#!/usr/bin/perl
+use strict;
+use warnings;
+use File::Find;
+use Getopt::Long;
+
+my $days = 30;
+my $dry_run = 0;
+GetOptions('days=i' => \$days, 'dry-run' => \$dry_run)
+    or die "usage: $0 [--days N] [--dry-run] DIR...\n";
+@ARGV or die "usage: $0 [--days N] [--dry-run] DIR...\n";
+
+find(sub {
+    return unless -f $_ && /\.log$/;
+    return unless -M $_ > $days;
+    if ($dry_run) {
+        print "would remove $File::Find::name\n";
+    } else {
+        unlink $_ or warn "cannot remove $File::Find::name: $!\n";
+    }
+}, @ARGV);
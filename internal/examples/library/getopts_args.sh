@@ -0,0 +1,26 @@
+#!/bin/sh
+
+usage() {
+    echo "usage: $0 [-f] [-o output] file..." >&2
+    exit 2
+}
+
+force=0
+output=out.txt
+while getopts "fo:" opt; do
+    case "$opt" in
+        f) force=1 ;;
+        o) output="$OPTARG" ;;
+        *) usage ;;
+    esac
+done
+shift $((OPTIND - 1))
+
+[ $# -gt 0 ] || usage
+
+if [ -e "$output" ] && [ "$force" -ne 1 ]; then
+    echo "$output exists, use -f to overwrite" >&2
+    exit 1
+fi
+
+cat "$@" > "$output"
@@ -0,0 +1,31 @@
+#!/usr/bin/env node
+'use strict';
+
+const { execSync, spawnSync } = require('child_process');
+const fs = require('fs');
+const path = require('path');
+
+const base = process.argv[2] || process.env.BASE_REF || 'origin/main';
+
+let changed;
+try {
+  changed = execSync(`git diff --name-only ${base}...HEAD`, { encoding: 'utf8' })
+    .split('\n')
+    .filter((f) => f.endsWith('.json') && fs.existsSync(f));
+} catch (err) {
+  console.error(`git diff failed: ${err.message}`);
+  process.exit(1);
+}
+
+let invalid = 0;
+for (const file of changed) {
+  try {
+    JSON.parse(fs.readFileSync(file, 'utf8'));
+  } catch (err) {
+    console.error(`${path.basename(file)}: ${err.message}`);
+    invalid++;
+  }
+}
+
+const lint = spawnSync('npx', ['prettier', '--check', ...changed], { stdio: 'inherit' });
+process.exit(invalid > 0 ? 1 : lint.status ?? 1);
@@ -0,0 +1,22 @@
+import json
+import os
+import sys
+import urllib.request
+
+token = os.environ.get("API_TOKEN", "")
+req = urllib.request.Request(
+    "https://api.example.com/v1/items",
+    headers={"Authorization": f"Bearer {token}"},
+)
+try:
+    with urllib.request.urlopen(req, timeout=10) as resp:
+        items = json.load(resp)
+except Exception as e:
+    print(f"request failed: {e}", file=sys.stderr)
+    sys.exit(1)
+
+for item in items:
+    print(item["id"], item["name"])
+
+with open("items.json", "w") as f:
+    json.dump(items, f, indent=2)
@@ -0,0 +1,17 @@
+import logging
+import re
+import sys
+
+logging.basicConfig(level=logging.INFO, format="%(asctime)s %(levelname)s %(message)s")
+log = logging.getLogger(__name__)
+
+pattern = re.compile(r"ERROR \[(\w+)\] (.*)")
+counts = {}
+with open(sys.argv[1]) as f:
+    for line in f:
+        m = pattern.search(line)
+        if m:
+            counts[m.group(1)] = counts.get(m.group(1), 0) + 1
+
+for component, n in sorted(counts.items()):
+    log.info("%s: %d errors", component, n)
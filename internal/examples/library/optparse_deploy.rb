@@ -0,0 +1,21 @@
+#!/usr/bin/env ruby
+require 'optparse'
+
+options = { env: 'staging', dry_run: false }
+OptionParser.new do |opts|
+  opts.banner = "Usage: #{$0} [options] SERVICE"
+  opts.on('-e', '--env ENV', 'Target environment') { |v| options[:env] = v }
+  opts.on('-n', '--dry-run', 'Print commands only') { options[:dry_run] = true }
+end.parse!
+
+service = ARGV.shift or abort("Usage: #{$0} [options] SERVICE")
+
+sha = `git rev-parse --short HEAD`.strip
+abort('git rev-parse failed') unless $?.success?
+
+cmd = "kubectl -n #{options[:env]} set image deploy/#{service} app=registry/#{service}:#{sha}"
+if options[:dry_run]
+  puts cmd
+else
+  system(cmd) or abort("deploy failed with status #{$?.exitstatus}")
+end
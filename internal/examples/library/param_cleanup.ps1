@@ -0,0 +1,30 @@
+#!/usr/bin/env pwsh
+param(
+    [Parameter(Mandatory)]
+    [string]$Path,
+    [int]$Days = 30,
+    [switch]$WhatIf
+)
+
+$ErrorActionPreference = 'Stop'
+
+if (-not (Test-Path $Path)) {
+    Write-Error "Path not found: $Path"
+    exit 2
+}
+
+$cutoff = (Get-Date).AddDays(-$Days)
+$old = Get-ChildItem -Path $Path -Recurse -File -Filter *.log |
+    Where-Object { $_.LastWriteTime -lt $cutoff } |
+    Sort-Object LastWriteTime
+
+foreach ($file in $old) {
+    if ($WhatIf) {
+        Write-Host "Would remove $($file.FullName)"
+    } else {
+        Remove-Item $file.FullName -Force
+    }
+}
+
+$size = ($old | Measure-Object -Property Length -Sum).Sum
+Write-Output ("{0} files, {1:N1} MB" -f $old.Count, ($size / 1MB))
@@ -0,0 +1,10 @@
+#!/bin/bash
+
+total=0
+while IFS=, read -r user quota; do
+    [[ "$user" == \#* ]] && continue
+    echo "setting quota for $user to ${quota:-100}"
+    total=$((total + ${quota:-100}))
+done < quotas.csv
+
+echo "total quota: $total"
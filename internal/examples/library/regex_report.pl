@@ -0,0 +1,18 @@
+#!/usr/bin/perl
+use strict;
+use warnings;
+
+my $file = shift @ARGV or die "usage: $0 FILE\n";
+open(my $fh, '<', $file) or die "cannot open $file: $!\n";
+
+my %count;
+while (my $line = <$fh>) {
+    chomp $line;
+    next unless $line =~ /^(\S+) .* "(?:GET|POST) (\S+)/;
+    $count{$2}++;
+}
+close($fh);
+
+for my $path (sort { $count{$b} <=> $count{$a} || $a cmp $b } keys %count) {
+    printf "%6d %s\n", $count{$path}, $path;
+}
@@ -0,0 +1,21 @@
+# Release tasks for a Go service
+BIN ?= bin/server
+VERSION := $(shell git describe --tags --always)
+LDFLAGS = -s -w -X main.version=$(VERSION)
+
+.PHONY: all build test clean
+
+all: build
+
+## Run the unit tests
+test:
+	go test ./...
+
+## Build the server binary
+build: test
+	@mkdir -p bin
+	go build -ldflags "$(LDFLAGS)" -o $(BIN) ./cmd/server
+
+## Remove build output
+clean:
+	-rm -rf bin
@@ -0,0 +1,25 @@
+param(
+    [string[]]$Endpoints = @('https://status.example.com/api/health'),
+    [ValidateSet('json', 'table')]
+    [string]$Format = 'table'
+)
+
+$results = foreach ($url in $Endpoints) {
+    try {
+        $res = Invoke-RestMethod -Uri $url -TimeoutSec 10
+        [pscustomobject]@{ Endpoint = $url; Status = $res.status; Healthy = $res.status -eq 'ok' }
+    } catch {
+        Write-Warning "$url failed: $($_.Exception.Message)"
+        [pscustomobject]@{ Endpoint = $url; Status = 'error'; Healthy = $false }
+    }
+}
+
+if ($Format -eq 'json') {
+    $results | ConvertTo-Json
+} else {
+    $results | Format-Table -AutoSize
+}
+
+if ($results | Where-Object { -not $_.Healthy }) {
+    exit 1
+}
@@ -0,0 +1,20 @@
+#!/usr/bin/awk -f
+# Sum response bytes per HTTP status in a common log format access log
+BEGIN { FS = " " }
+
+$9 ~ /^[0-9]+$/ {
+    count[$9]++
+    bytes[$9] += ($10 == "-" ? 0 : $10)
+    total++
+}
+
+END {
+    if (total == 0) {
+        print "no requests" > "/dev/stderr"
+        exit 1
+    }
+    for (status in count)
+        printf "%s %d %.1f%% %d\n", status, count[status], 100 * count[status] / total, bytes[status] | "sort"
+    close("sort")
+    printf "total %d\n", total
+}
@@ -0,0 +1,11 @@
+import subprocess
+import sys
+
+result = subprocess.run(["git", "rev-parse", "HEAD"], capture_output=True, text=True, check=True)
+commit = result.stdout.strip()
+print(f"commit: {commit}")
+
+status = subprocess.run(["make", "test"])
+if status.returncode != 0:
+    print("tests failed", file=sys.stderr)
+    sys.exit(status.returncode)
@@ -0,0 +1,16 @@
+import os
+import shutil
+import sys
+
+src = sys.argv[1]
+dst = sys.argv[2]
+
+for root, dirs, files in os.walk(src):
+    for name in files:
+        if not name.endswith(".log"):
+            continue
+        path = os.path.join(root, name)
+        target = os.path.join(dst, os.path.relpath(path, src))
+        os.makedirs(os.path.dirname(target), exist_ok=True)
+        shutil.copy2(path, target)
+        print(f"copied {path}")
@@ -62,17 +62,24 @@ func (g *Generator) Generate(goCode string, outputPath string) (*GenerateResult,
 	}, nil
 }
 
-// Build compiles the Go code into a static binary
+// Build compiles the Go code into a static binary. The build runs in the
+// file's directory so that a go.mod written next to it is used.
 func (g *Generator) Build(goFilePath string, binaryPath string) error {
 	g.logger.Info("Building binary", zap.String("source", goFilePath), zap.String("output", binaryPath))
 
+	absBinaryPath, err := filepath.Abs(binaryPath)
+	if err != nil {
+		return fmt.Errorf("failed to resolve binary path: %w", err)
+	}
+
 	// Build with static linking flags
 	cmd := exec.Command("go", "build",
 		"-ldflags", "-s -w",
-		"-o", binaryPath,
-		goFilePath,
+		"-o", absBinaryPath,
+		filepath.Base(goFilePath),
 	)
 
+	cmd.Dir = filepath.Dir(goFilePath)
 	cmd.Env = append(os.Environ(), "CGO_ENABLED=0")
 
 	output, err := cmd.CombinedOutput()
@@ -84,6 +91,127 @@ func (g *Generator) Build(goFilePath string, binaryPath string) error {
 	return nil
 }
 
+// BuildTemp builds goCode as a module with the given go.mod in a temporary
+// directory. It returns the binary and a function that removes the directory.
+func (g *Generator) BuildTemp(goCode, goMod string) (string, func(), error) {
+	dir, err := os.MkdirTemp("", "gopherscript-build-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create build directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(dir) }
+
+	goFile := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(goFile, []byte(goCode), 0644); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write Go file: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(goMod), 0644); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to write go.mod: %w", err)
+	}
+
+	binary := filepath.Join(dir, "main")
+	if err := g.Build(goFile, binary); err != nil {
+		cleanup()
+		return "", nil, err
+	}
+	return binary, cleanup, nil
+}
+
+// ModuleHeader starts every go.mod gopherscript writes. Only a go.mod with
+// it is rewritten or tidied; any other belongs to the user.
+const ModuleHeader = "// Generated by gopherscript. Do not edit; it is rewritten on each conversion.\n\n"
+
+// ReadModule returns the go.mod gopherscript wrote in dir, without
+// ModuleHeader. It returns "" when dir has no go.mod or one that belongs to
+// the user, which WriteModule reports.
+func (g *Generator) ReadModule(dir string) (string, error) {
+	goModPath := filepath.Join(dir, "go.mod")
+
+	existing, err := os.ReadFile(goModPath)
+	switch {
+	case os.IsNotExist(err):
+		return "", nil
+	case err != nil:
+		return "", fmt.Errorf("failed to read %s: %w", goModPath, err)
+	}
+
+	content, ok := strings.CutPrefix(string(existing), ModuleHeader)
+	if !ok {
+		return "", nil
+	}
+	return content, nil
+}
+
+// WriteModule makes dir a module that requires the given modules
+// ("path@version"), writing go.mod from goMod. A go.mod gopherscript did not
+// create is left untouched and reported as an error, and so is a dir inside
+// another module, which a new go.mod would take out of that module. It
+// returns the go.mod path.
+func (g *Generator) WriteModule(dir, goMod string, requires []string) (string, error) {
+	goModPath := filepath.Join(dir, "go.mod")
+
+	existing, err := os.ReadFile(goModPath)
+	switch {
+	case err == nil && !strings.HasPrefix(string(existing), ModuleHeader):
+		return "", fmt.Errorf("%s was not created by gopherscript and is left as it is; write the Go file to another directory with -o, or add the requirements yourself (go get %s)",
+			goModPath, strings.Join(requires, " "))
+	case os.IsNotExist(err):
+		if enclosing := EnclosingModule(dir); enclosing != "" {
+			return "", fmt.Errorf("%s is inside the module of %s, and a go.mod there would take it out of that module; write the Go file outside the module with -o, or add the requirements to it (go get %s)",
+				dir, enclosing, strings.Join(requires, " "))
+		}
+	case err != nil:
+		return "", fmt.Errorf("failed to read %s: %w", goModPath, err)
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := os.WriteFile(goModPath, []byte(ModuleHeader+goMod), 0644); err != nil {
+		return "", fmt.Errorf("failed to write go.mod: %w", err)
+	}
+
+	g.logger.Info("Generated go.mod", zap.String("path", goModPath))
+	return goModPath, nil
+}
+
+// EnclosingModule returns the go.mod of the module dir would belong to
+// without its own go.mod, or "" when there is none
+func EnclosingModule(dir string) string {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+
+	for parent := filepath.Dir(abs); ; parent = filepath.Dir(parent) {
+		goModPath := filepath.Join(parent, "go.mod")
+		if info, err := os.Stat(goModPath); err == nil && !info.IsDir() {
+			return goModPath
+		}
+		if filepath.Dir(parent) == parent {
+			return ""
+		}
+	}
+}
+
+// Tidy resolves the module requirements in dir and writes go.sum. Only
+// call it on a module WriteModule wrote.
+func (g *Generator) Tidy(dir string) error {
+	cmd := exec.Command("go", "mod", "tidy")
+	cmd.Dir = dir
+
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("go mod tidy failed: %s: %w", string(output), err)
+	}
+	return nil
+}
+
+// CleanCode strips markdown code block markers from LLM output
+func (g *Generator) CleanCode(code string) string {
+	return g.cleanCodeBlock(code)
+}
+
 // cleanCodeBlock removes markdown code block markers from the code
 func (g *Generator) cleanCodeBlock(code string) string {
 	// Remove ```go or ``` at the beginning
@@ -111,6 +239,12 @@ func (g *Generator) GetDefaultOutputPath(inputPath string) string {
 	ext := filepath.Ext(base)
 	name := strings.TrimSuffix(base, ext)
 
+	// The binary built from name.go would replace an input without an
+	// extension, such as a Makefile
+	if ext == "" || name == "" {
+		name = strings.TrimPrefix(base, ".") + "-go"
+	}
+
 	return filepath.Join(dir, name+".go")
 }
 
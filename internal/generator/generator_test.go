@@ -118,6 +118,8 @@ func TestGenerator_GetDefaultOutputPath(t *testing.T) {
 		{"/path/to/script.py", "/path/to/script.go"},
 		{"/path/to/script.sh", "/path/to/script.go"},
 		{"./test.py", "test.go"},
+		{"/path/to/Makefile", "/path/to/Makefile-go.go"},
+		{"/path/to/.justfile", "/path/to/justfile-go.go"},
 	}
 
 	for _, tt := range tests {
@@ -147,3 +149,94 @@ func TestGenerator_GetDefaultBinaryPath(t *testing.T) {
 		}
 	}
 }
+
+func TestGenerator_WriteModule(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	g := NewGenerator(logger)
+
+	dir := t.TempDir()
+	goMod := "module example\n\ngo 1.22\n"
+	path, err := g.WriteModule(dir, goMod, []string{"example.com/a@v1.0.0"})
+	if err != nil {
+		t.Fatalf("WriteModule failed: %v", err)
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != ModuleHeader+goMod {
+		t.Errorf("go.mod = %q, want %q", data, ModuleHeader+goMod)
+	}
+
+	// A go.mod gopherscript wrote is rewritten
+	updated := "module example\n\ngo 1.23\n"
+	if _, err := g.WriteModule(dir, updated, nil); err != nil {
+		t.Fatalf("WriteModule over its own go.mod failed: %v", err)
+	}
+	data, _ = os.ReadFile(path)
+	if string(data) != ModuleHeader+updated {
+		t.Errorf("go.mod = %q, want %q", data, ModuleHeader+updated)
+	}
+}
+
+func TestGenerator_WriteModule_Foreign(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	g := NewGenerator(logger)
+
+	dir := t.TempDir()
+	foreign := "module github.com/user/project\n\ngo 1.22\n"
+	goModPath := filepath.Join(dir, "go.mod")
+	if err := os.WriteFile(goModPath, []byte(foreign), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := g.WriteModule(dir, "module example\n", []string{"example.com/a@v1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "not created by gopherscript") {
+		t.Fatalf("WriteModule error = %v, want a foreign go.mod error", err)
+	}
+	data, _ := os.ReadFile(goModPath)
+	if string(data) != foreign {
+		t.Errorf("foreign go.mod was modified: %q", data)
+	}
+}
+
+func TestGenerator_WriteModule_EnclosingModule(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	g := NewGenerator(logger)
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module github.com/user/project\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(root, "scripts")
+
+	_, err := g.WriteModule(dir, "module example\n", []string{"example.com/a@v1.0.0"})
+	if err == nil || !strings.Contains(err.Error(), "inside the module") {
+		t.Fatalf("WriteModule error = %v, want an enclosing module error", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go.mod")); !os.IsNotExist(err) {
+		t.Errorf("expected no go.mod inside the enclosing module, got %v", err)
+	}
+}
+
+func TestGenerator_ReadModule(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+	g := NewGenerator(logger)
+
+	dir := t.TempDir()
+	if content, err := g.ReadModule(dir); err != nil || content != "" {
+		t.Errorf("ReadModule() without a go.mod = %q, %v", content, err)
+	}
+
+	goMod := "module example\n"
+	if _, err := g.WriteModule(dir, goMod, nil); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := g.ReadModule(dir); err != nil || content != goMod {
+		t.Errorf("ReadModule() = %q, %v, want %q", content, err, goMod)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/user/project\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if content, err := g.ReadModule(dir); err != nil || content != "" {
+		t.Errorf("ReadModule() of a foreign go.mod = %q, %v", content, err)
+	}
+}
@@ -0,0 +1,273 @@
+package goversion
+
+import (
+	"bufio"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Issue is a use of a package or language feature that is newer than the
+// target Go version
+type Issue struct {
+	Line     int    `json:"line"`
+	Feature  string `json:"feature"`
+	Requires string `json:"requires"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("line %d: %s requires Go %s", i.Line, i.Feature, i.Requires)
+}
+
+// packages maps standard library packages to the Go version that added them
+var packages = map[string]string{
+	"embed":            "1.16",
+	"io/fs":            "1.16",
+	"net/netip":        "1.18",
+	"cmp":              "1.21",
+	"log/slog":         "1.21",
+	"maps":             "1.21",
+	"slices":           "1.21",
+	"testing/slogtest": "1.21",
+	"go/version":       "1.22",
+	"math/rand/v2":     "1.22",
+	"iter":             "1.23",
+	"structs":          "1.23",
+	"unique":           "1.23",
+	"crypto/hkdf":      "1.24",
+	"crypto/mlkem":     "1.24",
+	"crypto/pbkdf2":    "1.24",
+	"crypto/sha3":      "1.24",
+	"weak":             "1.24",
+	"testing/synctest": "1.25",
+}
+
+// symbols maps standard library functions and types, keyed by import path,
+// to the Go version that added them
+var symbols = map[string]map[string]string{
+	"bytes":       {"Cut": "1.18", "CutPrefix": "1.20", "CutSuffix": "1.20", "Lines": "1.24", "SplitSeq": "1.24", "FieldsSeq": "1.24"},
+	"context":     {"WithCancelCause": "1.20", "Cause": "1.20", "WithoutCancel": "1.21", "AfterFunc": "1.21", "WithDeadlineCause": "1.21", "WithTimeoutCause": "1.21"},
+	"errors":      {"Join": "1.20", "ErrUnsupported": "1.21"},
+	"fmt":         {"Append": "1.19", "Appendf": "1.19", "Appendln": "1.19"},
+	"io":          {"ReadAll": "1.16", "Discard": "1.16", "NopCloser": "1.16"},
+	"maps":        {"All": "1.23", "Keys": "1.23", "Values": "1.23", "Insert": "1.23", "Collect": "1.23"},
+	"os":          {"ReadFile": "1.16", "WriteFile": "1.16", "ReadDir": "1.16", "MkdirTemp": "1.16", "CreateTemp": "1.16", "DirFS": "1.16", "CopyFS": "1.23", "OpenRoot": "1.24", "OpenInRoot": "1.24"},
+	"os/signal":   {"NotifyContext": "1.16"},
+	"reflect":     {"TypeFor": "1.22"},
+	"slices":      {"Concat": "1.22", "Repeat": "1.23", "All": "1.23", "Values": "1.23", "Backward": "1.23", "Collect": "1.23", "AppendSeq": "1.23", "Sorted": "1.23", "SortedFunc": "1.23", "Chunk": "1.23"},
+	"strings":     {"Cut": "1.18", "CutPrefix": "1.20", "CutSuffix": "1.20", "Lines": "1.24", "SplitSeq": "1.24", "SplitAfterSeq": "1.24", "FieldsSeq": "1.24", "FieldsFuncSeq": "1.24"},
+	"sync":        {"OnceFunc": "1.21", "OnceValue": "1.21", "OnceValues": "1.21"},
+	"sync/atomic": {"Bool": "1.19", "Int32": "1.19", "Int64": "1.19", "Uint32": "1.19", "Uint64": "1.19", "Pointer": "1.19"},
+	"time":        {"DateTime": "1.20", "DateOnly": "1.20", "TimeOnly": "1.20"},
+}
+
+// builtins maps predeclared identifiers to the Go version that added them
+var builtins = map[string]string{
+	"any":        "1.18",
+	"comparable": "1.18",
+	"clear":      "1.21",
+	"max":        "1.21",
+	"min":        "1.21",
+}
+
+// Check returns the packages and language features used by code that need
+// a newer Go than version. Code that does not parse yields an error.
+func Check(code, version string) ([]Issue, error) {
+	target, ok := parse(version)
+	if !ok {
+		return nil, fmt.Errorf("invalid Go version: %s", version)
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", code, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse Go code: %w", err)
+	}
+
+	var issues []Issue
+	seen := make(map[string]bool)
+	report := func(pos token.Pos, feature, requires string) {
+		if seen[feature] || !newer(requires, target) {
+			return
+		}
+		seen[feature] = true
+		issues = append(issues, Issue{Line: fset.Position(pos).Line, Feature: feature, Requires: requires})
+	}
+
+	// Map local package names to import paths
+	imports := make(map[string]string)
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		name := filepath.Base(path)
+		if path == "math/rand/v2" {
+			name = "rand"
+		}
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+
+		if v, ok := packages[path]; ok {
+			report(spec.Pos(), "package "+path, v)
+		}
+	}
+
+	ast.Inspect(file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.SelectorExpr:
+			if pkg, ok := n.X.(*ast.Ident); ok && pkg.Obj == nil {
+				if path, ok := imports[pkg.Name]; ok {
+					if v, ok := symbols[path][n.Sel.Name]; ok {
+						report(n.Pos(), path+"."+n.Sel.Name, v)
+					}
+				}
+			}
+		case *ast.Ident:
+			// Unresolved identifiers are predeclared or package-level names
+			if v, ok := builtins[n.Name]; ok && n.Obj == nil && !declared(file, n.Name) {
+				report(n.Pos(), "predeclared "+n.Name, v)
+			}
+		case *ast.FuncType:
+			if n.TypeParams != nil && len(n.TypeParams.List) > 0 {
+				report(n.Pos(), "type parameters", "1.18")
+			}
+		case *ast.TypeSpec:
+			if n.TypeParams != nil && len(n.TypeParams.List) > 0 {
+				report(n.Pos(), "type parameters", "1.18")
+			}
+		case *ast.RangeStmt:
+			switch {
+			case isIntExpr(n.X):
+				report(n.Pos(), "range over int", "1.22")
+			case isFuncExpr(n.X):
+				report(n.Pos(), "range over func", "1.23")
+			}
+		}
+		return true
+	})
+
+	sort.SliceStable(issues, func(i, j int) bool { return issues[i].Line < issues[j].Line })
+	return issues, nil
+}
+
+// declared reports whether name is declared at package level, shadowing a
+// predeclared identifier
+func declared(file *ast.File, name string) bool {
+	return file.Scope != nil && file.Scope.Lookup(name) != nil
+}
+
+// isIntExpr reports whether a range expression is evidently an integer
+func isIntExpr(x ast.Expr) bool {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		return x.Kind == token.INT
+	case *ast.CallExpr:
+		if fn, ok := x.Fun.(*ast.Ident); ok && fn.Obj == nil {
+			return fn.Name == "len" || fn.Name == "cap" || fn.Name == "int"
+		}
+	case *ast.BinaryExpr:
+		return isIntExpr(x.X) || isIntExpr(x.Y)
+	case *ast.ParenExpr:
+		return isIntExpr(x.X)
+	}
+	return false
+}
+
+// isFuncExpr reports whether a range expression is evidently a function
+func isFuncExpr(x ast.Expr) bool {
+	_, ok := x.(*ast.FuncLit)
+	return ok
+}
+
+// version is a Go release as major and minor numbers
+type version struct {
+	major, minor int
+}
+
+var versionPattern = regexp.MustCompile(`^(?:go)?(\d+)\.(\d+)`)
+
+// parse reads a version such as "1.22", "1.22.3" or "go1.22rc1"
+func parse(s string) (version, bool) {
+	m := versionPattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return version{}, false
+	}
+	major, _ := strconv.Atoi(m[1])
+	minor, _ := strconv.Atoi(m[2])
+	return version{major, minor}, true
+}
+
+// newer reports whether required is a later release than target
+func newer(required string, target version) bool {
+	v, _ := parse(required)
+	if v.major != target.major {
+		return v.major > target.major
+	}
+	return v.minor > target.minor
+}
+
+// Valid reports whether s is a Go version such as "1.22" or "1.22.3"
+func Valid(s string) bool {
+	_, ok := parse(s)
+	return ok
+}
+
+// Detect returns the Go version generated code should target for a file
+// written to dir: the go directive of the enclosing module if there is one,
+// otherwise the version of the installed toolchain. The second value
+// describes where the version came from.
+func Detect(dir string) (string, string, error) {
+	if gomod, v := moduleDirective(dir); v != "" {
+		return v, gomod, nil
+	}
+
+	out, err := exec.Command("go", "env", "GOVERSION").Output()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to detect Go version: %w", err)
+	}
+
+	v, ok := parse(string(out))
+	if !ok {
+		return "", "", fmt.Errorf("failed to detect Go version: unexpected output %q", strings.TrimSpace(string(out)))
+	}
+	return fmt.Sprintf("%d.%d", v.major, v.minor), "go env GOVERSION", nil
+}
+
+var directivePattern = regexp.MustCompile(`^go\s+(\d+\.\d+(?:\.\d+)?)\s*$`)
+
+// moduleDirective finds the go.mod enclosing dir and returns its path and
+// go directive
+func moduleDirective(dir string) (string, string) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", ""
+	}
+
+	for {
+		gomod := filepath.Join(abs, "go.mod")
+		if f, err := os.Open(gomod); err == nil {
+			defer f.Close()
+			scanner := bufio.NewScanner(f)
+			for scanner.Scan() {
+				if m := directivePattern.FindStringSubmatch(strings.TrimSpace(scanner.Text())); m != nil {
+					return gomod, m[1]
+				}
+			}
+			return gomod, ""
+		}
+
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", ""
+		}
+		abs = parent
+	}
+}
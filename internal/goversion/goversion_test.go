@@ -0,0 +1,126 @@
+package goversion
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const modernCode = `package main
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+)
+
+func main() {
+	names := []string{"b", "a"}
+	slices.Sort(names)
+	for i := range 3 {
+		fmt.Println(i, min(i, 1))
+	}
+	before, _, _ := strings.Cut("a=b", "=")
+	slog.Info(before)
+}
+`
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		version  string
+		features []string
+	}{
+		{"1.25", nil},
+		{"1.22", nil},
+		{"1.21", []string{"range over int"}},
+		{"1.20", []string{"package log/slog", "package slices", "range over int", "predeclared min"}},
+		{"1.17", []string{"package log/slog", "package slices", "range over int", "predeclared min", "strings.Cut"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			issues, err := Check(modernCode, tt.version)
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			var got []string
+			for _, issue := range issues {
+				got = append(got, issue.Feature)
+			}
+			for _, want := range tt.features {
+				if !contains(got, want) {
+					t.Errorf("Check() missing %q, got %v", want, got)
+				}
+			}
+			if len(got) != len(tt.features) {
+				t.Errorf("Check() = %v, want %v", got, tt.features)
+			}
+		})
+	}
+}
+
+func TestCheck_ShadowedBuiltin(t *testing.T) {
+	code := `package main
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func main() {
+	_ = min(1, 2)
+}
+`
+	issues, err := Check(code, "1.20")
+	if err != nil {
+		t.Fatalf("Check() error = %v", err)
+	}
+	if len(issues) != 0 {
+		t.Errorf("Check() = %v, want no issues", issues)
+	}
+}
+
+func TestCheck_Invalid(t *testing.T) {
+	if _, err := Check(modernCode, "latest"); err == nil {
+		t.Error("Check() with an invalid version should fail")
+	}
+	if _, err := Check("package main\nfunc {", "1.22"); err == nil {
+		t.Error("Check() with invalid code should fail")
+	}
+}
+
+func TestDetect_ModuleDirective(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "cmd", "tool")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(root, "go.mod"), []byte("module example.com/x\n\ngo 1.21.5\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	v, source, err := Detect(sub)
+	if err != nil {
+		t.Fatalf("Detect() error = %v", err)
+	}
+	if v != "1.21.5" {
+		t.Errorf("Detect() = %s, want 1.21.5", v)
+	}
+	if !strings.HasSuffix(source, "go.mod") {
+		t.Errorf("Detect() source = %s, want go.mod", source)
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
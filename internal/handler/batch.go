@@ -0,0 +1,316 @@
+package handler
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/bonzonkim/gopher-script/internal/batch"
+	"github.com/bonzonkim/gopher-script/internal/conventions"
+	"github.com/bonzonkim/gopher-script/internal/injection"
+	"github.com/bonzonkim/gopher-script/internal/llm"
+	"github.com/bonzonkim/gopher-script/internal/redact"
+	"github.com/bonzonkim/gopher-script/internal/scan"
+	"go.uber.org/zap"
+)
+
+// BatchSubmitOptions contains options for submitting a batch job
+type BatchSubmitOptions struct {
+	InputPaths []string
+	OutputDir  string
+	Build      bool
+
+	// Redact replaces sensitive values with placeholders before the scripts
+	// are sent; the values are kept in the job to be resolved on collect
+	Redact          bool
+	RedactPolicy    redact.Policy
+	InternalDomains []string
+	AllowSensitive  bool
+	ScanAllowlist   []scan.AllowRule
+	Injection       injection.Mode
+}
+
+// BatchItemResult is the outcome of collecting a single script from a batch job
+type BatchItemResult struct {
+	InputPath string
+	Result    *TranspileResult
+	Err       error
+	// RedactPolicy is how the redacted values were resolved, or empty when
+	// the script was not redacted
+	RedactPolicy redact.Policy
+}
+
+// batchClient returns the LLM client as a BatchClienter if the provider supports batches
+func (h *Handler) batchClient() (llm.BatchClienter, error) {
+	client, ok := h.client.(llm.BatchClienter)
+	if !ok {
+		return nil, fmt.Errorf("provider %s does not support batch jobs", h.Provider)
+	}
+	return client, nil
+}
+
+// SubmitBatch builds a transpile prompt for every input script and submits
+// them to the provider as a single batch job
+func (h *Handler) SubmitBatch(opts BatchSubmitOptions) (*batch.Job, error) {
+	client, err := h.batchClient()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &batch.Job{
+		Provider:  string(h.Provider),
+		CreatedAt: time.Now().UTC(),
+		GoVersion: h.GoVersion,
+	}
+
+	requests := make([]llm.BatchRequest, 0, len(opts.InputPaths))
+	for i, inputPath := range opts.InputPaths {
+		scriptOpts := TranspileOptions{
+			InputPath:       inputPath,
+			Redact:          opts.Redact,
+			RedactPolicy:    opts.RedactPolicy,
+			InternalDomains: opts.InternalDomains,
+			AllowSensitive:  opts.AllowSensitive,
+			ScanAllowlist:   opts.ScanAllowlist,
+			Injection:       opts.Injection,
+		}
+		req, err := h.prepare(scriptOpts)
+		if err != nil {
+			return nil, err
+		}
+
+		prompt, err := h.buildTranspilePrompt(req)
+		if err != nil {
+			return nil, err
+		}
+
+		outputPath := h.Generator.GetDefaultOutputPath(inputPath)
+		if opts.OutputDir != "" {
+			outputPath = filepath.Join(opts.OutputDir, filepath.Base(outputPath))
+		}
+
+		entry := batch.Entry{
+			Metadata:   h.metadata(req, scriptOpts),
+			CustomID:   batch.CustomID(i + 1),
+			InputPath:  inputPath,
+			OutputPath: outputPath,
+			ScriptType: string(req.parsed.ScriptType),
+			InputHash:  llm.Hash(req.script),
+			PromptHash: llm.Hash(prompt),
+			Build:      opts.Build,
+
+			Script:         req.script,
+			ScriptPackages: req.packages,
+			NonPortable:    req.nonPortable,
+			Tasks:          req.parsed.Tasks,
+			Notebook:       req.parsed.Notebook,
+		}
+		if req.redacted != nil {
+			entry.Redactions = req.redacted.Redactions
+			entry.RedactPolicy = string(redactPolicy(scriptOpts.RedactPolicy))
+		}
+		job.Entries = append(job.Entries, entry)
+		requests = append(requests, llm.BatchRequest{CustomID: entry.CustomID, Prompt: prompt})
+	}
+
+	h.Logger.Info("Submitting batch job",
+		zap.String("provider", string(h.Provider)),
+		zap.Int("scripts", len(requests)))
+
+	job.ID, err = client.SubmitBatch(requests)
+
+	if h.Audit != nil {
+		for i, r := range requests {
+			entry := llm.AuditEntry{
+				InputPath: job.Entries[i].InputPath,
+				InputHash: job.Entries[i].InputHash,
+				Provider:  string(h.Provider),
+				Model:     h.Model,
+				Prompt:    r.Prompt,
+				BatchID:   job.ID,
+				Outcome:   llm.AuditOutcomeBatchSubmitted,
+			}
+			if err != nil {
+				entry.Outcome = llm.AuditOutcomeError
+				entry.Error = err.Error()
+			}
+			if auditErr := h.Audit.Append(entry); auditErr != nil {
+				return nil, fmt.Errorf("failed to write audit log: %w", auditErr)
+			}
+		}
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to submit batch: %w", err)
+	}
+
+	return job, nil
+}
+
+// BatchStatus returns the provider-side state of a batch job
+func (h *Handler) BatchStatus(job *batch.Job) (*llm.BatchStatus, error) {
+	client, err := h.batchClient()
+	if err != nil {
+		return nil, err
+	}
+
+	status, err := client.BatchStatus(job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch status: %w", err)
+	}
+
+	return status, nil
+}
+
+// CollectBatch downloads the results of a finished batch job and runs the
+// usual generation step on each of them
+func (h *Handler) CollectBatch(job *batch.Job) ([]BatchItemResult, error) {
+	client, err := h.batchClient()
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := client.BatchResults(job.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get batch results: %w", err)
+	}
+
+	byID := make(map[string]llm.BatchResult, len(results))
+	for _, r := range results {
+		if _, ok := job.Entry(r.CustomID); !ok {
+			h.Logger.Warn("Ignoring unknown batch result", zap.String("customID", r.CustomID))
+			continue
+		}
+		byID[r.CustomID] = r
+	}
+
+	// Every entry is reported, so that a script the provider returned
+	// nothing for is not mistaken for one that was converted
+	items := make([]BatchItemResult, 0, len(job.Entries))
+	var missing int
+	for i := range job.Entries {
+		entry := &job.Entries[i]
+		r, ok := byID[entry.CustomID]
+		if !ok {
+			missing++
+			r.Error = "the provider returned no result for this script"
+		}
+
+		if h.Audit != nil {
+			auditEntry := llm.AuditEntry{
+				InputPath:  entry.InputPath,
+				InputHash:  entry.InputHash,
+				Provider:   job.Provider,
+				Model:      h.Model,
+				PromptHash: entry.PromptHash,
+				BatchID:    job.ID,
+				Outcome:    llm.AuditOutcomeSuccess,
+			}
+			if r.Error != "" {
+				auditEntry.Outcome = llm.AuditOutcomeError
+				auditEntry.Error = r.Error
+			} else {
+				auditEntry.ResponseHash = llm.Hash(r.Text)
+			}
+			if err := h.Audit.Append(auditEntry); err != nil {
+				return nil, fmt.Errorf("failed to write audit log: %w", err)
+			}
+		}
+
+		item := BatchItemResult{InputPath: entry.InputPath, RedactPolicy: redact.Policy(entry.RedactPolicy)}
+		if r.Error != "" {
+			item.Err = fmt.Errorf("LLM request failed: %s", r.Error)
+			items = append(items, item)
+			continue
+		}
+
+		item.Result, item.Err = h.collectEntry(job, entry, r.Text)
+		items = append(items, item)
+	}
+
+	// A job with missing results can be collected again once they are in
+	if missing > 0 {
+		h.Logger.Warn("Batch returned no result for some scripts", zap.Int("missing", missing))
+	} else {
+		now := time.Now().UTC()
+		job.CollectedAt = &now
+	}
+
+	return items, nil
+}
+
+// collectEntry runs the steps Transpile runs after the LLM request on the
+// code returned for a batch entry, and writes it
+func (h *Handler) collectEntry(job *batch.Job, entry *batch.Entry, goCode string) (*TranspileResult, error) {
+	req, err := h.entryRequest(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	goCode = h.Generator.CleanCode(goCode)
+	if goCode, err = h.ensureDependencies(goCode, req); err != nil {
+		return nil, err
+	}
+	goCode, styleIssue, err := h.ensureCLIStyle(goCode, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(entry.Redactions) > 0 {
+		if goCode, err = redact.Restore(goCode, entry.Redactions, redactPolicy(redact.Policy(entry.RedactPolicy))); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := h.writeOutput(goCode, TranspileOptions{
+		InputPath:  entry.InputPath,
+		OutputPath: entry.OutputPath,
+		Build:      entry.Build,
+		BinaryPath: entry.BinaryPath,
+	})
+	if result == nil {
+		return nil, err
+	}
+
+	result.Compatibility = h.checkGoVersion(result.GoCode, job.GoVersion)
+	result.CLIStyleIssue = styleIssue
+	if entry.Metadata != nil {
+		entry.Metadata.BatchID = job.ID
+		entry.Metadata.Refinements = req.refinements
+		if req.refinements > 0 {
+			entry.Metadata.Prompts = append(entry.Metadata.Prompts, h.Prompts.Info(llm.TemplateRefine))
+		}
+		if metaErr := h.writeMetadata(result, entry.Metadata); metaErr != nil && err == nil {
+			err = metaErr
+		}
+	}
+	if entry.Script != "" {
+		result.Divergences = injection.Diverge(entry.Script, result.GoCode)
+	}
+	result.ScriptPackages = entry.ScriptPackages
+	result.NonPortable = entry.NonPortable
+	result.Tasks = entry.Tasks
+	result.Notebook = entry.Notebook
+	result.Redactions = entry.Redactions
+
+	return result, err
+}
+
+// entryRequest recreates what refining the code of a batch entry needs:
+// the project conventions and the instructions the prompt was built with
+func (h *Handler) entryRequest(entry *batch.Entry) (*request, error) {
+	conv, err := conventions.Find(filepath.Dir(entry.InputPath))
+	if err != nil {
+		return nil, err
+	}
+
+	instructions := h.Instructions
+	if entry.Metadata != nil {
+		instructions = entry.Metadata.Options.Instructions
+	}
+
+	return &request{
+		conventions:  conv,
+		instructions: append([]string(nil), instructions...),
+	}, nil
+}
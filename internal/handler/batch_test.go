@@ -0,0 +1,141 @@
+package handler
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bonzonkim/gopher-script/internal/batch"
+	"github.com/bonzonkim/gopher-script/internal/llm"
+	"go.uber.org/zap"
+)
+
+// fakeBatchClient returns fixed batch results and refines code with refined
+type fakeBatchClient struct {
+	results []llm.BatchResult
+	refined string
+	prompts []string
+}
+
+func (c *fakeBatchClient) Generate(prompt string) (string, error) {
+	c.prompts = append(c.prompts, prompt)
+	return c.refined, nil
+}
+
+func (c *fakeBatchClient) SubmitBatch(requests []llm.BatchRequest) (string, error) {
+	return "batch_1", nil
+}
+
+func (c *fakeBatchClient) BatchStatus(batchID string) (*llm.BatchStatus, error) {
+	return &llm.BatchStatus{ID: batchID, State: "completed", Done: true}, nil
+}
+
+func (c *fakeBatchClient) BatchResults(batchID string) ([]llm.BatchResult, error) {
+	return c.results, nil
+}
+
+const helloProgram = "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"hello\")\n}\n"
+
+// newBatchTestHandler returns a handler using client and a job with n
+// entries written under a temporary directory
+func newBatchTestHandler(t *testing.T, client *fakeBatchClient, n int) (*Handler, *batch.Job) {
+	t.Helper()
+
+	h := NewPromptHandler(zap.NewNop(), llm.ClientConfig{Provider: llm.ProviderOpenAI})
+	h.client = client
+	h.LLMClient = client
+
+	dir := t.TempDir()
+	job := &batch.Job{ID: "batch_1", Provider: string(llm.ProviderOpenAI)}
+	for i := 1; i <= n; i++ {
+		name := batch.CustomID(i)
+		job.Entries = append(job.Entries, batch.Entry{
+			CustomID:   name,
+			InputPath:  filepath.Join(dir, name+".sh"),
+			OutputPath: filepath.Join(dir, name+".go"),
+			ScriptType: "bash",
+		})
+	}
+	return h, job
+}
+
+func TestCollectBatch_ReportsEveryEntry(t *testing.T) {
+	client := &fakeBatchClient{results: []llm.BatchResult{
+		{CustomID: batch.CustomID(1), Text: helloProgram},
+		{CustomID: batch.CustomID(2), Error: "too many requests"},
+		{CustomID: "script-9999", Text: helloProgram},
+	}}
+	h, job := newBatchTestHandler(t, client, 3)
+
+	items, err := h.CollectBatch(job)
+	if err != nil {
+		t.Fatalf("CollectBatch failed: %v", err)
+	}
+
+	if len(items) != 3 {
+		t.Fatalf("expected an item for each of the 3 entries, got %d", len(items))
+	}
+
+	if items[0].Err != nil || items[0].Result == nil {
+		t.Fatalf("expected the first script to be converted, got %+v", items[0])
+	}
+	if _, err := os.Stat(job.Entries[0].OutputPath); err != nil {
+		t.Errorf("expected the Go file to be written: %v", err)
+	}
+
+	if items[1].Err == nil || !strings.Contains(items[1].Err.Error(), "too many requests") {
+		t.Errorf("expected the second script to fail with the provider error, got %v", items[1].Err)
+	}
+
+	if items[2].Err == nil || !strings.Contains(items[2].Err.Error(), "no result") {
+		t.Errorf("expected the script without a result to fail, got %v", items[2].Err)
+	}
+	if _, err := os.Stat(job.Entries[2].OutputPath); !os.IsNotExist(err) {
+		t.Errorf("expected no Go file for the script without a result, got %v", err)
+	}
+
+	if job.CollectedAt != nil {
+		t.Error("expected a job with missing results to stay uncollected")
+	}
+}
+
+func TestCollectBatch_MarksCompleteJobCollected(t *testing.T) {
+	client := &fakeBatchClient{results: []llm.BatchResult{
+		{CustomID: batch.CustomID(1), Text: helloProgram},
+		{CustomID: batch.CustomID(2), Error: "request expired"},
+	}}
+	h, job := newBatchTestHandler(t, client, 2)
+
+	if _, err := h.CollectBatch(job); err != nil {
+		t.Fatalf("CollectBatch failed: %v", err)
+	}
+
+	if job.CollectedAt == nil {
+		t.Error("expected a job with a result for every entry to be collected")
+	}
+}
+
+func TestCollectBatch_EnforcesDependencyPolicy(t *testing.T) {
+	thirdParty := "package main\n\nimport \"github.com/fatih/color\"\n\nfunc main() {\n\tcolor.Red(\"hello\")\n}\n"
+	client := &fakeBatchClient{
+		results: []llm.BatchResult{{CustomID: batch.CustomID(1), Text: thirdParty}},
+		refined: helloProgram,
+	}
+	h, job := newBatchTestHandler(t, client, 1)
+
+	items, err := h.CollectBatch(job)
+	if err != nil {
+		t.Fatalf("CollectBatch failed: %v", err)
+	}
+
+	if len(client.prompts) != 1 {
+		t.Fatalf("expected one refine request, got %d", len(client.prompts))
+	}
+	if items[0].Err != nil {
+		t.Fatalf("expected the refined code to be written, got %v", items[0].Err)
+	}
+	if strings.Contains(items[0].Result.GoCode, "fatih/color") {
+		t.Errorf("expected the disallowed import to be removed, got:\n%s", items[0].Result.GoCode)
+	}
+}
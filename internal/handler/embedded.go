@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bonzonkim/gopher-script/internal/embedded"
+	"go.uber.org/zap"
+)
+
+// DefaultCommandDir is the directory a rewritten YAML file runs the
+// programs from
+const DefaultCommandDir = "bin"
+
+// EmbeddedOptions contains options for transpiling the scripts embedded in
+// a YAML file
+type EmbeddedOptions struct {
+	InputPath string
+	// Selectors pick the scripts by path; without them every script found
+	// for the file's kind is transpiled
+	Selectors []string
+	// OutputDir receives the extracted scripts and their Go files
+	// (default: <name>-scripts next to the YAML file)
+	OutputDir string
+	Build     bool
+
+	// RewritePath, when set, is where a copy of the YAML file that runs the
+	// programs instead of the scripts is written
+	RewritePath string
+	// CommandDir is the directory the rewritten file runs the programs from
+	CommandDir string
+
+	// Script is applied to every script; its paths and Build are ignored.
+	// The egress policy is checked against InputPath, not the extracted
+	// script.
+	Script TranspileOptions
+}
+
+// EmbeddedItemResult is the outcome of transpiling one embedded script
+type EmbeddedItemResult struct {
+	Script     *embedded.Script
+	ScriptPath string
+	Result     *TranspileResult
+	Err        error
+}
+
+// EmbeddedResult is the outcome of transpiling the scripts of a YAML file
+type EmbeddedResult struct {
+	File  *embedded.File
+	Items []EmbeddedItemResult
+	// RewritePath is the rewritten YAML file, if one was written
+	RewritePath string
+}
+
+// DefaultEmbeddedOutputDir returns the directory the scripts of a YAML file
+// are extracted to by default
+func DefaultEmbeddedOutputDir(inputPath string) string {
+	base := filepath.Base(inputPath)
+	name := strings.TrimPrefix(strings.TrimSuffix(base, filepath.Ext(base)), ".")
+	return filepath.Join(filepath.Dir(inputPath), name+"-scripts")
+}
+
+// CommandPath is how a rewritten YAML file runs the program of a script
+func CommandPath(commandDir string, s *embedded.Script) string {
+	if commandDir == "" {
+		commandDir = DefaultCommandDir
+	}
+	command := path.Join(filepath.ToSlash(commandDir), s.Name)
+	if !strings.Contains(command, "/") {
+		command = "./" + command
+	}
+	return command
+}
+
+// TranspileEmbedded extracts the scripts embedded in a YAML file, transpiles
+// each into its own Go program and optionally writes a copy of the file
+// that runs the programs. A script that fails does not stop the others and
+// stays in the rewritten file.
+func (h *Handler) TranspileEmbedded(opts EmbeddedOptions) (*EmbeddedResult, error) {
+	f, err := embedded.Load(opts.InputPath, opts.Selectors)
+	if err != nil {
+		return nil, err
+	}
+	if len(f.Scripts) == 0 {
+		return nil, fmt.Errorf("no embedded scripts found in %s", opts.InputPath)
+	}
+
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		outputDir = DefaultEmbeddedOutputDir(opts.InputPath)
+	}
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	h.Logger.Info("Transpiling embedded scripts",
+		zap.String("input", opts.InputPath),
+		zap.String("kind", string(f.Kind)),
+		zap.Int("scripts", len(f.Scripts)))
+
+	result := &EmbeddedResult{File: f}
+	var converted []*embedded.Script
+	for _, s := range f.Scripts {
+		item := EmbeddedItemResult{Script: s, ScriptPath: filepath.Join(outputDir, s.FileName())}
+
+		if err := os.WriteFile(item.ScriptPath, []byte(s.Content), 0755); err != nil {
+			item.Err = fmt.Errorf("failed to write script: %w", err)
+			result.Items = append(result.Items, item)
+			continue
+		}
+
+		scriptOpts := opts.Script
+		scriptOpts.InputPath = item.ScriptPath
+		scriptOpts.SourcePath = opts.InputPath
+		scriptOpts.OutputPath = ""
+		scriptOpts.BinaryPath = ""
+		scriptOpts.Build = opts.Build
+
+		item.Result, item.Err = h.Transpile(scriptOpts)
+		if item.Err == nil {
+			converted = append(converted, s)
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	if opts.RewritePath == "" || len(converted) == 0 {
+		return result, nil
+	}
+
+	data, err := f.Rewrite(converted, func(s *embedded.Script) string {
+		return CommandPath(opts.CommandDir, s)
+	})
+	if err != nil {
+		return result, err
+	}
+	if err := os.WriteFile(opts.RewritePath, data, 0644); err != nil {
+		return result, fmt.Errorf("failed to write rewritten YAML: %w", err)
+	}
+	result.RewritePath = opts.RewritePath
+
+	h.Logger.Info("Rewrote YAML file", zap.String("path", opts.RewritePath), zap.Int("scripts", len(converted)))
+	return result, nil
+}
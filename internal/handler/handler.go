@@ -2,34 +2,130 @@ package handler
 
 import (
 	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/bonzonkim/gopher-script/internal/clistyle"
+	"github.com/bonzonkim/gopher-script/internal/conventions"
+	"github.com/bonzonkim/gopher-script/internal/deps"
+	"github.com/bonzonkim/gopher-script/internal/examples"
 	"github.com/bonzonkim/gopher-script/internal/generator"
+	"github.com/bonzonkim/gopher-script/internal/goversion"
+	"github.com/bonzonkim/gopher-script/internal/injection"
 	"github.com/bonzonkim/gopher-script/internal/llm"
+	"github.com/bonzonkim/gopher-script/internal/metadata"
+	"github.com/bonzonkim/gopher-script/internal/notebook"
 	"github.com/bonzonkim/gopher-script/internal/parser"
+	"github.com/bonzonkim/gopher-script/internal/policy"
+	"github.com/bonzonkim/gopher-script/internal/portability"
+	"github.com/bonzonkim/gopher-script/internal/redact"
+	"github.com/bonzonkim/gopher-script/internal/samplecheck"
+	"github.com/bonzonkim/gopher-script/internal/scan"
+	"github.com/bonzonkim/gopher-script/internal/taskfile"
 	"go.uber.org/zap"
 )
 
 // Handler orchestrates the transpilation process
 type Handler struct {
 	Logger    *zap.Logger
+	Provider  llm.Provider
+	Model     string
 	LLMClient llm.Clienter
 	Parser    *parser.Parser
 	Generator *generator.Generator
+
+	// Audit, when set, records every LLM exchange
+	Audit *llm.AuditLog
+
+	// Policy, when set, restricts which scripts may be sent to the provider
+	Policy *policy.Resolver
+
+	// Prompts renders the prompts sent to the LLM
+	Prompts *llm.PromptTemplates
+
+	// Examples, when set, supplies up to MaxExamples few-shot examples
+	// similar to each script
+	Examples    *examples.Library
+	MaxExamples int
+
+	// GoVersion, when set, is the Go version the generated code must
+	// compile with
+	GoVersion string
+
+	// Dependencies restricts the imports of generated code
+	Dependencies *deps.Policy
+
+	// CLIStyle, when set, is the command-line framework the generated
+	// program must use
+	CLIStyle clistyle.Style
+
+	// Instructions are additional requirements added to every prompt
+	Instructions []string
+
+	// client is the provider client without middlewares, used for
+	// provider-specific capabilities such as batch jobs
+	client llm.Clienter
+
+	// source is the script the current LLM request is made for
+	source llm.AuditSource
 }
 
-// NewHandler creates a new Handler with all dependencies
-func NewHandler(logger *zap.Logger, provider llm.Provider, apiKey string) (*Handler, error) {
-	client, err := llm.NewClient(provider, apiKey, logger)
+// NewHandler creates a new Handler with all dependencies. Middlewares wrap
+// every LLM call made by the handler; the first one is the outermost.
+func NewHandler(logger *zap.Logger, clientCfg llm.ClientConfig, middlewares ...llm.Middleware) (*Handler, error) {
+	client, err := llm.NewClient(clientCfg, logger)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create LLM client: %w", err)
 	}
 
+	h := &Handler{
+		Logger:    logger,
+		Provider:  clientCfg.Provider,
+		Model:     clientCfg.Model(),
+		client:    client,
+		Parser:    parser.NewParser(),
+		Generator: generator.NewGenerator(logger),
+		Prompts:   llm.DefaultPromptTemplates(),
+
+		Examples:     examples.Default(),
+		MaxExamples:  examples.DefaultLimit,
+		Dependencies: deps.StdlibOnly(),
+	}
+
+	// Auditing is the innermost middleware so that retries are recorded too
+	h.LLMClient = llm.Chain(client, append(middlewares, h.audited)...)
+
+	return h, nil
+}
+
+// NewPromptHandler creates a Handler without an LLM client. It can render
+// prompts with Prompt but cannot transpile.
+func NewPromptHandler(logger *zap.Logger, clientCfg llm.ClientConfig) *Handler {
 	return &Handler{
 		Logger:    logger,
-		LLMClient: client,
+		Provider:  clientCfg.Provider,
+		Model:     clientCfg.Model(),
 		Parser:    parser.NewParser(),
 		Generator: generator.NewGenerator(logger),
-	}, nil
+		Prompts:   llm.DefaultPromptTemplates(),
+
+		Examples:     examples.Default(),
+		MaxExamples:  examples.DefaultLimit,
+		Dependencies: deps.StdlibOnly(),
+	}
+}
+
+// audited records LLM exchanges to the audit log when one is configured
+func (h *Handler) audited(next llm.Clienter) llm.Clienter {
+	return llm.ClienterFunc(func(prompt string) (string, error) {
+		if h.Audit == nil {
+			return next.Generate(prompt)
+		}
+
+		usage, _ := h.client.(llm.UsageReporter)
+		return llm.WithAudit(h.Audit, h.Provider, h.Model, h.source, usage)(next).Generate(prompt)
+	})
 }
 
 // TranspileOptions contains options for the transpile operation
@@ -38,6 +134,30 @@ type TranspileOptions struct {
 	OutputPath string
 	Build      bool
 	BinaryPath string
+
+	// SourcePath is the file the script was extracted from, such as a YAML
+	// file. The egress policy is checked against it instead of InputPath.
+	SourcePath string
+
+	// Redact replaces secrets with placeholders before the script is sent
+	// to the LLM and resolves them in the generated code by RedactPolicy
+	Redact          bool
+	RedactPolicy    redact.Policy
+	InternalDomains []string
+
+	// AllowSensitive continues even when the scanner finds sensitive data
+	// that is not allowlisted by ScanAllowlist
+	AllowSensitive bool
+	ScanAllowlist  []scan.AllowRule
+
+	// Injection is how suspected prompt injection in the script is
+	// handled; the default is injection.ModeWarn
+	Injection injection.Mode
+
+	// SampleInputs are files on which the generated program must produce
+	// the same output as the script. Only awk and sed scripts are checked,
+	// and the inputs are never sent to the LLM.
+	SampleInputs []string
 }
 
 // TranspileResult contains the result of transpilation
@@ -45,41 +165,508 @@ type TranspileResult struct {
 	GoCode     string
 	OutputPath string
 	BinaryPath string
+	Redactions []redact.Redaction
+	Findings   []scan.Finding
+
+	// Injections is text in the script that could steer the LLM, and
+	// Divergences is behavior of the code the script does not show
+	Injections  []injection.Finding
+	Divergences []injection.Divergence
+
+	// GoModPath is the go.mod written for the third-party modules the code
+	// requires, if any
+	GoModPath string
+	Modules   []deps.Module
+
+	// MetadataPath is the sidecar file recording how the code was generated
+	MetadataPath string
+	Metadata     *metadata.Metadata
+
+	// ConventionsPath is the project conventions file the prompts included
+	ConventionsPath string
+	// ScriptPackages are the third-party packages the script imports,
+	// which the Go code replaces
+	ScriptPackages []deps.ScriptPackage
+	// NonPortable are commands in the script with no portable Go
+	// equivalent, such as Windows-only PowerShell cmdlets
+	NonPortable []portability.Issue
+	// Samples compares the output of the script and the Go program on
+	// each sample input
+	Samples []samplecheck.Result
+	// Tasks is the Makefile or justfile the program runs the tasks of
+	Tasks *taskfile.File
+	// Notebook is the Jupyter notebook the program was converted from
+	Notebook *notebook.Notebook
+
+	// CLIStyleIssue describes how the code still fails to use the requested
+	// CLI style after refinement
+	CLIStyleIssue string
+
+	// Compatibility lists features that still need a newer Go than the
+	// target version after refinement
+	Compatibility []goversion.Issue
 }
 
-// RequestLLM sends the script code to LLM for transpilation
+// RequestLLM sends the script code to LLM for transpilation. Like
+// Transpile it stops when the code contains sensitive data; use Transpile
+// to convert a file with every check.
 func (h *Handler) RequestLLM(scriptType parser.ScriptType, code string) (string, error) {
+	if !h.Parser.IsSupportedType(scriptType) {
+		return "", fmt.Errorf("unsupported script type: %s", scriptType)
+	}
+
+	parsed := &parser.ParseResult{ScriptType: scriptType, Content: code, Source: code}
+	if findings := h.scan(parsed, nil, TranspileOptions{}); len(findings) > 0 {
+		return "", &scan.BlockedError{Findings: findings}
+	}
+
+	return h.requestLLM(&request{
+		parsed:       parsed,
+		script:       code,
+		instructions: append([]string(nil), h.Instructions...),
+	})
+}
+
+// requestLLM sends the prepared script to LLM for transpilation
+func (h *Handler) requestLLM(req *request) (string, error) {
+	parsed := req.parsed
 	h.Logger.Info("Requesting LLM for transpilation",
-		zap.String("scriptType", string(scriptType)),
-		zap.Int("codeLength", len(code)))
+		zap.String("scriptType", string(parsed.ScriptType)),
+		zap.Int("codeLength", len(parsed.Content)))
+
+	prompt, err := h.buildTranspilePrompt(req)
+	if err != nil {
+		return "", err
+	}
+
+	h.source = llm.AuditSource{Path: parsed.FilePath, Hash: llm.Hash(parsed.Content)}
+
+	goCode, err := h.LLMClient.Generate(prompt)
+	if err != nil {
+		return "", fmt.Errorf("LLM request failed: %w", err)
+	}
 
-	// Build appropriate prompt based on script type
+	h.Logger.Info("LLM transpilation completed", zap.Int("resultLength", len(goCode)))
+	return goCode, nil
+}
+
+// buildTranspilePrompt renders the transpile prompt for a prepared script
+func (h *Handler) buildTranspilePrompt(req *request) (string, error) {
+	parsed := req.parsed
 	var llmScriptType llm.ScriptType
-	switch scriptType {
+	switch parsed.ScriptType {
 	case parser.ScriptTypePython:
 		llmScriptType = llm.ScriptTypePython
 	case parser.ScriptTypeShell:
 		llmScriptType = llm.ScriptTypeShell
+	case parser.ScriptTypePerl:
+		llmScriptType = llm.ScriptTypePerl
+	case parser.ScriptTypeRuby:
+		llmScriptType = llm.ScriptTypeRuby
+	case parser.ScriptTypeNode:
+		llmScriptType = llm.ScriptTypeNode
+	case parser.ScriptTypePwsh:
+		llmScriptType = llm.ScriptTypePwsh
+	case parser.ScriptTypeAwk:
+		llmScriptType = llm.ScriptTypeAwk
+	case parser.ScriptTypeSed:
+		llmScriptType = llm.ScriptTypeSed
 	default:
-		return "", fmt.Errorf("unsupported script type: %s", scriptType)
+		return "", fmt.Errorf("unsupported script type: %s", parsed.ScriptType)
 	}
 
-	prompt := llm.BuildTranspilePrompt(llmScriptType, code)
+	examples := h.selectExamples(parsed)
+	req.examples = make([]string, len(examples))
+	for i, e := range examples {
+		req.examples[i] = e.Name
+	}
 
-	goCode, err := h.LLMClient.Generate(prompt)
+	prompt, err := h.Prompts.Transpile(llm.PromptData{
+		ScriptType:       llmScriptType,
+		Dialect:          llm.Dialect(parsed.Dialect),
+		DialectGuidance:  llm.DialectGuidance(llm.Dialect(parsed.Dialect)),
+		Code:             parsed.Content,
+		FileName:         parsed.FileName,
+		GoVersion:        h.GoVersion,
+		DependencyPolicy: h.Dependencies.Instruction(),
+		CLIStyle:         string(h.CLIStyle),
+		CLIGuidance:      clistyle.Guidance(h.CLIStyle),
+		Provider:         h.Provider,
+		Model:            h.Model,
+		Conventions:      req.conventionsContent(),
+		ScriptPackages:   req.packageNames(),
+		Instructions:     req.instructions,
+		Examples:         examples,
+	})
 	if err != nil {
-		return "", fmt.Errorf("LLM request failed: %w", err)
+		return "", err
 	}
 
-	h.Logger.Info("LLM transpilation completed", zap.Int("resultLength", len(goCode)))
-	return goCode, nil
+	req.promptHash = llm.Hash(prompt)
+	return prompt, nil
+}
+
+// selectExamples picks the few-shot examples most similar to the script
+func (h *Handler) selectExamples(parsed *parser.ParseResult) []llm.PromptExample {
+	selected := h.Examples.Select(parsed.ScriptType, parsed.Content, h.MaxExamples)
+	if len(selected) == 0 {
+		return nil
+	}
+
+	names := make([]string, len(selected))
+	promptExamples := make([]llm.PromptExample, len(selected))
+	for i, e := range selected {
+		names[i] = e.Name
+		promptExamples[i] = llm.PromptExample{Name: e.Name, Script: e.Script, GoCode: e.GoCode}
+	}
+	h.Logger.Debug("Selected few-shot examples", zap.Strings("examples", names))
+
+	return promptExamples
 }
 
 // Transpile converts a script file to Go and optionally builds it
 func (h *Handler) Transpile(opts TranspileOptions) (*TranspileResult, error) {
 	h.Logger.Info("Starting transpilation", zap.String("input", opts.InputPath))
 
-	// Step 1: Parse the input file
+	// Step 1: Parse the input file and prepare what is sent
+	req, err := h.prepare(opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.SampleInputs) > 0 && sampleInterpreters[req.parsed.ScriptType] == nil {
+		return nil, fmt.Errorf("sample inputs can only be checked for awk and sed scripts, not %s", req.parsed.ScriptType)
+	}
+
+	// Step 2: Request LLM for transpilation
+	goCode, err := h.requestLLM(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transpile: %w", err)
+	}
+
+	// Step 3: Make sure the code follows the dependency policy and compiles
+	// with the target Go version
+	goCode = h.Generator.CleanCode(goCode)
+	goCode, err = h.ensureDependencies(goCode, req)
+	if err != nil {
+		return nil, err
+	}
+
+	goCode, styleIssue, err := h.ensureCLIStyle(goCode, req)
+	if err != nil {
+		return nil, err
+	}
+
+	goCode, issues, err := h.ensureGoVersion(goCode, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.redacted != nil && len(req.redacted.Redactions) > 0 {
+		if goCode, err = redact.Restore(goCode, req.redacted.Redactions, redactPolicy(opts.RedactPolicy)); err != nil {
+			return nil, err
+		}
+	}
+
+	divergences := injection.Diverge(req.script, goCode)
+	if len(divergences) > 0 {
+		h.Logger.Warn("Generated code does things the script does not", zap.Int("divergences", len(divergences)))
+	}
+
+	samples := h.checkSamples(goCode, req, opts.SampleInputs)
+
+	result, err := h.writeOutput(goCode, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.writeMetadata(result, h.metadata(req, opts)); err != nil {
+		return nil, err
+	}
+	result.Samples = samples
+	result.Findings = req.findings
+	result.Injections = req.injections
+	result.Divergences = divergences
+	result.ScriptPackages = req.packages
+	result.NonPortable = req.nonPortable
+	result.Tasks = req.parsed.Tasks
+	result.Notebook = req.parsed.Notebook
+	result.Compatibility = issues
+	result.CLIStyleIssue = styleIssue
+	if req.conventions != nil {
+		result.ConventionsPath = req.conventions.Path
+	}
+	if req.redacted != nil {
+		result.Redactions = req.redacted.Redactions
+	}
+
+	h.Logger.Info("Transpilation completed successfully",
+		zap.String("output", result.OutputPath),
+		zap.String("binary", result.BinaryPath))
+
+	return result, nil
+}
+
+// ensureDependencies asks the LLM once to remove imports the dependency
+// policy does not allow. Remaining violations are rejected when the code is
+// written.
+func (h *Handler) ensureDependencies(goCode string, req *request) (string, error) {
+	violations := h.checkDependencies(goCode)
+	if len(violations) == 0 {
+		return goCode, nil
+	}
+
+	h.Logger.Info("Generated code violates the dependency policy, refining", zap.Int("violations", len(violations)))
+
+	var sb strings.Builder
+	sb.WriteString("The code imports packages that are not allowed:\n")
+	for _, v := range violations {
+		fmt.Fprintf(&sb, "- %s\n", v)
+	}
+	sb.WriteString(h.Dependencies.Instruction() + ".")
+
+	return h.refine(goCode, sb.String(), req)
+}
+
+// checkDependencies returns the imports in goCode that the dependency
+// policy does not allow. Code that cannot be checked yields no violations;
+// the build reports those problems.
+func (h *Handler) checkDependencies(goCode string) []deps.Violation {
+	if h.Dependencies == nil {
+		return nil
+	}
+
+	violations, _, err := h.Dependencies.Check(goCode)
+	if err != nil {
+		h.Logger.Warn("Skipped dependency check", zap.Error(err))
+		return nil
+	}
+	return violations
+}
+
+// ensureCLIStyle asks the LLM once to switch the code to the requested CLI
+// framework if it uses another one. The remaining problem is returned.
+func (h *Handler) ensureCLIStyle(goCode string, req *request) (string, string, error) {
+	issue := h.checkCLIStyle(goCode)
+	if issue == "" {
+		return goCode, "", nil
+	}
+
+	h.Logger.Info("Generated code does not use the requested CLI style, refining",
+		zap.String("cliStyle", string(h.CLIStyle)),
+		zap.String("issue", issue))
+
+	problem := fmt.Sprintf("The program must use the %s command-line style, but %s. Follow these rules:\n%s",
+		h.CLIStyle, issue, clistyle.Guidance(h.CLIStyle))
+
+	refined, err := h.refine(goCode, problem, req)
+	if err != nil {
+		return "", "", err
+	}
+
+	return refined, h.checkCLIStyle(refined), nil
+}
+
+// checkCLIStyle describes how goCode fails to use the requested CLI style
+func (h *Handler) checkCLIStyle(goCode string) string {
+	if h.CLIStyle == "" {
+		return ""
+	}
+
+	issue, err := clistyle.Check(goCode, h.CLIStyle)
+	if err != nil {
+		h.Logger.Warn("Skipped CLI style check", zap.Error(err))
+		return ""
+	}
+	return issue
+}
+
+// ensureGoVersion checks the generated code against the target Go version
+// and asks the LLM once to replace anything that is too new. The issues
+// that remain are returned.
+func (h *Handler) ensureGoVersion(goCode string, req *request) (string, []goversion.Issue, error) {
+	issues := h.checkGoVersion(goCode, h.GoVersion)
+	if len(issues) == 0 {
+		return goCode, nil, nil
+	}
+
+	h.Logger.Info("Generated code needs a newer Go version, refining",
+		zap.String("goVersion", h.GoVersion),
+		zap.Int("issues", len(issues)))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "The code must compile with Go %s, but it uses features from newer releases:\n", h.GoVersion)
+	for _, issue := range issues {
+		fmt.Fprintf(&sb, "- %s\n", issue)
+	}
+	sb.WriteString("Rewrite these parts using only what Go " + h.GoVersion + " provides.")
+
+	refined, err := h.refine(goCode, sb.String(), req)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return refined, h.checkGoVersion(refined, h.GoVersion), nil
+}
+
+// checkGoVersion returns the features in goCode that need a newer Go than
+// version. Code that cannot be checked yields no issues; the build reports
+// those problems.
+func (h *Handler) checkGoVersion(goCode, version string) []goversion.Issue {
+	if version == "" {
+		return nil
+	}
+
+	issues, err := goversion.Check(goCode, version)
+	if err != nil {
+		h.Logger.Warn("Skipped Go version check", zap.Error(err))
+		return nil
+	}
+	return issues
+}
+
+// sampleInterpreters run scripts without a shebang for the sample check
+var sampleInterpreters = map[parser.ScriptType][]string{
+	parser.ScriptTypeAwk: {"awk", "-f"},
+	parser.ScriptTypeSed: {"sed", "-f"},
+}
+
+// checkSamples builds goCode and compares its output with the script's on
+// each sample input. A program that cannot be built or run is not checked;
+// the build reports those problems.
+func (h *Handler) checkSamples(goCode string, req *request, inputs []string) []samplecheck.Result {
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	binary, cleanup, err := h.Generator.BuildTemp(goCode, deps.GoMod("sample", h.GoVersion, nil))
+	if err != nil {
+		h.Logger.Warn("Skipped sample check", zap.Error(err))
+		return nil
+	}
+	defer cleanup()
+
+	script := samplecheck.ScriptCommand(req.parsed.FilePath, req.script, sampleInterpreters[req.parsed.ScriptType])
+	results, err := samplecheck.Check(script, binary, inputs)
+	if err != nil {
+		h.Logger.Warn("Skipped sample check", zap.Error(err))
+		return nil
+	}
+
+	for _, r := range results {
+		if !r.Match {
+			h.Logger.Warn("Generated program output differs from the script", zap.String("sample", r.Input))
+		}
+	}
+	return results
+}
+
+// refine asks the LLM to fix generated code and returns the cleaned result
+func (h *Handler) refine(goCode, problem string, req *request) (string, error) {
+	req.refinements++
+	prompt, err := h.Prompts.Refine(llm.PromptData{
+		GoCode:       goCode,
+		Error:        problem,
+		GoVersion:    h.GoVersion,
+		Provider:     h.Provider,
+		Model:        h.Model,
+		Conventions:  req.conventionsContent(),
+		Instructions: req.instructions,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	refined, err := h.LLMClient.Generate(prompt)
+	if err != nil {
+		return "", fmt.Errorf("LLM refine request failed: %w", err)
+	}
+
+	return h.Generator.CleanCode(refined), nil
+}
+
+// request is a script prepared for sending to the LLM
+type request struct {
+	// parsed is the script as sent, with redacted content if enabled
+	parsed       *parser.ParseResult
+	redacted     *redact.Result
+	findings     []scan.Finding
+	injections   []injection.Finding
+	instructions []string
+
+	// script is the unredacted script content
+	script string
+
+	// promptHash, examples and refinements record how the code was
+	// generated
+	promptHash  string
+	examples    []string
+	refinements int
+
+	// conventions is the project conventions file found for the script
+	conventions *conventions.File
+	// packages are the third-party packages the script imports
+	packages []deps.ScriptPackage
+	// nonPortable are the commands without a portable Go equivalent
+	nonPortable []portability.Issue
+}
+
+// newRequest creates the request for a parsed script, with the project
+// conventions that apply to it and the handler's instructions
+func (h *Handler) newRequest(parsed *parser.ParseResult) (*request, error) {
+	conv, err := conventions.Find(filepath.Dir(parsed.FilePath))
+	if err != nil {
+		return nil, err
+	}
+	if conv != nil {
+		h.Logger.Info("Using project conventions", zap.String("file", conv.Path))
+	}
+
+	var packages []deps.ScriptPackage
+	var nonPortable []portability.Issue
+	switch parsed.ScriptType {
+	case parser.ScriptTypeNode:
+		if packages, err = deps.NodePackages(parsed.FilePath, parsed.Content); err != nil {
+			return nil, err
+		}
+	case parser.ScriptTypePwsh:
+		nonPortable = portability.PowerShell(parsed.Content)
+		if len(nonPortable) > 0 {
+			h.Logger.Warn("Script uses commands without a portable Go equivalent",
+				zap.String("input", parsed.FilePath),
+				zap.Int("commands", len(nonPortable)))
+		}
+	}
+
+	return &request{
+		parsed:       parsed,
+		script:       parsed.Content,
+		conventions:  conv,
+		packages:     packages,
+		nonPortable:  nonPortable,
+		instructions: append([]string(nil), h.Instructions...),
+	}, nil
+}
+
+// packageNames returns the script's third-party packages with their
+// declared versions
+func (r *request) packageNames() []string {
+	names := make([]string, len(r.packages))
+	for i, p := range r.packages {
+		names[i] = p.String()
+	}
+	return names
+}
+
+// conventionsContent returns the project conventions, if any
+func (r *request) conventionsContent() string {
+	if r.conventions == nil {
+		return ""
+	}
+	return r.conventions.Content
+}
+
+// prepare parses the script, enforces the egress policy and the
+// sensitive-data gate, and applies redaction
+func (h *Handler) prepare(opts TranspileOptions) (*request, error) {
 	parsed, err := h.Parser.Parse(opts.InputPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse input file: %w", err)
@@ -92,20 +679,162 @@ func (h *Handler) Transpile(opts TranspileOptions) (*TranspileResult, error) {
 
 	h.Logger.Info("Parsed script file",
 		zap.String("file", parsed.FileName),
-		zap.String("type", string(parsed.ScriptType)))
+		zap.String("type", string(parsed.ScriptType)),
+		zap.String("dialect", string(parsed.Dialect)))
 
-	// Step 2: Request LLM for transpilation
-	goCode, err := h.RequestLLM(parsed.ScriptType, parsed.Content)
+	policyPath := opts.InputPath
+	if opts.SourcePath != "" {
+		policyPath = opts.SourcePath
+	}
+	if err := h.checkPolicy(policyPath, parsed.Content); err != nil {
+		return nil, err
+	}
+
+	// Redact secrets so they never leave the machine
+	var redacted *redact.Result
+	if opts.Redact {
+		redacted = redact.NewRedactor(opts.InternalDomains).Redact(parsed.Content)
+		h.Logger.Info("Redacted sensitive values", zap.Int("count", len(redacted.Redactions)))
+	}
+
+	// Stop before any LLM call if sensitive data would still be sent
+	findings := h.scan(parsed, redacted, opts)
+	if len(findings) > 0 && !opts.AllowSensitive {
+		return nil, &scan.BlockedError{Findings: findings}
+	}
+
+	injections, err := h.detectInjection(parsed, opts.Injection)
 	if err != nil {
-		return nil, fmt.Errorf("failed to transpile: %w", err)
+		return nil, err
+	}
+
+	req, err := h.newRequest(parsed)
+	if err != nil {
+		return nil, err
+	}
+	req.redacted = redacted
+	req.findings = findings
+	req.injections = injections
+	if redacted != nil {
+		sent := *parsed
+		sent.Content = redacted.Content
+		req.parsed = &sent
+		if len(redacted.Redactions) > 0 {
+			req.instructions = append(req.instructions, llm.PlaceholderInstruction)
+		}
+	}
+
+	return req, nil
+}
+
+// Prompt returns the transpile prompt that Transpile would send for opts
+func (h *Handler) Prompt(opts TranspileOptions) (string, error) {
+	req, err := h.prepare(opts)
+	if err != nil {
+		return "", err
+	}
+	return h.buildTranspilePrompt(req)
+}
+
+// detectInjection looks for text in the script that could break out of the
+// prompt or steer the LLM, and stops in block mode when there is any
+func (h *Handler) detectInjection(parsed *parser.ParseResult, mode injection.Mode) ([]injection.Finding, error) {
+	findings := injection.Detect(parsed.FilePath, parsed.Content)
+	if len(findings) == 0 {
+		return nil, nil
+	}
+	for i, f := range findings {
+		findings[i].Line, findings[i].Column = parsed.SourcePosition(f.Value(), f.Line, f.Column)
+	}
+
+	h.Logger.Warn("Possible prompt injection in script", zap.String("input", parsed.FilePath), zap.Int("findings", len(findings)))
+	if mode == injection.ModeBlock {
+		return nil, &injection.BlockedError{Findings: findings}
+	}
+	return findings, nil
+}
+
+// checkPolicy verifies that the egress policy allows sending the script to
+// the handler's provider
+func (h *Handler) checkPolicy(path, content string) error {
+	if h.Policy == nil {
+		return nil
+	}
+
+	if err := h.Policy.Check(path, content, string(h.Provider)); err != nil {
+		h.Logger.Warn("Blocked by egress policy", zap.String("input", path), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// scan returns the sensitive findings in the script that would be sent to
+// the LLM, at their position in the input file. Values that are redacted
+// before sending are not reported.
+func (h *Handler) scan(parsed *parser.ParseResult, redacted *redact.Result, opts TranspileOptions) []scan.Finding {
+	findings := scan.NewScanner(opts.InternalDomains, opts.ScanAllowlist).Scan(parsed.FilePath, parsed.Content)
+	for i, f := range findings {
+		findings[i].Line, findings[i].Column = parsed.SourcePosition(f.Value(), f.Line, f.Column)
+	}
+	if redacted == nil {
+		return findings
 	}
 
+	var remaining []scan.Finding
+	for _, f := range findings {
+		if !isRedacted(f.Value(), redacted.Redactions) {
+			remaining = append(remaining, f)
+		}
+	}
+	return remaining
+}
+
+// redactPolicy returns the policy, defaulting to restoring the values
+func redactPolicy(policy redact.Policy) redact.Policy {
+	if policy == "" {
+		return redact.PolicyRestore
+	}
+	return policy
+}
+
+// isRedacted reports whether value is removed by one of the redactions
+func isRedacted(value string, redactions []redact.Redaction) bool {
+	for _, r := range redactions {
+		if strings.Contains(r.Value, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeOutput formats and writes the generated Go code and optionally builds it
+func (h *Handler) writeOutput(goCode string, opts TranspileOptions) (*TranspileResult, error) {
 	// Step 3: Determine output path
 	outputPath := opts.OutputPath
 	if outputPath == "" {
 		outputPath = h.Generator.GetDefaultOutputPath(opts.InputPath)
 	}
 
+	// Reject imports the dependency policy does not allow before writing
+	var modules []deps.Module
+	if h.Dependencies != nil {
+		violations, required, err := h.Dependencies.Check(h.Generator.CleanCode(goCode))
+		if err == nil && len(violations) > 0 {
+			return nil, &deps.ViolationError{Violations: violations}
+		}
+		modules = required
+	}
+
+	// Declare the third-party modules the code requires, before writing
+	// anything else, so that a foreign go.mod stops the conversion
+	var goModPath string
+	if len(modules) > 0 {
+		var err error
+		if goModPath, err = h.writeModule(outputPath, modules); err != nil {
+			return nil, err
+		}
+	}
+
 	// Step 4: Generate Go file
 	genResult, err := h.Generator.Generate(goCode, outputPath)
 	if err != nil {
@@ -115,6 +844,8 @@ func (h *Handler) Transpile(opts TranspileOptions) (*TranspileResult, error) {
 	result := &TranspileResult{
 		GoCode:     genResult.GoCode,
 		OutputPath: genResult.OutputPath,
+		GoModPath:  goModPath,
+		Modules:    modules,
 	}
 
 	// Step 5: Build if requested
@@ -124,6 +855,12 @@ func (h *Handler) Transpile(opts TranspileOptions) (*TranspileResult, error) {
 			binaryPath = h.Generator.GetDefaultBinaryPath(outputPath)
 		}
 
+		if result.GoModPath != "" {
+			if err := h.Generator.Tidy(filepath.Dir(result.GoModPath)); err != nil {
+				return nil, fmt.Errorf("failed to resolve modules: %w", err)
+			}
+		}
+
 		if err := h.Generator.Build(outputPath, binaryPath); err != nil {
 			return nil, fmt.Errorf("failed to build binary: %w", err)
 		}
@@ -131,9 +868,110 @@ func (h *Handler) Transpile(opts TranspileOptions) (*TranspileResult, error) {
 		result.BinaryPath = binaryPath
 	}
 
-	h.Logger.Info("Transpilation completed successfully",
-		zap.String("output", result.OutputPath),
-		zap.String("binary", result.BinaryPath))
-
 	return result, nil
 }
+
+// metadata describes how the code for req is generated
+func (h *Handler) metadata(req *request, opts TranspileOptions) *metadata.Metadata {
+	m := &metadata.Metadata{
+		Tool: metadata.ToolVersion(),
+		Input: metadata.Input{
+			Path:       req.parsed.FilePath,
+			Hash:       llm.Hash(req.script),
+			ScriptType: string(req.parsed.ScriptType),
+			Dialect:    string(req.parsed.Dialect),
+		},
+		Provider:   string(h.Provider),
+		Model:      h.Model,
+		Prompts:    []llm.TemplateInfo{h.Prompts.Info(llm.TemplateTranspile)},
+		PromptHash: req.promptHash,
+		Options: metadata.Options{
+			GoVersion:    h.GoVersion,
+			CLIStyle:     string(h.CLIStyle),
+			Redact:       opts.Redact,
+			Examples:     req.examples,
+			Instructions: h.Instructions,
+		},
+		Refinements: req.refinements,
+	}
+
+	if req.refinements > 0 {
+		m.Prompts = append(m.Prompts, h.Prompts.Info(llm.TemplateRefine))
+	}
+	if opts.Redact {
+		m.Options.RedactPolicy = string(redactPolicy(opts.RedactPolicy))
+	}
+	if h.Dependencies != nil {
+		m.Options.Dependencies = string(h.Dependencies.Mode)
+		for _, mod := range h.Dependencies.Modules {
+			m.Options.Modules = append(m.Options.Modules, mod.String())
+		}
+	}
+	if req.conventions != nil {
+		m.Options.Conventions = &metadata.File{Path: req.conventions.Path, Hash: llm.Hash(req.conventions.Content)}
+	}
+
+	return m
+}
+
+// writeMetadata writes the sidecar metadata file for a generated Go file
+func (h *Handler) writeMetadata(result *TranspileResult, m *metadata.Metadata) error {
+	m.GeneratedAt = time.Now().UTC()
+	m.OutputHash = llm.Hash(result.GoCode)
+
+	path := metadata.PathFor(result.OutputPath)
+	if err := metadata.Write(path, m); err != nil {
+		return err
+	}
+
+	result.MetadataPath = path
+	result.Metadata = m
+	return nil
+}
+
+// writeModule writes the go.mod next to the generated file so that it
+// requires modules
+func (h *Handler) writeModule(outputPath string, modules []deps.Module) (string, error) {
+	requires := make([]string, len(modules))
+	for i, m := range modules {
+		requires[i] = m.String()
+	}
+
+	// A go.mod written for another program in the same directory keeps its
+	// module path and requirements
+	dir := filepath.Dir(outputPath)
+	path := modulePath(outputPath)
+	existing, err := h.Generator.ReadModule(dir)
+	if err != nil {
+		return "", err
+	}
+	if existing != "" {
+		existingPath, existingModules := deps.ParseGoMod(existing)
+		if existingPath != "" {
+			path = existingPath
+		}
+		modules = deps.MergeModules(existingModules, modules)
+	}
+
+	goMod := deps.GoMod(path, h.GoVersion, modules)
+	return h.Generator.WriteModule(dir, goMod, requires)
+}
+
+// modulePath derives a module path from the generated file name
+func modulePath(outputPath string) string {
+	name := strings.TrimSuffix(filepath.Base(outputPath), filepath.Ext(outputPath))
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_', r == '.':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + ('a' - 'A')
+		}
+		return '-'
+	}, name)
+
+	if name == "" || name == "." {
+		return "main"
+	}
+	return name
+}
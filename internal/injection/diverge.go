@@ -0,0 +1,79 @@
+package injection
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Divergence kinds
+const (
+	DivergenceEndpoint = "endpoint"
+	DivergenceCommand  = "command"
+)
+
+// Divergence is behavior of the generated code that the script does not
+// show, such as a network endpoint the script never contacts
+type Divergence struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+	Line  int    `json:"line"`
+}
+
+func (d Divergence) String() string {
+	return fmt.Sprintf("line %d: new %s %s", d.Line, d.Kind, d.Value)
+}
+
+var (
+	// urlPattern matches URLs with a scheme
+	urlPattern = regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s"'` + "`" + `<>(){}\[\]]+`)
+	// hostPortPattern matches "host:port" string literals as passed to net.Dial
+	hostPortPattern = regexp.MustCompile(`"((?:localhost|[a-zA-Z0-9-]+(?:\.[a-zA-Z0-9-]+)+)):\d{1,5}"`)
+	// commandPattern matches the program started with exec.Command
+	commandPattern = regexp.MustCompile(`exec\.Command(?:Context)?\((?:[^,()"]+,\s*)?"([^"]+)"`)
+)
+
+// Diverge reports network endpoints and commands in goCode that do not
+// appear in the script
+func Diverge(script, goCode string) []Divergence {
+	lowerScript := strings.ToLower(script)
+	seen := make(map[string]bool)
+
+	var divergences []Divergence
+	add := func(kind, value string, offset int) {
+		key := kind + "\x00" + value
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+		line, _ := position(goCode, offset)
+		divergences = append(divergences, Divergence{Kind: kind, Value: value, Line: line})
+	}
+
+	for _, m := range urlPattern.FindAllStringIndex(goCode, -1) {
+		u, err := url.Parse(goCode[m[0]:m[1]])
+		if err != nil || u.Hostname() == "" {
+			continue
+		}
+		if host := strings.ToLower(u.Hostname()); !strings.Contains(lowerScript, host) {
+			add(DivergenceEndpoint, host, m[0])
+		}
+	}
+
+	for _, m := range hostPortPattern.FindAllStringSubmatchIndex(goCode, -1) {
+		if host := strings.ToLower(goCode[m[2]:m[3]]); !strings.Contains(lowerScript, host) {
+			add(DivergenceEndpoint, host, m[0])
+		}
+	}
+
+	for _, m := range commandPattern.FindAllStringSubmatchIndex(goCode, -1) {
+		command := goCode[m[2]:m[3]]
+		name := command[strings.LastIndex(command, "/")+1:]
+		if !regexp.MustCompile(`(^|[^\w.-])` + regexp.QuoteMeta(name) + `($|[^\w.-])`).MatchString(script) {
+			add(DivergenceCommand, command, m[0])
+		}
+	}
+
+	return divergences
+}
@@ -0,0 +1,134 @@
+package injection
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Mode is how suspected prompt injection in a script is handled
+type Mode string
+
+const (
+	// ModeWarn sends the script and reports the findings
+	ModeWarn Mode = "warn"
+	// ModeBlock stops before the script is sent
+	ModeBlock Mode = "block"
+)
+
+// ValidModes returns all valid modes
+func ValidModes() []Mode {
+	return []Mode{ModeWarn, ModeBlock}
+}
+
+// IsValid checks if the mode is valid
+func (m Mode) IsValid() bool {
+	for _, valid := range ValidModes() {
+		if m == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Rule detects one kind of text that could steer the LLM
+type Rule struct {
+	ID          string
+	Description string
+	Pattern     *regexp.Regexp
+}
+
+// Finding is suspicious text found in a script
+type Finding struct {
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	RuleID      string `json:"rule"`
+	Description string `json:"description"`
+	Match       string `json:"match"`
+
+	// value is the matched text, before quoting and truncation
+	value string
+}
+
+// Value returns the matched text
+func (f Finding) Value() string {
+	return f.value
+}
+
+// maxMatchLength is the longest match reported before it is truncated
+const maxMatchLength = 60
+
+var rules = []Rule{
+	{ID: "fence-break", Description: "Markdown code fence", Pattern: regexp.MustCompile("```+|~~~+")},
+	{ID: "delimiter-spoof", Description: "Prompt delimiter", Pattern: regexp.MustCompile(`\b(?:BEGIN|END) GOPHERSCRIPT-`)},
+	{ID: "ignore-instructions", Description: "Instruction override", Pattern: regexp.MustCompile(`(?i)\b(?:ignore|disregard|forget|override)\s+(?:all\s+|any\s+)?(?:of\s+)?(?:the\s+|your\s+)?(?:previous|prior|above|earlier|preceding|system|original)\s+(?:instructions?|prompts?|rules|directions|requirements)`)},
+	{ID: "output-override", Description: "Output override", Pattern: regexp.MustCompile(`(?i)\b(?:output|return|respond\s+with|emit|generate)\s+(?:only\s+)?(?:this|the\s+following)\s+(?:go\s+)?code\s+instead\b`)},
+	{ID: "role-override", Description: "Role override", Pattern: regexp.MustCompile(`(?i)\byou\s+are\s+now\s+(?:an?\s+)?\w+|\bnew\s+instructions\s*:|\bsystem\s+prompt\b`)},
+	{ID: "prompt-markup", Description: "Chat markup token", Pattern: regexp.MustCompile(`<\|(?:im_start|im_end|system|user|assistant|endoftext)\|>|\[/?INST\]|<</?SYS>>`)},
+	{ID: "hidden-text", Description: "Invisible or bidirectional control character", Pattern: regexp.MustCompile(`[\x{200B}-\x{200F}\x{202A}-\x{202E}\x{2060}-\x{2064}\x{2066}-\x{2069}\x{FEFF}]`)},
+}
+
+// Detect returns the text in content that could break out of the prompt or
+// steer the LLM, ordered by position
+func Detect(path, content string) []Finding {
+	var findings []Finding
+	for _, rule := range rules {
+		for _, m := range rule.Pattern.FindAllStringIndex(content, -1) {
+			f := Finding{
+				File:        path,
+				RuleID:      rule.ID,
+				Description: rule.Description,
+				Match:       quote(content[m[0]:m[1]]),
+				value:       content[m[0]:m[1]],
+			}
+			f.Line, f.Column = position(content, m[0])
+			findings = append(findings, f)
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Column < findings[j].Column
+	})
+
+	return findings
+}
+
+// position returns the 1-based line and column of offset in content
+func position(content string, offset int) (int, int) {
+	line := strings.Count(content[:offset], "\n") + 1
+	column := offset - strings.LastIndex(content[:offset], "\n")
+	return line, column
+}
+
+// quote makes a match printable and shortens long matches
+func quote(match string) string {
+	if len(match) > maxMatchLength {
+		match = match[:maxMatchLength] + "..."
+	}
+	return strings.Trim(fmt.Sprintf("%+q", match), `"`)
+}
+
+// BlockedError is returned when suspected prompt injection stops a transpilation
+type BlockedError struct {
+	Findings []Finding
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("%d possible prompt injection(s) in script; pass --injection warn to send it anyway", len(e.Findings))
+}
+
+// WriteText writes findings in a compiler-like "file:line:column" format
+func WriteText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s: %s (%s)\n", f.File, f.Line, f.Column, f.RuleID, f.Description, f.Match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+package llm
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Audit outcomes recorded in AuditEntry.Outcome
+const (
+	AuditOutcomeSuccess        = "success"
+	AuditOutcomeError          = "error"
+	AuditOutcomeBatchSubmitted = "batch_submitted"
+)
+
+// AuditEntry is a single record of source code sent to an LLM provider
+type AuditEntry struct {
+	Timestamp    time.Time `json:"timestamp"`
+	User         string    `json:"user"`
+	InputPath    string    `json:"input_path,omitempty"`
+	InputHash    string    `json:"input_hash,omitempty"`
+	Provider     string    `json:"provider"`
+	Model        string    `json:"model"`
+	Prompt       string    `json:"prompt,omitempty"`
+	PromptHash   string    `json:"prompt_hash"`
+	ResponseHash string    `json:"response_hash,omitempty"`
+	InputTokens  int       `json:"input_tokens,omitempty"`
+	OutputTokens int       `json:"output_tokens,omitempty"`
+	DurationMS   int64     `json:"duration_ms,omitempty"`
+	BatchID      string    `json:"batch_id,omitempty"`
+	Outcome      string    `json:"outcome"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// AuditSource identifies the script an LLM exchange was made for
+type AuditSource struct {
+	Path string
+	Hash string
+}
+
+// AuditLog is an append-only JSONL log of LLM exchanges
+type AuditLog struct {
+	path          string
+	includePrompt bool
+	mu            sync.Mutex
+}
+
+// NewAuditLog creates an AuditLog writing to path. When includePrompt is
+// false only the prompt hash is recorded.
+func NewAuditLog(path string, includePrompt bool) *AuditLog {
+	return &AuditLog{
+		path:          path,
+		includePrompt: includePrompt,
+	}
+}
+
+// Append writes an entry to the end of the log, filling in the timestamp,
+// user and prompt fields according to the log configuration
+func (a *AuditLog) Append(entry AuditEntry) error {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now().UTC()
+	}
+	if entry.User == "" {
+		entry.User = currentUser()
+	}
+	if entry.PromptHash == "" && entry.Prompt != "" {
+		entry.PromptHash = Hash(entry.Prompt)
+	}
+	if !a.includePrompt {
+		entry.Prompt = ""
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to marshal audit entry: %w", err)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(a.path), 0700); err != nil {
+		return fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to write audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// WithAudit records every LLM exchange made for source to the audit log.
+// Token counts are taken from usage when the underlying client reports them.
+// A failure to write the audit log fails the call, so no exchange goes unrecorded.
+func WithAudit(log *AuditLog, provider Provider, model string, source AuditSource, usage UsageReporter) Middleware {
+	return func(next Clienter) Clienter {
+		return ClienterFunc(func(prompt string) (string, error) {
+			start := time.Now()
+			result, err := next.Generate(prompt)
+
+			entry := AuditEntry{
+				InputPath:  source.Path,
+				InputHash:  source.Hash,
+				Provider:   string(provider),
+				Model:      model,
+				Prompt:     prompt,
+				DurationMS: time.Since(start).Milliseconds(),
+				Outcome:    AuditOutcomeSuccess,
+			}
+			if err != nil {
+				entry.Outcome = AuditOutcomeError
+				entry.Error = err.Error()
+			} else {
+				entry.ResponseHash = Hash(result)
+				if usage != nil {
+					u := usage.LastUsage()
+					entry.InputTokens = u.InputTokens
+					entry.OutputTokens = u.OutputTokens
+				}
+			}
+
+			if auditErr := log.Append(entry); auditErr != nil {
+				return "", auditErr
+			}
+
+			return result, err
+		})
+	}
+}
+
+// AuditFilter selects audit entries. Zero-valued fields match everything.
+type AuditFilter struct {
+	Since     time.Time
+	Provider  string
+	InputPath string
+	Outcome   string
+	User      string
+}
+
+// Match reports whether the entry satisfies the filter
+func (f AuditFilter) Match(e AuditEntry) bool {
+	if !f.Since.IsZero() && e.Timestamp.Before(f.Since) {
+		return false
+	}
+	if f.Provider != "" && e.Provider != f.Provider {
+		return false
+	}
+	if f.InputPath != "" && e.InputPath != f.InputPath {
+		return false
+	}
+	if f.Outcome != "" && e.Outcome != f.Outcome {
+		return false
+	}
+	if f.User != "" && e.User != f.User {
+		return false
+	}
+	return true
+}
+
+// ReadAuditLog returns the entries of the audit log at path that match the filter
+func ReadAuditLog(path string, filter AuditFilter) ([]AuditEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []AuditEntry
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+
+		var entry AuditEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("failed to parse audit log line %d: %w", lineNum, err)
+		}
+
+		if filter.Match(entry) {
+			entries = append(entries, entry)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+
+	return entries, nil
+}
+
+// Hash returns the SHA-256 digest of s in "sha256:<hex>" form
+func Hash(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// currentUser returns the name of the user running GopherScript
+func currentUser() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	return os.Getenv("USER")
+}
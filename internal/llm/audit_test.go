@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fixedUsage Usage
+
+func (u fixedUsage) LastUsage() Usage { return Usage(u) }
+
+func TestWithAudit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "log.jsonl")
+	log := NewAuditLog(path, false)
+
+	base := ClienterFunc(func(prompt string) (string, error) {
+		if prompt == "fail" {
+			return "", errors.New("boom")
+		}
+		return "package main", nil
+	})
+
+	source := AuditSource{Path: "deploy.sh", Hash: Hash("echo hi")}
+	client := WithAudit(log, ProviderOpenAI, "gpt-4o", source, fixedUsage{InputTokens: 10, OutputTokens: 20})(base)
+
+	if _, err := client.Generate("convert this"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if _, err := client.Generate("fail"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	entries, err := ReadAuditLog(path, AuditFilter{})
+	if err != nil {
+		t.Fatalf("ReadAuditLog failed: %v", err)
+	}
+
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	ok := entries[0]
+	if ok.Outcome != AuditOutcomeSuccess || ok.InputPath != "deploy.sh" || ok.Model != "gpt-4o" {
+		t.Errorf("unexpected success entry: %+v", ok)
+	}
+	if ok.Prompt != "" || ok.PromptHash != Hash("convert this") {
+		t.Errorf("Expected only the prompt hash to be recorded: %+v", ok)
+	}
+	if ok.ResponseHash != Hash("package main") || ok.InputTokens != 10 || ok.OutputTokens != 20 {
+		t.Errorf("unexpected response fields: %+v", ok)
+	}
+
+	if entries[1].Outcome != AuditOutcomeError || entries[1].Error != "boom" {
+		t.Errorf("unexpected error entry: %+v", entries[1])
+	}
+}
+
+func TestAuditLog_FullPrompt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	log := NewAuditLog(path, true)
+
+	if err := log.Append(AuditEntry{Provider: "claude", Prompt: "secret script", Outcome: AuditOutcomeSuccess}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read log: %v", err)
+	}
+
+	if !strings.Contains(string(content), "secret script") {
+		t.Error("Expected full prompt to be recorded")
+	}
+}
+
+func TestReadAuditLog_Filter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	log := NewAuditLog(path, false)
+
+	old := time.Now().Add(-48 * time.Hour)
+	log.Append(AuditEntry{Timestamp: old, Provider: "openai", Outcome: AuditOutcomeSuccess})
+	log.Append(AuditEntry{Provider: "openai", Outcome: AuditOutcomeError})
+	log.Append(AuditEntry{Provider: "claude", Outcome: AuditOutcomeSuccess})
+
+	entries, err := ReadAuditLog(path, AuditFilter{Since: time.Now().Add(-time.Hour), Provider: "openai"})
+	if err != nil {
+		t.Fatalf("ReadAuditLog failed: %v", err)
+	}
+
+	if len(entries) != 1 || entries[0].Outcome != AuditOutcomeError {
+		t.Errorf("unexpected entries: %+v", entries)
+	}
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	defaultAzureOpenAIAPIVersion = "2024-10-21"
+)
+
+// AzureOpenAIClient implements Clienter for Azure OpenAI Service.
+// Azure scopes requests to a deployment instead of a model name and
+// authenticates with an api-key header rather than a Bearer token.
+type AzureOpenAIClient struct {
+	apiKey     string
+	endpoint   string
+	deployment string
+	apiVersion string
+	httpClient *http.Client
+	logger     *zap.Logger
+	usageTracker
+}
+
+// NewAzureOpenAIClient creates a new Azure OpenAI API client
+func NewAzureOpenAIClient(apiKey, endpoint, deployment, apiVersion string, logger *zap.Logger) *AzureOpenAIClient {
+	if apiVersion == "" {
+		apiVersion = defaultAzureOpenAIAPIVersion
+	}
+
+	return &AzureOpenAIClient{
+		apiKey:     apiKey,
+		endpoint:   strings.TrimRight(endpoint, "/"),
+		deployment: deployment,
+		apiVersion: apiVersion,
+		httpClient: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+		logger: logger,
+	}
+}
+
+// chatCompletionsURL returns the deployment-scoped chat completions URL
+func (c *AzureOpenAIClient) chatCompletionsURL() string {
+	return fmt.Sprintf("%s/openai/deployments/%s/chat/completions?api-version=%s",
+		c.endpoint, url.PathEscape(c.deployment), url.QueryEscape(c.apiVersion))
+}
+
+// Generate sends a prompt to Azure OpenAI and returns the response
+func (c *AzureOpenAIClient) Generate(prompt string) (string, error) {
+	c.reset()
+	c.logger.Debug("Sending request to Azure OpenAI API", zap.String("deployment", c.deployment))
+
+	// The deployment already pins the model, so the model field is left empty
+	reqBody := OpenAIChatRequest{
+		Messages: []OpenAIChatMessage{
+			{
+				Role:    "user",
+				Content: prompt,
+			},
+		},
+	}
+
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.chatCompletionsURL(), bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("api-key", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	result, usage, err := parseOpenAIChatResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	c.record(usage)
+	c.logger.Debug("Received response from Azure OpenAI API", zap.Int("length", len(result)))
+
+	return result, nil
+}
@@ -0,0 +1,106 @@
+package llm
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestAzureOpenAIClient_Generate(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/gpt-4o-prod/chat/completions" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("api-version"); got != "2024-06-01" {
+			t.Errorf("unexpected api-version: %s", got)
+		}
+		if got := r.Header.Get("api-key"); got != "secret" {
+			t.Errorf("unexpected api-key header: %s", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "" {
+			t.Errorf("Authorization header should not be set, got %s", got)
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var req OpenAIChatRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if len(req.Messages) != 1 || req.Messages[0].Content != "hello" {
+			t.Errorf("unexpected messages: %+v", req.Messages)
+		}
+
+		w.Write([]byte(`{"id":"1","choices":[{"message":{"role":"assistant","content":"package main"}}]}`))
+	}))
+	defer server.Close()
+
+	client := NewAzureOpenAIClient("secret", server.URL+"/", "gpt-4o-prod", "2024-06-01", zap.NewNop())
+
+	result, err := client.Generate("hello")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if result != "package main" {
+		t.Errorf("unexpected result: %s", result)
+	}
+}
+
+func TestAzureOpenAIClient_Generate_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"error":{"code":"DeploymentNotFound","message":"The API deployment for this resource does not exist."}}`))
+	}))
+	defer server.Close()
+
+	client := NewAzureOpenAIClient("secret", server.URL, "missing", "", zap.NewNop())
+
+	if _, err := client.Generate("hello"); err == nil {
+		t.Error("Expected error for missing deployment, got nil")
+	}
+}
+
+func TestNewClient_AzureOpenAIRequiresDeployment(t *testing.T) {
+	_, err := NewClient(ClientConfig{
+		Provider:      ProviderAzureOpenAI,
+		APIKey:        "secret",
+		AzureEndpoint: "https://example.openai.azure.com",
+	}, zap.NewNop())
+	if err == nil {
+		t.Error("Expected error when deployment is missing, got nil")
+	}
+}
+
+func TestAzureOpenAIClient_Generate_ResetsUsage(t *testing.T) {
+	fail := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":{"code":"InternalError","message":"boom"}}`))
+			return
+		}
+		w.Write([]byte(`{"id":"1","choices":[{"message":{"role":"assistant","content":"package main"}}],"usage":{"prompt_tokens":12,"completion_tokens":34}}`))
+	}))
+	defer server.Close()
+
+	client := NewAzureOpenAIClient("secret", server.URL, "gpt-4o-prod", "", zap.NewNop())
+
+	if _, err := client.Generate("hello"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+	if got := client.LastUsage(); got != (Usage{InputTokens: 12, OutputTokens: 34}) {
+		t.Errorf("unexpected usage: %+v", got)
+	}
+
+	fail = true
+	if _, err := client.Generate("hello"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if got := client.LastUsage(); got != (Usage{}) {
+		t.Errorf("Usage of a failed request should be empty, got %+v", got)
+	}
+}
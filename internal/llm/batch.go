@@ -0,0 +1,30 @@
+package llm
+
+// BatchRequest is a single prompt submitted as part of a batch job
+type BatchRequest struct {
+	CustomID string
+	Prompt   string
+}
+
+// BatchResult is the outcome of a single request in a finished batch job
+type BatchResult struct {
+	CustomID string
+	Text     string
+	Error    string
+}
+
+// BatchStatus describes the processing state of a batch job
+type BatchStatus struct {
+	ID    string
+	State string
+	Done  bool
+}
+
+// BatchClienter is implemented by clients whose provider offers an
+// asynchronous batch API. Batch jobs are typically billed at a discount
+// and complete within 24 hours.
+type BatchClienter interface {
+	SubmitBatch(requests []BatchRequest) (string, error)
+	BatchStatus(batchID string) (*BatchStatus, error)
+	BatchResults(batchID string) ([]BatchResult, error)
+}
@@ -0,0 +1,135 @@
+package llm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestOpenAIClient_SubmitBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("missing bearer token")
+		}
+
+		switch r.URL.Path {
+		case "/files":
+			if err := r.ParseMultipartForm(1 << 20); err != nil {
+				t.Fatalf("failed to parse upload: %v", err)
+			}
+			if r.FormValue("purpose") != "batch" {
+				t.Errorf("unexpected purpose: %s", r.FormValue("purpose"))
+			}
+			w.Write([]byte(`{"id":"file-1"}`))
+		case "/batches":
+			w.Write([]byte(`{"id":"batch_1","status":"validating"}`))
+		default:
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("secret", zap.NewNop())
+	client.baseURL = server.URL
+
+	id, err := client.SubmitBatch([]BatchRequest{{CustomID: "script-0001", Prompt: "convert"}})
+	if err != nil {
+		t.Fatalf("SubmitBatch failed: %v", err)
+	}
+
+	if id != "batch_1" {
+		t.Errorf("unexpected batch ID: %s", id)
+	}
+}
+
+func TestParseOpenAIBatchOutput(t *testing.T) {
+	output := strings.Join([]string{
+		`{"custom_id":"script-0001","response":{"status_code":200,"body":{"choices":[{"message":{"role":"assistant","content":"package main"}}]}},"error":null}`,
+		`{"custom_id":"script-0002","response":null,"error":{"code":"rate_limit","message":"too many requests"}}`,
+	}, "\n")
+
+	results, err := parseOpenAIBatchOutput([]byte(output))
+	if err != nil {
+		t.Fatalf("parseOpenAIBatchOutput failed: %v", err)
+	}
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results, got %d", len(results))
+	}
+
+	if results[0].Text != "package main" || results[0].Error != "" {
+		t.Errorf("unexpected first result: %+v", results[0])
+	}
+
+	if results[1].Error != "too many requests" {
+		t.Errorf("unexpected second result: %+v", results[1])
+	}
+}
+
+func TestParseClaudeBatchResults(t *testing.T) {
+	output := strings.Join([]string{
+		`{"custom_id":"script-0001","result":{"type":"succeeded","message":{"content":[{"type":"text","text":"package main"}]}}}`,
+		`{"custom_id":"script-0002","result":{"type":"errored","error":{"type":"error","error":{"type":"invalid_request_error","message":"bad request"}}}}`,
+		`{"custom_id":"script-0003","result":{"type":"expired"}}`,
+	}, "\n")
+
+	results, err := parseClaudeBatchResults([]byte(output))
+	if err != nil {
+		t.Fatalf("parseClaudeBatchResults failed: %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("expected 3 results, got %d", len(results))
+	}
+
+	if results[0].Text != "package main" {
+		t.Errorf("unexpected first result: %+v", results[0])
+	}
+
+	if results[1].Error != "bad request" {
+		t.Errorf("unexpected second result: %+v", results[1])
+	}
+
+	if results[2].Error != "request expired" {
+		t.Errorf("unexpected third result: %+v", results[2])
+	}
+}
+
+func TestBatchStatus_NonSuccessStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(`upstream unavailable`))
+	}))
+	defer server.Close()
+
+	openai := NewOpenAIClient("secret", zap.NewNop())
+	openai.baseURL = server.URL
+	claude := NewClaudeClient("secret", zap.NewNop())
+	claude.baseURL = server.URL
+
+	for name, client := range map[string]BatchClienter{"openai": openai, "claude": claude} {
+		_, err := client.BatchStatus("batch_abc123")
+		if err == nil || !strings.Contains(err.Error(), "502") || !strings.Contains(err.Error(), "upstream unavailable") {
+			t.Errorf("%s: BatchStatus() error = %v, want the status and body", name, err)
+		}
+	}
+}
+
+func TestOpenAIClient_BatchResults_FailedBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"id":"batch_1","status":"failed","output_file_id":null,"error_file_id":null,` +
+			`"errors":{"object":"list","data":[{"code":"invalid_json_line","message":"This line is not parseable as valid JSON.","line":1}]}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("secret", zap.NewNop())
+	client.baseURL = server.URL
+
+	results, err := client.BatchResults("batch_1")
+	if err == nil || !strings.Contains(err.Error(), "invalid_json_line") || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("BatchResults() = %v, %v, want the batch errors", results, err)
+	}
+}
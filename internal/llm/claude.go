@@ -12,21 +12,25 @@ import (
 )
 
 const (
-	claudeAPIURL = "https://api.anthropic.com/v1/messages"
-	claudeModel  = "claude-sonnet-4-20250514"
+	claudeBaseURL    = "https://api.anthropic.com/v1"
+	claudeModel      = "claude-sonnet-4-20250514"
+	claudeAPIVersion = "2023-06-01"
 )
 
 // ClaudeClient implements Clienter for Anthropic Claude API
 type ClaudeClient struct {
 	apiKey     string
+	baseURL    string
 	httpClient *http.Client
 	logger     *zap.Logger
+	usageTracker
 }
 
 // NewClaudeClient creates a new Anthropic Claude API client
 func NewClaudeClient(apiKey string, logger *zap.Logger) *ClaudeClient {
 	return &ClaudeClient{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		baseURL: claudeBaseURL,
 		httpClient: &http.Client{
 			Timeout: 120 * time.Second,
 		},
@@ -52,9 +56,16 @@ type ClaudeResponse struct {
 	ID      string               `json:"id"`
 	Type    string               `json:"type"`
 	Content []ClaudeContentBlock `json:"content"`
+	Usage   *ClaudeUsage         `json:"usage,omitempty"`
 	Error   *ClaudeError         `json:"error,omitempty"`
 }
 
+// ClaudeUsage represents the token usage of a request
+type ClaudeUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
 // ClaudeContentBlock represents a content block in the response
 type ClaudeContentBlock struct {
 	Type string `json:"type"`
@@ -69,6 +80,7 @@ type ClaudeError struct {
 
 // Generate sends a prompt to Claude API and returns the response
 func (c *ClaudeClient) Generate(prompt string) (string, error) {
+	c.reset()
 	c.logger.Debug("Sending request to Claude API")
 
 	reqBody := ClaudeRequest{
@@ -87,14 +99,13 @@ func (c *ClaudeClient) Generate(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, claudeAPIURL, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/messages", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("x-api-key", c.apiKey)
-	req.Header.Set("anthropic-version", "2023-06-01")
+	c.setHeaders(req)
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
@@ -112,17 +123,38 @@ func (c *ClaudeClient) Generate(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
-	if claudeResp.Error != nil {
-		return "", fmt.Errorf("API error [%s]: %s", claudeResp.Error.Type, claudeResp.Error.Message)
+	result, err := claudeResp.text()
+	if err != nil {
+		return "", err
 	}
 
-	if len(claudeResp.Content) == 0 {
+	if claudeResp.Usage != nil {
+		c.record(Usage{InputTokens: claudeResp.Usage.InputTokens, OutputTokens: claudeResp.Usage.OutputTokens})
+	}
+	c.logger.Debug("Received response from Claude API", zap.Int("length", len(result)))
+
+	return result, nil
+}
+
+// setHeaders sets the authentication and versioning headers required by the Claude API
+func (c *ClaudeClient) setHeaders(req *http.Request) {
+	req.Header.Set("x-api-key", c.apiKey)
+	req.Header.Set("anthropic-version", claudeAPIVersion)
+}
+
+// text returns the first text block of a Claude response
+func (r *ClaudeResponse) text() (string, error) {
+	if r.Error != nil {
+		return "", fmt.Errorf("API error [%s]: %s", r.Error.Type, r.Error.Message)
+	}
+
+	if len(r.Content) == 0 {
 		return "", fmt.Errorf("empty response from Claude API")
 	}
 
 	// Find the first text block in the response
 	var result string
-	for _, block := range claudeResp.Content {
+	for _, block := range r.Content {
 		if block.Type == "text" {
 			result = block.Text
 			break
@@ -133,7 +165,5 @@ func (c *ClaudeClient) Generate(prompt string) (string, error) {
 		return "", fmt.Errorf("no text content in Claude API response")
 	}
 
-	c.logger.Debug("Received response from Claude API", zap.Int("length", len(result)))
-
 	return result, nil
 }
@@ -0,0 +1,189 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// claudeBatchRequest is a single request in a Message Batches submission
+type claudeBatchRequest struct {
+	CustomID string        `json:"custom_id"`
+	Params   ClaudeRequest `json:"params"`
+}
+
+// claudeBatch represents a message batch object
+type claudeBatch struct {
+	ID               string       `json:"id"`
+	ProcessingStatus string       `json:"processing_status"`
+	ResultsURL       string       `json:"results_url"`
+	Error            *ClaudeError `json:"error,omitempty"`
+}
+
+// claudeBatchResultLine is a single line of the batch results file
+type claudeBatchResultLine struct {
+	CustomID string `json:"custom_id"`
+	Result   struct {
+		Type    string          `json:"type"`
+		Message *ClaudeResponse `json:"message"`
+		Error   *struct {
+			Error *ClaudeError `json:"error"`
+		} `json:"error"`
+	} `json:"result"`
+}
+
+// SubmitBatch creates a Message Batches job containing all prompts
+func (c *ClaudeClient) SubmitBatch(requests []BatchRequest) (string, error) {
+	c.logger.Debug("Submitting batch to Claude API", zap.Int("requests", len(requests)))
+
+	batchRequests := make([]claudeBatchRequest, len(requests))
+	for i, r := range requests {
+		batchRequests[i] = claudeBatchRequest{
+			CustomID: r.CustomID,
+			Params: ClaudeRequest{
+				Model:     claudeModel,
+				MaxTokens: 8192,
+				Messages:  []ClaudeMessage{{Role: "user", Content: r.Prompt}},
+			},
+		}
+	}
+
+	jsonBody, err := json.Marshal(map[string]any{"requests": batchRequests})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	batch, err := c.batchRequest(http.MethodPost, c.baseURL+"/messages/batches", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", err
+	}
+
+	c.logger.Debug("Created Claude message batch", zap.String("id", batch.ID))
+
+	return batch.ID, nil
+}
+
+// BatchStatus returns the processing state of a message batch
+func (c *ClaudeClient) BatchStatus(batchID string) (*BatchStatus, error) {
+	batch, err := c.batchRequest(http.MethodGet, c.baseURL+"/messages/batches/"+batchID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchStatus{
+		ID:    batch.ID,
+		State: batch.ProcessingStatus,
+		Done:  batch.ProcessingStatus == "ended",
+	}, nil
+}
+
+// BatchResults downloads the results of an ended message batch
+func (c *ClaudeClient) BatchResults(batchID string) ([]BatchResult, error) {
+	batch, err := c.batchRequest(http.MethodGet, c.baseURL+"/messages/batches/"+batchID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if batch.ProcessingStatus != "ended" || batch.ResultsURL == "" {
+		return nil, fmt.Errorf("batch %s is not finished (status: %s)", batchID, batch.ProcessingStatus)
+	}
+
+	body, err := c.do(http.MethodGet, batch.ResultsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return parseClaudeBatchResults(body)
+}
+
+// batchRequest sends a request to the Message Batches API and decodes the batch object
+func (c *ClaudeClient) batchRequest(method, url string, reqBody io.Reader) (*claudeBatch, error) {
+	body, err := c.do(method, url, reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch claudeBatch
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if batch.Error != nil {
+		return nil, fmt.Errorf("API error [%s]: %s", batch.Error.Type, batch.Error.Message)
+	}
+
+	return &batch, nil
+}
+
+// do sends an authenticated request to the Claude API and returns the response body
+func (c *ClaudeClient) do(method, url string, reqBody io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if reqBody != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.setHeaders(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return readResponse(resp)
+}
+
+// parseClaudeBatchResults converts a batch results file into results
+func parseClaudeBatchResults(content []byte) ([]BatchResult, error) {
+	var results []BatchResult
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var line claudeBatchResultLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal batch result line: %w", err)
+		}
+
+		result := BatchResult{CustomID: line.CustomID}
+		switch line.Result.Type {
+		case "succeeded":
+			if line.Result.Message == nil {
+				result.Error = "no message in batch result"
+				break
+			}
+			text, err := line.Result.Message.text()
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Text = text
+			}
+		case "errored":
+			result.Error = "request errored"
+			if line.Result.Error != nil && line.Result.Error.Error != nil {
+				result.Error = line.Result.Error.Error.Message
+			}
+		default:
+			result.Error = "request " + line.Result.Type
+		}
+		results = append(results, result)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch results: %w", err)
+	}
+
+	return results, nil
+}
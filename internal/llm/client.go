@@ -12,7 +12,8 @@ import (
 )
 
 const (
-	geminiAPIURL = "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent"
+	geminiBaseURL = "https://generativelanguage.googleapis.com/v1beta"
+	geminiModel   = "gemini-2.0-flash"
 )
 
 // Clienter defines the interface for LLM clients
@@ -23,14 +24,17 @@ type Clienter interface {
 // GeminiClient implements Clienter for Google Gemini API
 type GeminiClient struct {
 	apiKey     string
+	baseURL    string
 	httpClient *http.Client
 	logger     *zap.Logger
+	usageTracker
 }
 
 // NewGeminiClient creates a new Gemini API client
 func NewGeminiClient(apiKey string, logger *zap.Logger) *GeminiClient {
 	return &GeminiClient{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		baseURL: geminiBaseURL,
 		httpClient: &http.Client{
 			Timeout: 60 * time.Second,
 		},
@@ -55,8 +59,15 @@ type Part struct {
 
 // GeminiResponse represents the response from Gemini API
 type GeminiResponse struct {
-	Candidates []Candidate `json:"candidates"`
-	Error      *APIError   `json:"error,omitempty"`
+	Candidates    []Candidate    `json:"candidates"`
+	UsageMetadata *UsageMetadata `json:"usageMetadata,omitempty"`
+	Error         *APIError      `json:"error,omitempty"`
+}
+
+// UsageMetadata represents the token usage of a request
+type UsageMetadata struct {
+	PromptTokenCount     int `json:"promptTokenCount"`
+	CandidatesTokenCount int `json:"candidatesTokenCount"`
 }
 
 // Candidate represents a candidate response
@@ -73,6 +84,7 @@ type APIError struct {
 
 // Generate sends a prompt to Gemini API and returns the response
 func (c *GeminiClient) Generate(prompt string) (string, error) {
+	c.reset()
 	c.logger.Debug("Sending request to Gemini API")
 
 	reqBody := GeminiRequest{
@@ -90,7 +102,7 @@ func (c *GeminiClient) Generate(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	url := fmt.Sprintf("%s?key=%s", geminiAPIURL, c.apiKey)
+	url := fmt.Sprintf("%s/models/%s:generateContent?key=%s", c.baseURL, geminiModel, c.apiKey)
 	req, err := http.NewRequest(http.MethodPost, url, bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
@@ -123,6 +135,12 @@ func (c *GeminiClient) Generate(prompt string) (string, error) {
 	}
 
 	result := geminiResp.Candidates[0].Content.Parts[0].Text
+	if geminiResp.UsageMetadata != nil {
+		c.record(Usage{
+			InputTokens:  geminiResp.UsageMetadata.PromptTokenCount,
+			OutputTokens: geminiResp.UsageMetadata.CandidatesTokenCount,
+		})
+	}
 	c.logger.Debug("Received response from Gemini API", zap.Int("length", len(result)))
 
 	return result, nil
@@ -0,0 +1,24 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Delimiter returns the marker that encloses untrusted script content in
+// prompts. It is derived from the content itself, so a script cannot
+// contain the marker that closes it, and the same script always gets the
+// same prompt.
+func Delimiter(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	for i := 6; i <= len(sum); i++ {
+		delimiter := "GOPHERSCRIPT-" + strings.ToUpper(hex.EncodeToString(sum[:i]))
+		if !strings.Contains(content, delimiter) {
+			return delimiter
+		}
+	}
+
+	// Unreachable short of a SHA-256 collision
+	return "GOPHERSCRIPT-" + strings.ToUpper(hex.EncodeToString(sum[:]))
+}
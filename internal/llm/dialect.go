@@ -0,0 +1,150 @@
+package llm
+
+// Dialect is the specific language variant of a script, e.g. "bash" or "python3"
+type Dialect string
+
+// rubyGuidance holds the Ruby semantics shared by scripts and Rakefiles
+const rubyGuidance = `- This is a Ruby script. Only nil and false are falsy: 0, "" and empty collections are true in conditions.
+- Blocks passed to each, map, select, reject, each_with_index and inject become loops or small helper functions; File.open and Dir.chdir with a block close the file or restore the directory when the block ends, even on error, so use defer.
+- ARGV is os.Args[1:] and $0 is the program name; ARGV.shift consumes arguments. Map OptionParser switches to flags with the same long and short names, defaults and help text, and keep parse! removing options from ARGV.
+- Backticks and %x() run a command through the shell and return stdout, setting $? ($?.success?, $?.exitstatus); system returns true, false or nil without raising; exec replaces the process. Open3.capture2/capture3 capture stdout and stderr separately.
+- File.read, File.write, File.readlines, File.exist?, File.join, File.basename, File.dirname, File.expand_path and FileUtils.mkdir_p/rm_rf/cp_r map to os and path/filepath; Dir.glob and Dir["**/*.rb"] map to filepath.Glob or filepath.WalkDir for "**".
+- raise and an uncaught exception print the message and backtrace to stderr and exit with status 1; abort("msg") prints msg to stderr and exits with 1; exit(n) and exit! exit with n; rescue/ensure map to error checks and defer.
+- String interpolation "#{x}" calls to_s; puts adds a newline unless the string ends with one and prints arrays one element per line; p prints inspect output.
+- Hashes keep insertion order; preserve the iteration order where the script depends on it instead of ranging over a Go map.
+- Map gems to the standard library: json -> encoding/json, net/http, open-uri, httparty, faraday and rest-client -> net/http, optparse -> flag, fileutils and pathname -> os and path/filepath, tmpdir and tempfile -> os.MkdirTemp/os.CreateTemp, erb -> text/template, logger -> log/slog, time and date -> time, digest -> crypto/md5, crypto/sha1 and crypto/sha256, base64 -> encoding/base64, csv -> encoding/csv, open3 -> os/exec, shellwords -> an explicit argument slice, set -> map[T]struct{}. yaml (Psych) has no standard library equivalent: follow the dependency policy.`
+
+// nodeGuidance holds the Node.js semantics shared by JavaScript and TypeScript
+const nodeGuidance = `- This is a Node.js script. Go code runs synchronously, so an awaited call becomes a plain call that returns (value, error); a rejected promise or thrown error becomes a returned error. Promise.all becomes goroutines joined with a sync.WaitGroup that keep the first error, Promise.race becomes a select on channels, and setTimeout/setInterval become time.AfterFunc/time.Ticker. Keep work sequential where the script awaits each step.
+- process.argv[0] is node and process.argv[1] the script, so process.argv.slice(2) is os.Args[1:]. Map commander, yargs and minimist options to flags with the same names and defaults. process.env.X is undefined when unset (os.LookupEnv), process.exit(n) exits with n, and an uncaught exception or unhandled rejection prints the error to stderr and exits with status 1.
+- console.log writes to stdout with a newline and console.error to stderr; process.stdout.write adds no newline. Objects printed by console.log should be formatted readably (e.g. JSON), not with %v.
+- fs.readFileSync/fs.promises.readFile with "utf8" map to os.ReadFile, writeFileSync/appendFileSync to os.WriteFile/os.OpenFile with O_APPEND, existsSync to os.Stat, mkdirSync({recursive: true}) to os.MkdirAll, readdirSync to os.ReadDir, rmSync({recursive: true}) to os.RemoveAll; createReadStream with readline maps to bufio.Scanner. path.join/resolve/basename/extname map to path/filepath.
+- child_process: execSync and exec run through the shell, return stdout and throw (or call back with an error) on a non-zero exit; execFile, spawn and spawnSync run the program directly unless {shell: true}; stdio: "inherit" connects the child to os.Stdin/Stdout/Stderr.
+- fetch resolves even on HTTP error statuses: check res.ok (a 2xx StatusCode) exactly where the script does, and res.json()/res.text() read the body once. axios, got and node-fetch map to net/http too, but axios rejects on non-2xx statuses.
+- JSON.parse/JSON.stringify map to encoding/json (JSON.stringify(v, null, 2) is MarshalIndent with two spaces). Numbers are float64; 0, "", null, undefined and NaN are falsy; == coerces types while === does not.
+- Map npm packages to the standard library: commander, yargs and minimist -> flag, chalk -> ANSI escape codes, dotenv -> reading the .env file with bufio, glob and fast-glob -> filepath.Glob/filepath.WalkDir, fs-extra, rimraf and mkdirp -> os, lodash -> loops over slices and maps, axios, node-fetch and got -> net/http, dayjs and moment -> time, uuid -> crypto/rand, execa and shelljs -> os/exec, csv-parse -> encoding/csv. Packages without a standard library equivalent (e.g. js-yaml) follow the dependency policy.`
+
+// taskGuidance holds the task runner semantics shared by Makefiles and
+// justfiles, which are converted through a generated bash script
+const taskGuidance = `- This bash script was generated from a task file to show how its tasks run: convert what it does, not its bash plumbing. Turn each task function (target_* or recipe_*) into a subcommand named after the task, with the task file's comments as help text, and run the default task named in the usage comment when none is given. Several tasks may be named in one invocation; run them in order.
+- A task runs its dependencies first, in the order listed, and each task runs at most once per invocation, as make_target and run_recipe do with their set of finished tasks.
+- NAME=value arguments override the task file's variables in place of their assignments, as assign does. Exported variables are set in the environment of the commands the tasks run.
+- Each run_line call is one recipe line run in its own shell, so cd, variables and other shell state do not carry over to the next line. Convert the line like a shell script: echo, printf, mkdir -p, rm -rf, cp, mv, touch, cd and test become Go code, and other programs run with os/exec attached to the terminal's stdin, stdout and stderr. Print the line first unless its flags contain "@", and keep going after a failure when they contain "-".`
+
+// dialectGuidance holds the semantics the generated Go code must preserve
+// for each dialect
+// awkGuidance holds the awk semantics shared by POSIX awk and gawk
+const awkGuidance = `- This is an awk program. It reads the files named on the command line in turn (stdin when there are none, and "-" means stdin) one record at a time and runs every pattern-action rule against each record in order. Stream the input with bufio.Scanner (raise its limit with Scanner.Buffer for long lines) instead of reading it all, and write output through a bufio.Writer flushed before exiting. next skips to the next record; exit runs the END rules and then exits with the given status.
+- BEGIN rules run before any input is read, and a program with only BEGIN rules reads no input. END rules run after the last record, with $0 and the fields still holding it. -v var=value is applied before BEGIN; var=value operands between file names are applied when they are reached.
+- Records are split into fields $1..$NF by FS: the default " " splits on runs of blanks and ignores leading and trailing blanks (strings.Fields), any other single character splits on exactly that character and keeps empty fields (strings.Split), and a longer FS is a regular expression. -F sets FS. Assigning a field or NF rebuilds $0 joined by OFS (default " "); assigning $0 splits it again. NR counts records across all files and FNR within the current one; FILENAME is the current file.
+- A pattern is an expression, a /regex/ matched against $0, or a range /start/,/end/ that is active from a record matching start through the next record matching end, inclusive. A rule without an action prints $0; an action without a pattern runs for every record. awk regexes are EREs, which Go's regexp accepts; ~ and !~ match a value against a regex.
+- Values are strings or numbers by context: uninitialized variables are "" and 0, a string used as a number takes its leading numeric prefix ("3abc" is 3, "abc" is 0), two fields that look numeric compare as numbers, and non-integer numbers convert to strings with CONVFMT/OFMT ("%.6g") while integers print without a decimal point.
+- Arrays are maps from string keys (a[1] and a["1"] are the same element) and SUBSEP joins multiple subscripts. (k in a) tests membership without creating the element while referencing a[k] creates it; for (k in a) has no defined order, so sort keys only where the script does; delete removes elements.
+- print writes its arguments joined by OFS and followed by ORS ("\n"); printf takes C formats, which fmt mostly shares (%c with a number prints that character, %d truncates). print > "file" truncates the file on first use and keeps it open, >> appends, and | "cmd" pipes into a sh -c command kept open until close(); getline reads the next record, or a line from a file or command.
+- Built-ins: length, substr (1-based and clamped to the string), index (1-based, 0 when absent), split (returns the count and fills the array from index 1), sub and gsub (modify the target in place and return the count; & in the replacement is the match), match (sets RSTART and RLENGTH), sprintf, tolower, toupper, int (truncates toward zero), rand/srand and system (runs sh -c and returns the exit status).`
+
+// sedGuidance holds the sed semantics
+const sedGuidance = `- This is a sed script. It reads the files named on the command line in turn (stdin when there are none) one line at a time into the pattern space, runs every command in order on it, and prints the pattern space at the end of each cycle unless -n, or a first script line of "#n", is in effect. Stream the input with bufio.Scanner and write output through a bufio.Writer flushed before exiting; line numbers continue across files.
+- Addresses: a command without an address applies to every line. N selects line N, $ the last line (read one line ahead to know it), /regex/ lines that match, and addr1,addr2 a range active from the line matching addr1 through the next line matching addr2 (a regex addr2 is first tried on the line after addr1; a line number not greater than addr1 selects one line). ! negates an address and { } groups commands under one. GNU sed adds first~step and addr1,+N.
+- s/regex/replacement/flags: regexes are BREs unless -E or -r selects EREs. In a BRE \( \), \{ \}, \+ and \? are the special forms and ( ) { } + ? are literal, so translate them to Go regexp syntax. & in the replacement is the match and \1 to \9 are groups. Flags: g replaces every match, a number N replaces only the Nth, p prints the result when a substitution was made, w file writes it, and I ignores case. Any character can replace the / delimiter.
+- Commands: d deletes the pattern space and starts the next cycle; p prints it; n prints it (unless -n) and reads the next line; N appends a newline and the next line (GNU sed prints the pattern space and exits when there is none); D deletes through the first newline and restarts the cycle without reading input if text remains; P prints through the first newline; h, H, g, G and x copy, append and exchange with the hold space, which starts empty; y/abc/xyz/ transliterates; a, i and c append, insert and change text (appended text is printed at the end of the cycle); = prints the line number; q prints and quits and Q quits without printing, both with an optional exit status; b, t and T branch to a label (t when a substitution succeeded since the last input line or t); r file queues a file's contents and w file writes the pattern space.
+- The exit status is 0 unless q or Q sets one. A missing input file is reported on stderr and skipped, and the exit status becomes 2.`
+
+var dialectGuidance = map[Dialect]string{
+	"sh": `- This is a POSIX sh script: there are no arrays, [[ ]], or "local"; do not assume bash behaviour.
+- Unquoted expansions undergo word splitting on IFS and pathname globbing; quoted expansions are single arguments. Only split or glob (strings.Fields, filepath.Glob) where the script leaves an expansion unquoted.
+- "$@" expands to separate arguments while "$*" joins them with the first character of IFS.
+- Under "set -e" the script exits at the first failing command, except in if/while conditions, && and || lists and commands negated with !. Without "set -e" failures are ignored unless checked.
+- A pipeline's exit status is that of its last command.
+- Preserve exit codes: when a command fails, exit with its status (exec.ExitError.ExitCode()), and end with the status of the last command the script ran.
+- "[ a = b ]" compares strings while "-eq", "-lt" and friends compare integers.`,
+
+	"bash": `- This is a bash script. Unquoted expansions undergo word splitting on IFS and pathname globbing; quoted expansions are single arguments. Only split or glob where the script leaves an expansion unquoted.
+- Map indexed arrays to slices and associative arrays (declare -A) to maps; "${arr[@]}" expands to one argument per element.
+- "set -e" exits at the first failing command, except in conditions, && and || lists and negated commands; "set -u" makes expanding an unset variable a fatal error; "set -o pipefail" makes a pipeline fail when any command in it fails. Honour exactly the options the script sets.
+- Inside [[ ]], "==" and "!=" match glob patterns and "=~" matches an extended regular expression (use regexp with BASH_REMATCH semantics).
+- Reproduce parameter expansions such as ${var:-default}, ${var#prefix}, ${var%suffix} and ${var//a/b} exactly.
+- Implement "trap ... EXIT" with deferred cleanup that also runs on error exits, and "trap ... ERR" as a handler for failing commands.
+- Preserve exit codes: when a command fails, exit with its status (exec.ExitError.ExitCode()), and end with the status of the last command the script ran.`,
+
+	"zsh": `- This is a zsh script. Unlike sh and bash, unquoted parameter expansions are NOT word-split (unless SH_WORD_SPLIT is set), but unquoted globs are expanded.
+- Arrays are 1-indexed: $arr[1] is the first element.
+- A glob that matches nothing is an error (NOMATCH) unless the (N) qualifier is used; "**/" globs recursively. Glob qualifiers like *(.) or *(/) select files or directories.
+- Parameter expansion flags such as ${(s:,:)var}, ${(j:,:)arr} and ${(U)var} split, join and transform values.
+- Honour any setopt/unsetopt options the script changes, including ERR_EXIT (set -e) and PIPE_FAIL.
+- Preserve exit codes: when a command fails, exit with its status (exec.ExitError.ExitCode()), and end with the status of the last command the script ran.`,
+
+	"ksh": `- This is a Korn shell script. Unquoted expansions undergo word splitting and globbing; quoted expansions are single arguments.
+- Arrays are 0-indexed; "typeset" declares variables and their attributes (integers, arrays, upper/lower case).
+- The last command of a pipeline runs in the current shell, so variables set in "... | while read" loops remain visible afterwards.
+- [[ ]] matches glob patterns with == and !=; "print" writes its arguments like echo.
+- "set -e" exits at the first failing command outside conditions and && / || lists; honour "set -o pipefail" if present.
+- Preserve exit codes: when a command fails, exit with its status (exec.ExitError.ExitCode()), and end with the status of the last command the script ran.`,
+
+	"python2": `- This is a Python 2 script. "print x" is a statement and "print x," suppresses the newline.
+- "/" between two integers is floor division; keep integer results where Python 2 produces them.
+- str is a byte string and unicode is text; preserve how the script encodes and decodes data.
+- range() builds a list and xrange() is lazy; dict.keys()/items() return lists; raw_input() reads a line without the newline.
+- An uncaught exception prints a traceback to stderr and exits with status 1; sys.exit(n) exits with n, sys.exit("msg") prints msg to stderr and exits with 1.
+- subprocess.check_call/check_output raise CalledProcessError on a non-zero exit status; propagate those failures as errors.`,
+
+	"python3": `- This is a Python 3 script. "/" is true division and "//" is floor division.
+- str is Unicode text and bytes is binary data; preserve how the script encodes and decodes data.
+- An uncaught exception prints a traceback to stderr and exits with status 1; sys.exit(n) exits with n, sys.exit("msg") prints msg to stderr and exits with 1, and sys.exit() exits with 0.
+- subprocess.run(..., check=True) and check_output raise CalledProcessError on a non-zero exit status, while subprocess.run without check ignores it; propagate failures the same way.
+- Context managers (with) release resources even on error; use defer for the same guarantee.`,
+
+	"ruby": rubyGuidance,
+
+	"rake": rubyGuidance + `
+- This is a Rakefile. Turn each task into a subcommand named after it, running its prerequisites (task :deploy => [:build, :test]) first and each task at most once per run. The default task runs when no subcommand is given; desc strings become the help text.
+- Task arguments (task :release, [:version]) become positional arguments of the subcommand; namespace :db do ... end prefixes task names as "db:migrate".
+- sh "cmd" runs through the shell and aborts the run when the command fails; ruby "file" runs a Ruby script; file and directory tasks only run when the target is missing or older than its prerequisites.`,
+
+	"javascript": nodeGuidance,
+
+	"typescript": nodeGuidance + `
+- This is TypeScript: interfaces and object types become structs with JSON tags matching the property names, union string literal types and enums become typed constants, optional properties (x?: T) become pointers or zero values checked where the script checks for undefined, and generics become Go type parameters. Type-only imports, "as" casts and non-null assertions (!) have no runtime effect.`,
+
+	"awk": awkGuidance,
+
+	"gawk": awkGuidance + `
+- This program uses GNU awk extensions: gensub returns the result instead of modifying the target and supports \1 references, BEGINFILE and ENDFILE run around each file, asort and asorti sort arrays, PROCINFO["sorted_in"] fixes the for-in order, strftime, systime and mktime map to the time package, IGNORECASE makes matching case-insensitive, FPAT and FIELDWIDTHS split fields by content or width, and length(array) counts elements. Implement each the way gawk defines it.`,
+
+	"sed": sedGuidance,
+
+	"make": taskGuidance + `
+- make prints recipe lines to stdout, and a failing line stops the run with status 2 after printing "make: *** [Makefile:LINE: TARGET] Error STATUS" to stderr.
+- A target that is not .PHONY names a file: up_to_date skips its recipe when the file exists and is newer than every prerequisite (compare os.Stat ModTime), and a prerequisite without a rule must exist as a file.
+- Variables assigned with "=" are expanded when used, so compute them after the variables they refer to; "?=" keeps a value from the environment, "+=" appends with a space, and $(shell ...) output has its newlines turned into spaces. make functions kept as written, such as $(patsubst ...), $(wildcard ...) or $(dir ...), are GNU make functions: implement them with strings and path/filepath.`,
+
+	"just": taskGuidance + `
+- just prints recipe lines to stderr, and a failing line stops the run with the line's exit status after printing "error: Recipe 'NAME' failed on line LINE with exit code STATUS".
+- Recipe parameters are the arguments after the recipe name: a recipe takes as many of them as it has parameters, parameters with defaults are optional, and a variadic parameter (+ for one or more, * for zero or more) takes the rest, joined with spaces. A recipe runs again when called with different arguments, and an alias runs the recipe it names.
+- Variables are evaluated once at startup, in order; a backtick runs a command and uses its output without the final newline. A run_script body starting with #! runs as one script: convert bash and sh bodies like shell scripts, and run others through their interpreter.`,
+
+	"powershell": `- This is a PowerShell script. Cmdlets pass .NET objects, not text, through the pipeline: Where-Object, ForEach-Object, Select-Object, Sort-Object, Group-Object and Measure-Object become loops over slices of structs, with $_ / $PSItem as the loop variable. A cmdlet that returns one object returns a scalar, not a one-element array, unless the script wraps it in @(). Format-Table, Format-List and Out-String only format for display; print the selected properties (text/tabwriter for tables).
+- param() blocks become flags with the same names and defaults: [switch] parameters are bool flags, [Parameter(Mandatory)] parameters are required (report a usage error where PowerShell would prompt), [ValidateSet()], [ValidateRange()] and [ValidatePattern()] are checked after parsing, and positional parameters come from flag.Args(). $args holds the unbound arguments.
+- $ErrorActionPreference = 'Stop' turns every cmdlet error into a terminating error; with the default 'Continue' a failing cmdlet writes its error to stderr and the script carries on, and -ErrorAction on a single call overrides the preference. try/catch only catches terminating errors, finally always runs, and throw or an uncaught terminating error exits with status 1. Failing native programs are not errors: $LASTEXITCODE holds their exit code and $? the success of the last command. exit N exits with N.
+- Write-Output and bare expressions write to stdout; Write-Host also writes to stdout; Write-Error and Write-Warning write to stderr and Write-Verbose/Write-Debug only with -Verbose/-Debug. Every uncaptured expression in a function is part of its return value.
+- -eq, -ne, -lt, -like, -match, -contains and -in compare case-insensitively unless written -ceq, -clike, -cmatch, ...; -like uses wildcards (path.Match) and -match uses .NET regular expressions and sets $Matches. A comparison with an array on the left filters the array. $null, 0, "" and empty arrays are falsy, while the strings "0" and "False" are truthy. Double-quoted strings and here-strings (@" "@) expand $var and $(...); single-quoted strings are literal.
+- File cmdlets: Get-Content returns lines (-Raw the whole text) -> os.ReadFile, Set-Content, Out-File and Add-Content -> os.WriteFile or os.OpenFile with O_APPEND (write UTF-8 without a BOM), Get-ChildItem -Recurse -Filter -> filepath.WalkDir, Test-Path -> os.Stat, New-Item -ItemType Directory -> os.MkdirAll, Remove-Item -Recurse -Force -> os.RemoveAll, Copy-Item and Move-Item -> io.Copy and os.Rename, Join-Path and Split-Path -> path/filepath. Build paths with filepath instead of backslashes so they work on every OS; the provider paths Env:, HKLM: and Cert: are not files.
+- Invoke-RestMethod -> net/http plus encoding/json: it parses JSON responses into objects and throws on non-2xx statuses; Invoke-WebRequest returns the raw response. ConvertFrom-Json and ConvertTo-Json -> encoding/json (ConvertTo-Json truncates below -Depth, 2 by default). Import-Csv and Export-Csv -> encoding/csv. The call operator & and Start-Process -> os/exec; Start-Sleep -> time.Sleep; Get-Date -> time.Now with .NET format strings translated ("yyyy-MM-dd HH:mm" is "2006-01-02 15:04"); $env:NAME -> os.Getenv.
+- Cmdlets without a portable equivalent (WMI/CIM, the registry, Windows services, event logs, Active Directory, ACLs, COM objects, scheduled tasks) must not be dropped silently: run them only when runtime.GOOS == "windows", or return an error saying the operation is only supported on Windows.`,
+
+	"perl": `- This is a Perl 5 script. Go's regexp (RE2) has no backreferences or lookaround: rewrite such patterns with extra code instead of dropping them. =~ m// sets $1, $2, ... (use FindStringSubmatch), s///g maps to ReplaceAllString with ${1} references, tr/// maps to strings.Map, and the /i, /m, /s modifiers map to (?i), (?m), (?s) flags (strip whitespace and comments for /x).
+- open modes: "<" reads, ">" truncates or creates, ">>" appends, "+<" reads and writes, and "-|" / "|-" read from or write to a command (use exec.Command with StdoutPipe or StdinPipe). Two-argument open takes the mode from the start of the file name. Check every open the way the script does with "or die".
+- <$fh> and <STDIN> return lines including the newline until chomp removes it; while (<>) reads the files named in @ARGV in turn, or stdin when @ARGV is empty, and $. is the line number.
+- @ARGV is os.Args[1:] and $0 is the program name; shift and pop outside a sub operate on @ARGV. Map Getopt::Long and Getopt::Std options to flags with the same names and defaults.
+- die prints its message to stderr, appending " at FILE line N." unless it ends with a newline, and exits with status 255 (or the failing $! / $? >> 8 when non-zero); warn prints to stderr and continues; eval { ... } catches a die and sets $@.
+- Scalars convert between strings and numbers by context: eq, ne, lt and cmp compare strings while ==, != and <=> compare numbers, and undef is "" or 0. Treat non-numeric strings used as numbers as 0, as Perl does.
+- system() returns the wait status ($? >> 8 is the exit code) without dying; backticks and qx// capture stdout. Run commands without a shell unless the script passes a single string containing shell syntax.
+- Hash iteration order is random; sort keys where the script does (sort keys %h, sort { $a <=> $b }).
+- Map CPAN modules to the standard library: File::Find -> filepath.WalkDir, File::Basename -> filepath.Base/Dir/Ext, File::Path -> os.MkdirAll/os.RemoveAll, File::Copy -> io.Copy, File::Temp -> os.CreateTemp/os.MkdirTemp, Cwd -> os.Getwd, JSON and JSON::PP -> encoding/json, HTTP::Tiny and LWP::UserAgent -> net/http, Text::CSV -> encoding/csv, POSIX strftime -> time.Format, Time::Local -> time.Date, Digest::MD5 and Digest::SHA -> crypto/md5 and crypto/sha256, MIME::Base64 -> encoding/base64, Data::Dumper -> fmt.Printf("%#v"), Carp croak/confess -> returned errors.`,
+}
+
+// DialectGuidance returns the semantics to preserve when converting a
+// script of the given dialect, or "" for an unknown dialect
+func DialectGuidance(d Dialect) string {
+	return dialectGuidance[d]
+}
@@ -0,0 +1,163 @@
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Middleware wraps a Clienter to add behavior around LLM calls, such as
+// logging, metrics, caching or request tagging
+type Middleware func(Clienter) Clienter
+
+// ClienterFunc adapts an ordinary function to the Clienter interface
+type ClienterFunc func(prompt string) (string, error)
+
+// Generate calls f(prompt)
+func (f ClienterFunc) Generate(prompt string) (string, error) {
+	return f(prompt)
+}
+
+// Chain wraps client with the given middlewares. The first middleware is
+// the outermost one, so it sees each call first and each result last.
+func Chain(client Clienter, middlewares ...Middleware) Clienter {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		client = middlewares[i](client)
+	}
+	return client
+}
+
+// WithLogging logs every LLM call with its prompt size, duration and outcome
+func WithLogging(logger *zap.Logger) Middleware {
+	return func(next Clienter) Clienter {
+		return ClienterFunc(func(prompt string) (string, error) {
+			start := time.Now()
+			result, err := next.Generate(prompt)
+
+			fields := []zap.Field{
+				zap.Int("promptLength", len(prompt)),
+				zap.Int("resultLength", len(result)),
+				zap.Duration("duration", time.Since(start)),
+			}
+			if err != nil {
+				logger.Warn("LLM call failed", append(fields, zap.Error(err))...)
+			} else {
+				logger.Info("LLM call completed", fields...)
+			}
+
+			return result, err
+		})
+	}
+}
+
+// WithTiming reports the duration and outcome of every LLM call to observe,
+// which makes it easy to feed a metrics system
+func WithTiming(observe func(duration time.Duration, err error)) Middleware {
+	return func(next Clienter) Clienter {
+		return ClienterFunc(func(prompt string) (string, error) {
+			start := time.Now()
+			result, err := next.Generate(prompt)
+			observe(time.Since(start), err)
+			return result, err
+		})
+	}
+}
+
+// WithRetry retries failed LLM calls up to retries more times, doubling the
+// wait after each attempt starting from backoff
+func WithRetry(retries int, backoff time.Duration) Middleware {
+	return func(next Clienter) Clienter {
+		return ClienterFunc(func(prompt string) (string, error) {
+			wait := backoff
+			result, err := next.Generate(prompt)
+			for attempt := 0; err != nil && attempt < retries; attempt++ {
+				time.Sleep(wait)
+				wait *= 2
+				result, err = next.Generate(prompt)
+			}
+			return result, err
+		})
+	}
+}
+
+// Cache stores LLM responses keyed by prompt hash
+type Cache interface {
+	Get(key string) (string, bool)
+	Set(key string, value string)
+}
+
+// MemoryCache is an in-process Cache safe for concurrent use
+type MemoryCache struct {
+	mu      sync.RWMutex
+	entries map[string]string
+}
+
+// NewMemoryCache creates a new empty MemoryCache
+func NewMemoryCache() *MemoryCache {
+	return &MemoryCache{
+		entries: make(map[string]string),
+	}
+}
+
+// Get returns the cached value for key
+func (c *MemoryCache) Get(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	value, ok := c.entries[key]
+	return value, ok
+}
+
+// Set stores value under key
+func (c *MemoryCache) Set(key string, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entries[key] = value
+}
+
+// WithCache answers repeated prompts from cache. Only successful responses are cached.
+func WithCache(cache Cache) Middleware {
+	return func(next Clienter) Clienter {
+		return ClienterFunc(func(prompt string) (string, error) {
+			sum := sha256.Sum256([]byte(prompt))
+			key := hex.EncodeToString(sum[:])
+
+			if result, ok := cache.Get(key); ok {
+				return result, nil
+			}
+
+			result, err := next.Generate(prompt)
+			if err != nil {
+				return "", err
+			}
+
+			cache.Set(key, result)
+			return result, nil
+		})
+	}
+}
+
+// WithRateLimit spaces LLM calls at least interval apart
+func WithRateLimit(interval time.Duration) Middleware {
+	return func(next Clienter) Clienter {
+		var (
+			mu   sync.Mutex
+			last time.Time
+		)
+
+		return ClienterFunc(func(prompt string) (string, error) {
+			mu.Lock()
+			if wait := interval - time.Since(last); !last.IsZero() && wait > 0 {
+				time.Sleep(wait)
+			}
+			last = time.Now()
+			mu.Unlock()
+
+			return next.Generate(prompt)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package llm
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func TestChain_Order(t *testing.T) {
+	var calls []string
+	tag := func(name string) Middleware {
+		return func(next Clienter) Clienter {
+			return ClienterFunc(func(prompt string) (string, error) {
+				calls = append(calls, name)
+				return next.Generate(prompt + name)
+			})
+		}
+	}
+
+	base := ClienterFunc(func(prompt string) (string, error) {
+		return prompt, nil
+	})
+
+	result, err := Chain(base, tag("a"), tag("b")).Generate(">")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if result != ">ab" {
+		t.Errorf("Expected outermost middleware first, got %q", result)
+	}
+
+	if len(calls) != 2 || calls[0] != "a" {
+		t.Errorf("unexpected call order: %v", calls)
+	}
+}
+
+func TestWithRetry(t *testing.T) {
+	attempts := 0
+	base := ClienterFunc(func(prompt string) (string, error) {
+		attempts++
+		if attempts < 3 {
+			return "", errors.New("temporary failure")
+		}
+		return "ok", nil
+	})
+
+	result, err := WithRetry(2, time.Millisecond)(base).Generate("prompt")
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if result != "ok" || attempts != 3 {
+		t.Errorf("Expected success on third attempt, got %q after %d attempts", result, attempts)
+	}
+}
+
+func TestWithRetry_GivesUp(t *testing.T) {
+	attempts := 0
+	base := ClienterFunc(func(prompt string) (string, error) {
+		attempts++
+		return "", errors.New("permanent failure")
+	})
+
+	if _, err := WithRetry(1, time.Millisecond)(base).Generate("prompt"); err == nil {
+		t.Error("Expected error after retries are exhausted, got nil")
+	}
+
+	if attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", attempts)
+	}
+}
+
+func TestWithCache(t *testing.T) {
+	calls := 0
+	base := ClienterFunc(func(prompt string) (string, error) {
+		calls++
+		return "result for " + prompt, nil
+	})
+
+	client := WithCache(NewMemoryCache())(base)
+	for i := 0; i < 3; i++ {
+		if _, err := client.Generate("same prompt"); err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+	}
+
+	if calls != 1 {
+		t.Errorf("Expected 1 underlying call, got %d", calls)
+	}
+}
+
+func TestWithTimingAndLogging(t *testing.T) {
+	var observed int
+	base := ClienterFunc(func(prompt string) (string, error) {
+		return "ok", nil
+	})
+
+	client := Chain(base,
+		WithLogging(zap.NewNop()),
+		WithTiming(func(d time.Duration, err error) { observed++ }),
+	)
+
+	if _, err := client.Generate("prompt"); err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if observed != 1 {
+		t.Errorf("Expected timing to be observed once, got %d", observed)
+	}
+}
+
+func TestWithRateLimit(t *testing.T) {
+	base := ClienterFunc(func(prompt string) (string, error) {
+		return "ok", nil
+	})
+
+	client := WithRateLimit(20 * time.Millisecond)(base)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		client.Generate("prompt")
+	}
+
+	if elapsed := time.Since(start); elapsed < 40*time.Millisecond {
+		t.Errorf("Expected calls to be spaced out, took %s", elapsed)
+	}
+}
@@ -0,0 +1,191 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+)
+
+// ModelLister is implemented by clients that can list the models available
+// to the configured account. Listing models is also a cheap way to verify
+// that credentials are accepted.
+type ModelLister interface {
+	ListModels() ([]string, error)
+}
+
+// modelList is the response shape shared by the OpenAI, Azure OpenAI and Claude models endpoints
+type modelList struct {
+	Data []struct {
+		ID string `json:"id"`
+	} `json:"data"`
+	HasMore bool   `json:"has_more"`
+	LastID  string `json:"last_id"`
+	Error   *struct {
+		Type    string `json:"type"`
+		Code    any    `json:"code"`
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
+
+// ids returns the model IDs of the list or the API error it carries
+func (l *modelList) ids() ([]string, error) {
+	if l.Error != nil {
+		return nil, fmt.Errorf("API error [%s]: %s", l.Error.Type, l.Error.Message)
+	}
+
+	ids := make([]string, len(l.Data))
+	for i, m := range l.Data {
+		ids[i] = m.ID
+	}
+	return ids, nil
+}
+
+// geminiModelList is the response from the Gemini models endpoint
+type geminiModelList struct {
+	Models []struct {
+		Name string `json:"name"`
+	} `json:"models"`
+	NextPageToken string    `json:"nextPageToken"`
+	Error         *APIError `json:"error,omitempty"`
+}
+
+// ListModels returns the models available from the Gemini API
+func (c *GeminiClient) ListModels() ([]string, error) {
+	var models []string
+	pageToken := ""
+
+	for {
+		query := url.Values{"key": {c.apiKey}, "pageSize": {"1000"}}
+		if pageToken != "" {
+			query.Set("pageToken", pageToken)
+		}
+
+		resp, err := c.httpClient.Get(c.baseURL + "/models?" + query.Encode())
+		if err != nil {
+			return nil, fmt.Errorf("failed to send request: %w", err)
+		}
+
+		body, err := readResponse(resp)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		var list geminiModelList
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+
+		if list.Error != nil {
+			return nil, fmt.Errorf("API error [%d]: %s", list.Error.Code, list.Error.Message)
+		}
+
+		for _, m := range list.Models {
+			models = append(models, m.Name)
+		}
+
+		if list.NextPageToken == "" {
+			break
+		}
+		pageToken = list.NextPageToken
+	}
+
+	sort.Strings(models)
+	return models, nil
+}
+
+// ListModels returns the models available from the OpenAI API
+func (c *OpenAIClient) ListModels() ([]string, error) {
+	body, err := c.do(http.MethodGet, c.baseURL+"/models", "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	models, err := decodeModelList(body)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(models)
+	return models, nil
+}
+
+// ListModels returns the models available from the Claude API
+func (c *ClaudeClient) ListModels() ([]string, error) {
+	var models []string
+	afterID := ""
+
+	for {
+		query := url.Values{"limit": {"1000"}}
+		if afterID != "" {
+			query.Set("after_id", afterID)
+		}
+
+		body, err := c.do(http.MethodGet, c.baseURL+"/models?"+query.Encode(), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var list modelList
+		if err := json.Unmarshal(body, &list); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+
+		ids, err := list.ids()
+		if err != nil {
+			return nil, err
+		}
+		models = append(models, ids...)
+
+		if !list.HasMore || list.LastID == "" {
+			break
+		}
+		afterID = list.LastID
+	}
+
+	sort.Strings(models)
+	return models, nil
+}
+
+// ListModels returns the models available to the Azure OpenAI resource.
+// Deployments are created from these models in the Azure portal.
+func (c *AzureOpenAIClient) ListModels() ([]string, error) {
+	req, err := http.NewRequest(http.MethodGet,
+		fmt.Sprintf("%s/openai/models?api-version=%s", c.endpoint, url.QueryEscape(c.apiVersion)), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	req.Header.Set("api-key", c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := readResponse(resp)
+	if err != nil {
+		return nil, err
+	}
+
+	models, err := decodeModelList(body)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(models)
+	return models, nil
+}
+
+// decodeModelList decodes an OpenAI-style model list response
+func decodeModelList(body []byte) ([]string, error) {
+	var list modelList
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	return list.ids()
+}
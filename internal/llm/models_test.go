@@ -0,0 +1,96 @@
+package llm
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+)
+
+func TestOpenAIClient_ListModels(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/models" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"data":[{"id":"gpt-4o-mini"},{"id":"gpt-4o"}]}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("secret", zap.NewNop())
+	client.baseURL = server.URL
+
+	models, err := client.ListModels()
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(models, []string{"gpt-4o", "gpt-4o-mini"}) {
+		t.Errorf("unexpected models: %v", models)
+	}
+}
+
+func TestOpenAIClient_ListModels_InvalidKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"error":{"message":"Incorrect API key provided","type":"invalid_request_error","code":"invalid_api_key"}}`))
+	}))
+	defer server.Close()
+
+	client := NewOpenAIClient("wrong", zap.NewNop())
+	client.baseURL = server.URL
+
+	if _, err := client.ListModels(); err == nil {
+		t.Error("Expected error for invalid key, got nil")
+	}
+}
+
+func TestListModels_NonSuccessStatus(t *testing.T) {
+	// Azure's 401 body has no error key
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"statusCode":401,"message":"Unauthorized. Access token is missing, invalid, audience is incorrect, or have expired."}`))
+	}))
+	defer server.Close()
+
+	azure := NewAzureOpenAIClient("bad", server.URL, "gpt-4o-prod", "2024-06-01", zap.NewNop())
+	gemini := NewGeminiClient("bad", zap.NewNop())
+	gemini.baseURL = server.URL
+	claude := NewClaudeClient("bad", zap.NewNop())
+	claude.baseURL = server.URL
+
+	for name, lister := range map[string]ModelLister{"azure": azure, "gemini": gemini, "claude": claude} {
+		_, err := lister.ListModels()
+		if err == nil || !strings.Contains(err.Error(), "401") || !strings.Contains(err.Error(), "Access token is missing") {
+			t.Errorf("%s: ListModels() error = %v, want the status and body", name, err)
+		}
+	}
+}
+
+func TestClaudeClient_ListModels_Paginates(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("x-api-key") != "secret" {
+			t.Errorf("missing x-api-key header")
+		}
+		if r.URL.Query().Get("after_id") == "" {
+			w.Write([]byte(`{"data":[{"id":"claude-b"}],"has_more":true,"last_id":"claude-b"}`))
+			return
+		}
+		w.Write([]byte(`{"data":[{"id":"claude-a"}],"has_more":false}`))
+	}))
+	defer server.Close()
+
+	client := NewClaudeClient("secret", zap.NewNop())
+	client.baseURL = server.URL
+
+	models, err := client.ListModels()
+	if err != nil {
+		t.Fatalf("ListModels failed: %v", err)
+	}
+
+	if !reflect.DeepEqual(models, []string{"claude-a", "claude-b"}) {
+		t.Errorf("unexpected models: %v", models)
+	}
+}
@@ -12,21 +12,24 @@ import (
 )
 
 const (
-	openAIAPIURL = "https://api.openai.com/v1/chat/completions"
-	openAIModel  = "gpt-4o"
+	openAIBaseURL = "https://api.openai.com/v1"
+	openAIModel   = "gpt-4o"
 )
 
 // OpenAIClient implements Clienter for OpenAI API
 type OpenAIClient struct {
 	apiKey     string
+	baseURL    string
 	httpClient *http.Client
 	logger     *zap.Logger
+	usageTracker
 }
 
 // NewOpenAIClient creates a new OpenAI API client
 func NewOpenAIClient(apiKey string, logger *zap.Logger) *OpenAIClient {
 	return &OpenAIClient{
-		apiKey: apiKey,
+		apiKey:  apiKey,
+		baseURL: openAIBaseURL,
 		httpClient: &http.Client{
 			Timeout: 120 * time.Second,
 		},
@@ -36,7 +39,7 @@ func NewOpenAIClient(apiKey string, logger *zap.Logger) *OpenAIClient {
 
 // OpenAIChatRequest represents the request body for OpenAI Chat API
 type OpenAIChatRequest struct {
-	Model    string              `json:"model"`
+	Model    string              `json:"model,omitempty"`
 	Messages []OpenAIChatMessage `json:"messages"`
 }
 
@@ -50,9 +53,16 @@ type OpenAIChatMessage struct {
 type OpenAIChatResponse struct {
 	ID      string              `json:"id"`
 	Choices []OpenAIChatChoice  `json:"choices"`
+	Usage   *OpenAIUsage        `json:"usage,omitempty"`
 	Error   *OpenAIErrorWrapper `json:"error,omitempty"`
 }
 
+// OpenAIUsage represents the token usage of a request
+type OpenAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+}
+
 // OpenAIChatChoice represents a choice in the response
 type OpenAIChatChoice struct {
 	Message      OpenAIChatMessage `json:"message"`
@@ -68,6 +78,7 @@ type OpenAIErrorWrapper struct {
 
 // Generate sends a prompt to OpenAI API and returns the response
 func (c *OpenAIClient) Generate(prompt string) (string, error) {
+	c.reset()
 	c.logger.Debug("Sending request to OpenAI API")
 
 	reqBody := OpenAIChatRequest{
@@ -85,7 +96,7 @@ func (c *OpenAIClient) Generate(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	req, err := http.NewRequest(http.MethodPost, openAIAPIURL, bytes.NewBuffer(jsonBody))
+	req, err := http.NewRequest(http.MethodPost, c.baseURL+"/chat/completions", bytes.NewBuffer(jsonBody))
 	if err != nil {
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
@@ -104,21 +115,40 @@ func (c *OpenAIClient) Generate(prompt string) (string, error) {
 		return "", fmt.Errorf("failed to read response body: %w", err)
 	}
 
+	result, usage, err := parseOpenAIChatResponse(body)
+	if err != nil {
+		return "", err
+	}
+
+	c.record(usage)
+	c.logger.Debug("Received response from OpenAI API", zap.Int("length", len(result)))
+
+	return result, nil
+}
+
+// parseOpenAIChatResponse extracts the first choice and the token usage from
+// a chat completions response body. It is shared by every OpenAI-compatible client.
+func parseOpenAIChatResponse(body []byte) (string, Usage, error) {
 	var openAIResp OpenAIChatResponse
 	if err := json.Unmarshal(body, &openAIResp); err != nil {
-		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+		return "", Usage{}, fmt.Errorf("failed to unmarshal response: %w", err)
 	}
 
 	if openAIResp.Error != nil {
-		return "", fmt.Errorf("API error [%s]: %s", openAIResp.Error.Type, openAIResp.Error.Message)
+		return "", Usage{}, fmt.Errorf("API error [%s]: %s", openAIResp.Error.Type, openAIResp.Error.Message)
 	}
 
 	if len(openAIResp.Choices) == 0 {
-		return "", fmt.Errorf("empty response from OpenAI API")
+		return "", Usage{}, fmt.Errorf("empty response from OpenAI API")
 	}
 
-	result := openAIResp.Choices[0].Message.Content
-	c.logger.Debug("Received response from OpenAI API", zap.Int("length", len(result)))
+	var usage Usage
+	if openAIResp.Usage != nil {
+		usage = Usage{
+			InputTokens:  openAIResp.Usage.PromptTokens,
+			OutputTokens: openAIResp.Usage.CompletionTokens,
+		}
+	}
 
-	return result, nil
+	return openAIResp.Choices[0].Message.Content, usage, nil
 }
@@ -0,0 +1,309 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	openAIBatchEndpoint         = "/v1/chat/completions"
+	openAIBatchCompletionWindow = "24h"
+)
+
+// openAIBatchLine is a single line of the JSONL input file for the Batch API
+type openAIBatchLine struct {
+	CustomID string            `json:"custom_id"`
+	Method   string            `json:"method"`
+	URL      string            `json:"url"`
+	Body     OpenAIChatRequest `json:"body"`
+}
+
+// openAIFile represents an uploaded file
+type openAIFile struct {
+	ID    string              `json:"id"`
+	Error *OpenAIErrorWrapper `json:"error,omitempty"`
+}
+
+// openAIBatch represents a batch object returned by the Batch API
+type openAIBatch struct {
+	ID           string              `json:"id"`
+	Status       string              `json:"status"`
+	OutputFileID string              `json:"output_file_id"`
+	ErrorFileID  string              `json:"error_file_id"`
+	Error        *OpenAIErrorWrapper `json:"error,omitempty"`
+	// Errors are why the batch failed as a whole, such as an input file
+	// that did not pass validation
+	Errors *openAIBatchErrors `json:"errors,omitempty"`
+}
+
+// openAIBatchErrors is the list of errors of a failed batch
+type openAIBatchErrors struct {
+	Data []struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+		Line    *int   `json:"line"`
+	} `json:"data"`
+}
+
+// String joins the errors into one message
+func (e *openAIBatchErrors) String() string {
+	messages := make([]string, len(e.Data))
+	for i, d := range e.Data {
+		messages[i] = d.Message
+		if d.Line != nil {
+			messages[i] = fmt.Sprintf("line %d: %s", *d.Line, d.Message)
+		}
+		if d.Code != "" {
+			messages[i] = fmt.Sprintf("[%s] %s", d.Code, messages[i])
+		}
+	}
+	return strings.Join(messages, "; ")
+}
+
+// openAIBatchOutputLine is a single line of a batch output or error file
+type openAIBatchOutputLine struct {
+	CustomID string `json:"custom_id"`
+	Response *struct {
+		StatusCode int             `json:"status_code"`
+		Body       json.RawMessage `json:"body"`
+	} `json:"response"`
+	Error *OpenAIErrorWrapper `json:"error"`
+}
+
+// SubmitBatch uploads the prompts as a JSONL file and creates a batch job
+func (c *OpenAIClient) SubmitBatch(requests []BatchRequest) (string, error) {
+	c.logger.Debug("Submitting batch to OpenAI API", zap.Int("requests", len(requests)))
+
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for _, r := range requests {
+		line := openAIBatchLine{
+			CustomID: r.CustomID,
+			Method:   http.MethodPost,
+			URL:      openAIBatchEndpoint,
+			Body: OpenAIChatRequest{
+				Model:    openAIModel,
+				Messages: []OpenAIChatMessage{{Role: "user", Content: r.Prompt}},
+			},
+		}
+		if err := encoder.Encode(line); err != nil {
+			return "", fmt.Errorf("failed to encode batch request %s: %w", r.CustomID, err)
+		}
+	}
+
+	fileID, err := c.uploadBatchFile(input.Bytes())
+	if err != nil {
+		return "", err
+	}
+
+	jsonBody, err := json.Marshal(map[string]string{
+		"input_file_id":     fileID,
+		"endpoint":          openAIBatchEndpoint,
+		"completion_window": openAIBatchCompletionWindow,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	body, err := c.do(http.MethodPost, c.baseURL+"/batches", "application/json", bytes.NewBuffer(jsonBody))
+	if err != nil {
+		return "", err
+	}
+
+	var batch openAIBatch
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if batch.Error != nil {
+		return "", fmt.Errorf("API error [%s]: %s", batch.Error.Type, batch.Error.Message)
+	}
+
+	c.logger.Debug("Created OpenAI batch", zap.String("id", batch.ID))
+
+	return batch.ID, nil
+}
+
+// BatchStatus returns the processing state of a batch job
+func (c *OpenAIClient) BatchStatus(batchID string) (*BatchStatus, error) {
+	batch, err := c.getBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &BatchStatus{
+		ID:    batch.ID,
+		State: batch.Status,
+		Done:  isOpenAIBatchDone(batch.Status),
+	}, nil
+}
+
+// BatchResults downloads the output and error files of a finished batch job
+func (c *OpenAIClient) BatchResults(batchID string) ([]BatchResult, error) {
+	batch, err := c.getBatch(batchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isOpenAIBatchDone(batch.Status) {
+		return nil, fmt.Errorf("batch %s is not finished (status: %s)", batchID, batch.Status)
+	}
+	if batch.Errors != nil && len(batch.Errors.Data) > 0 {
+		return nil, fmt.Errorf("batch %s %s: %s", batchID, batch.Status, batch.Errors)
+	}
+
+	var results []BatchResult
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+
+		body, err := c.do(http.MethodGet, c.baseURL+"/files/"+fileID+"/content", "", nil)
+		if err != nil {
+			return nil, err
+		}
+
+		fileResults, err := parseOpenAIBatchOutput(body)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, fileResults...)
+	}
+
+	return results, nil
+}
+
+// getBatch retrieves a batch object
+func (c *OpenAIClient) getBatch(batchID string) (*openAIBatch, error) {
+	body, err := c.do(http.MethodGet, c.baseURL+"/batches/"+batchID, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var batch openAIBatch
+	if err := json.Unmarshal(body, &batch); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if batch.Error != nil {
+		return nil, fmt.Errorf("API error [%s]: %s", batch.Error.Type, batch.Error.Message)
+	}
+
+	return &batch, nil
+}
+
+// uploadBatchFile uploads a JSONL batch input file and returns its ID
+func (c *OpenAIClient) uploadBatchFile(content []byte) (string, error) {
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+
+	if err := writer.WriteField("purpose", "batch"); err != nil {
+		return "", fmt.Errorf("failed to write form field: %w", err)
+	}
+
+	part, err := writer.CreateFormFile("file", "gopherscript-batch.jsonl")
+	if err != nil {
+		return "", fmt.Errorf("failed to create form file: %w", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return "", fmt.Errorf("failed to write form file: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to close form: %w", err)
+	}
+
+	body, err := c.do(http.MethodPost, c.baseURL+"/files", writer.FormDataContentType(), &form)
+	if err != nil {
+		return "", err
+	}
+
+	var file openAIFile
+	if err := json.Unmarshal(body, &file); err != nil {
+		return "", fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+
+	if file.Error != nil {
+		return "", fmt.Errorf("API error [%s]: %s", file.Error.Type, file.Error.Message)
+	}
+
+	return file.ID, nil
+}
+
+// do sends an authenticated request to the OpenAI API and returns the response body
+func (c *OpenAIClient) do(method, url, contentType string, reqBody io.Reader) ([]byte, error) {
+	req, err := http.NewRequest(method, url, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	return readResponse(resp)
+}
+
+// parseOpenAIBatchOutput converts a batch output or error file into results
+func parseOpenAIBatchOutput(content []byte) ([]BatchResult, error) {
+	var results []BatchResult
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+
+		var line openAIBatchOutputLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal batch output line: %w", err)
+		}
+
+		result := BatchResult{CustomID: line.CustomID}
+		switch {
+		case line.Error != nil:
+			result.Error = line.Error.Message
+		case line.Response == nil:
+			result.Error = "no response in batch output"
+		default:
+			text, _, err := parseOpenAIChatResponse(line.Response.Body)
+			if err != nil {
+				result.Error = err.Error()
+			} else {
+				result.Text = text
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read batch output: %w", err)
+	}
+
+	return results, nil
+}
+
+// isOpenAIBatchDone reports whether a batch has reached a terminal status
+func isOpenAIBatchDone(status string) bool {
+	switch status {
+	case "completed", "failed", "expired", "cancelled":
+		return true
+	default:
+		return false
+	}
+}
@@ -1,41 +1,34 @@
 package llm
 
-import "fmt"
-
 // ScriptType represents the type of script being converted
 type ScriptType string
 
 const (
 	ScriptTypePython ScriptType = "python"
 	ScriptTypeShell  ScriptType = "shell"
+	ScriptTypePerl   ScriptType = "perl"
+	ScriptTypeRuby   ScriptType = "ruby"
+	ScriptTypeNode   ScriptType = "node"
+	ScriptTypePwsh   ScriptType = "powershell"
+	ScriptTypeAwk    ScriptType = "awk"
+	ScriptTypeSed    ScriptType = "sed"
 )
 
 // BuildTranspilePrompt creates a prompt for transpiling script code to Go
+// using the built-in template
 func BuildTranspilePrompt(scriptType ScriptType, code string) string {
-	baseInstruction := `You are an expert Go programmer. Convert the following %s script to idiomatic Go code.
-
-Requirements:
-1. Use proper error handling with wrapped errors
-2. Follow Go naming conventions (camelCase for unexported, PascalCase for exported)
-3. Add necessary imports
-4. Include a main function that can be compiled into a standalone binary
-5. Add brief comments explaining the logic
-6. Use the standard library when possible
-7. Return ONLY the Go code without any explanation or markdown formatting
-
-%s script to convert:
-` + "```\n%s\n```"
-
-	return fmt.Sprintf(baseInstruction, scriptType, scriptType, code)
+	prompt, _ := defaultPromptTemplates.Transpile(PromptData{ScriptType: scriptType, Code: code})
+	return prompt
 }
 
-// BuildRefinePrompt creates a prompt for refining generated Go code
-func BuildRefinePrompt(goCode string, errorMessage string) string {
-	return fmt.Sprintf(`The following Go code has a compilation error. Please fix it and return only the corrected Go code without any explanation or markdown formatting.
-
-Error message:
-%s
+// PlaceholderInstruction tells the model to keep redaction placeholders intact
+const PlaceholderInstruction = `Tokens of the form __GS_<KIND>_<N>__ are placeholders for redacted values.
+Keep every placeholder verbatim inside string literals exactly where the original value is used.
+Do not invent values for them, rename them, split them or read them from anywhere else.`
 
-Go code to fix:
-`+"```go\n%s\n```", errorMessage, goCode)
+// BuildRefinePrompt creates a prompt for refining generated Go code using
+// the built-in template
+func BuildRefinePrompt(goCode string, errorMessage string) string {
+	prompt, _ := defaultPromptTemplates.Refine(PromptData{GoCode: goCode, Error: errorMessage})
+	return prompt
 }
@@ -10,40 +10,98 @@ import (
 type Provider string
 
 const (
-	ProviderGemini Provider = "gemini"
-	ProviderOpenAI Provider = "openai"
-	ProviderClaude Provider = "claude"
+	ProviderGemini      Provider = "gemini"
+	ProviderOpenAI      Provider = "openai"
+	ProviderClaude      Provider = "claude"
+	ProviderAzureOpenAI Provider = "azure-openai"
 )
 
 // ValidProviders returns a list of valid provider names
 func ValidProviders() []Provider {
-	return []Provider{ProviderGemini, ProviderOpenAI, ProviderClaude}
+	return []Provider{ProviderGemini, ProviderOpenAI, ProviderClaude, ProviderAzureOpenAI}
 }
 
 // IsValid checks if the provider is valid
 func (p Provider) IsValid() bool {
 	switch p {
-	case ProviderGemini, ProviderOpenAI, ProviderClaude:
+	case ProviderGemini, ProviderOpenAI, ProviderClaude, ProviderAzureOpenAI:
 		return true
 	default:
 		return false
 	}
 }
 
+// DefaultModel returns the model GopherScript requests from the provider.
+// Azure OpenAI has no default because the deployment selects the model.
+func (p Provider) DefaultModel() string {
+	switch p {
+	case ProviderGemini:
+		return geminiModel
+	case ProviderOpenAI:
+		return openAIModel
+	case ProviderClaude:
+		return claudeModel
+	default:
+		return ""
+	}
+}
+
+// DefaultBaseURL returns the API base URL of the provider.
+// Azure OpenAI has no default because each resource has its own endpoint.
+func (p Provider) DefaultBaseURL() string {
+	switch p {
+	case ProviderGemini:
+		return geminiBaseURL
+	case ProviderOpenAI:
+		return openAIBaseURL
+	case ProviderClaude:
+		return claudeBaseURL
+	default:
+		return ""
+	}
+}
+
+// ClientConfig holds the settings needed to construct an LLM client
+type ClientConfig struct {
+	Provider Provider
+	APIKey   string
+
+	// Azure OpenAI only
+	AzureEndpoint   string
+	AzureDeployment string
+	AzureAPIVersion string
+}
+
+// Model returns the model requests are sent to. For Azure OpenAI this is the deployment name.
+func (c ClientConfig) Model() string {
+	if c.Provider == ProviderAzureOpenAI {
+		return c.AzureDeployment
+	}
+	return c.Provider.DefaultModel()
+}
+
 // NewClient creates an LLM client based on the provider
-func NewClient(provider Provider, apiKey string, logger *zap.Logger) (Clienter, error) {
-	if apiKey == "" {
-		return nil, fmt.Errorf("API key is required for provider %s", provider)
+func NewClient(cfg ClientConfig, logger *zap.Logger) (Clienter, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("API key is required for provider %s", cfg.Provider)
 	}
 
-	switch provider {
+	switch cfg.Provider {
 	case ProviderGemini:
-		return NewGeminiClient(apiKey, logger), nil
+		return NewGeminiClient(cfg.APIKey, logger), nil
 	case ProviderOpenAI:
-		return NewOpenAIClient(apiKey, logger), nil
+		return NewOpenAIClient(cfg.APIKey, logger), nil
 	case ProviderClaude:
-		return NewClaudeClient(apiKey, logger), nil
+		return NewClaudeClient(cfg.APIKey, logger), nil
+	case ProviderAzureOpenAI:
+		if cfg.AzureEndpoint == "" {
+			return nil, fmt.Errorf("endpoint is required for provider %s", cfg.Provider)
+		}
+		if cfg.AzureDeployment == "" {
+			return nil, fmt.Errorf("deployment is required for provider %s", cfg.Provider)
+		}
+		return NewAzureOpenAIClient(cfg.APIKey, cfg.AzureEndpoint, cfg.AzureDeployment, cfg.AzureAPIVersion, logger), nil
 	default:
-		return nil, fmt.Errorf("unsupported provider: %s", provider)
+		return nil, fmt.Errorf("unsupported provider: %s", cfg.Provider)
 	}
 }
@@ -0,0 +1,29 @@
+package llm
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxErrorBody is the most of a response body quoted in an error
+const maxErrorBody = 512
+
+// readResponse reads the body of resp. A status outside 2xx is an error
+// that carries the status and the body, whatever shape the body has.
+func readResponse(resp *http.Response) ([]byte, error) {
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		text := strings.TrimSpace(string(body))
+		if len(text) > maxErrorBody {
+			text = text[:maxErrorBody] + "..."
+		}
+		return nil, fmt.Errorf("API request failed with status %s: %s", resp.Status, text)
+	}
+	return body, nil
+}
@@ -0,0 +1,210 @@
+package llm
+
+import (
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+)
+
+// Prompt template names. User overrides are read from "<name>.tmpl".
+const (
+	TemplateTranspile = "transpile"
+	TemplateRefine    = "refine"
+)
+
+//go:embed prompts/*.tmpl
+var defaultTemplateFS embed.FS
+
+// PromptData holds the variables available to prompt templates
+type PromptData struct {
+	// ScriptType is the language of the script, e.g. "python" or "shell"
+	ScriptType ScriptType
+	// Dialect is the language variant, e.g. "bash" or "python3", when known
+	Dialect Dialect
+	// DialectGuidance lists the dialect semantics the Go code must preserve
+	DialectGuidance string
+	// Code is the script source, and Delimiter the marker enclosing it and
+	// GoCode. Delimiter is derived from both when empty.
+	Code      string
+	Delimiter string
+	// FileName is the base name of the script file
+	FileName string
+	// GoVersion is the Go version the generated code must compile with
+	GoVersion string
+	// DependencyPolicy describes which modules the code may import
+	DependencyPolicy string
+	// CLIStyle is the command-line framework the program must use, and
+	// CLIGuidance describes how to map the script's arguments to it
+	CLIStyle    string
+	CLIGuidance string
+	// Provider and Model identify the LLM the prompt is sent to
+	Provider Provider
+	Model    string
+	// Conventions is the project conventions file the code must follow
+	Conventions string
+	// ScriptPackages are the third-party packages the script imports,
+	// e.g. "axios@^1.6.0"
+	ScriptPackages []string
+	// Instructions are additional requirements, one per entry
+	Instructions []string
+	// Examples are reference conversions of similar scripts
+	Examples []PromptExample
+
+	// GoCode and Error are the code to fix and the compiler output (refine only)
+	GoCode string
+	Error  string
+}
+
+// PromptExample is a script and its Go conversion shown to the model as a
+// few-shot example
+type PromptExample struct {
+	Name   string
+	Script string
+	GoCode string
+}
+
+// TemplateSourceBuiltin is the TemplateInfo.Source of built-in templates
+const TemplateSourceBuiltin = "builtin"
+
+// TemplateInfo identifies the exact template a prompt was rendered from.
+// Version is declared in the template with a {{/* version: N */}} comment.
+type TemplateInfo struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Hash    string `json:"hash"`
+	Source  string `json:"source"`
+}
+
+// versionComment matches the version declaration of a template
+var versionComment = regexp.MustCompile(`\{\{-?\s*/\*\s*version:\s*(\S+)\s*\*/\s*-?\}\}`)
+
+// PromptTemplates renders transpile and refine prompts
+type PromptTemplates struct {
+	templates map[string]*promptTemplate
+}
+
+type promptTemplate struct {
+	tmpl *template.Template
+	info TemplateInfo
+}
+
+// newPromptTemplate parses a template and records where it came from
+func newPromptTemplate(name, text, source string) (*promptTemplate, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	info := TemplateInfo{Name: name, Hash: Hash(text), Source: source}
+	if m := versionComment.FindStringSubmatch(text); m != nil {
+		info.Version = m[1]
+	}
+	return &promptTemplate{tmpl: tmpl, info: info}, nil
+}
+
+var defaultPromptTemplates = mustDefaultPromptTemplates()
+
+// DefaultPromptTemplates returns the built-in prompt templates
+func DefaultPromptTemplates() *PromptTemplates {
+	return defaultPromptTemplates.clone()
+}
+
+func mustDefaultPromptTemplates() *PromptTemplates {
+	t := &PromptTemplates{templates: make(map[string]*promptTemplate)}
+	for _, name := range []string{TemplateTranspile, TemplateRefine} {
+		data, err := defaultTemplateFS.ReadFile("prompts/" + name + ".tmpl")
+		if err != nil {
+			panic(fmt.Sprintf("missing built-in prompt template %s: %v", name, err))
+		}
+		pt, err := newPromptTemplate(name, string(data), TemplateSourceBuiltin)
+		if err != nil {
+			panic(fmt.Sprintf("invalid built-in prompt template %s: %v", name, err))
+		}
+		if pt.info.Version == "" {
+			panic(fmt.Sprintf("built-in prompt template %s has no version", name))
+		}
+		t.templates[name] = pt
+	}
+	return t
+}
+
+func (t *PromptTemplates) clone() *PromptTemplates {
+	c := &PromptTemplates{templates: make(map[string]*promptTemplate, len(t.templates))}
+	for name, tmpl := range t.templates {
+		c.templates[name] = tmpl
+	}
+	return c
+}
+
+// LoadDir overrides templates with any "<name>.tmpl" file found in dir. A
+// missing directory is not an error.
+func (t *PromptTemplates) LoadDir(dir string) error {
+	for name := range t.templates {
+		path := filepath.Join(dir, name+".tmpl")
+		if _, err := os.Stat(path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to stat prompt template: %s: %w", path, err)
+		}
+
+		if err := t.LoadFile(name, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile overrides the named template with the contents of path
+func (t *PromptTemplates) LoadFile(name, path string) error {
+	if _, ok := t.templates[name]; !ok {
+		return fmt.Errorf("unknown prompt template: %s", name)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read prompt template: %s: %w", path, err)
+	}
+
+	pt, err := newPromptTemplate(name, string(data), path)
+	if err != nil {
+		return fmt.Errorf("failed to parse prompt template: %s: %w", path, err)
+	}
+
+	t.templates[name] = pt
+	return nil
+}
+
+// Info returns the version, hash and source of the named template
+func (t *PromptTemplates) Info(name string) TemplateInfo {
+	if pt, ok := t.templates[name]; ok {
+		return pt.info
+	}
+	return TemplateInfo{Name: name}
+}
+
+// Transpile renders the transpile prompt
+func (t *PromptTemplates) Transpile(data PromptData) (string, error) {
+	return t.render(TemplateTranspile, data)
+}
+
+// Refine renders the refine prompt
+func (t *PromptTemplates) Refine(data PromptData) (string, error) {
+	return t.render(TemplateRefine, data)
+}
+
+func (t *PromptTemplates) render(name string, data PromptData) (string, error) {
+	if data.Delimiter == "" {
+		data.Delimiter = Delimiter(data.Code + data.GoCode)
+	}
+
+	var sb strings.Builder
+	if err := t.templates[name].tmpl.Execute(&sb, data); err != nil {
+		return "", fmt.Errorf("failed to render %s prompt: %w", name, err)
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
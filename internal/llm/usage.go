@@ -0,0 +1,43 @@
+package llm
+
+import "sync"
+
+// Usage holds the token counts reported by a provider for one request
+type Usage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// UsageReporter is implemented by clients that record the token usage of
+// their most recent Generate call
+type UsageReporter interface {
+	LastUsage() Usage
+}
+
+// usageTracker records the token usage of the last request made by a client
+type usageTracker struct {
+	mu   sync.Mutex
+	last Usage
+}
+
+// record stores the usage of the latest request
+func (t *usageTracker) record(u Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.last = u
+}
+
+// reset clears the recorded usage at the start of a request, so that a
+// request that fails or reports none is not given the previous counts
+func (t *usageTracker) reset() {
+	t.record(Usage{})
+}
+
+// LastUsage returns the token usage of the most recent request
+func (t *usageTracker) LastUsage() Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.last
+}
@@ -0,0 +1,103 @@
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"github.com/bonzonkim/gopher-script/internal/llm"
+)
+
+// Metadata records how a Go file was generated, so that it can be
+// reproduced or audited later
+type Metadata struct {
+	GeneratedAt time.Time `json:"generated_at"`
+	Tool        string    `json:"tool_version,omitempty"`
+
+	Input    Input  `json:"input"`
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+	BatchID  string `json:"batch_id,omitempty"`
+
+	// Prompts are the templates used; PromptHash is the hash of the
+	// transpile prompt as sent, matching the audit log
+	Prompts    []llm.TemplateInfo `json:"prompts"`
+	PromptHash string             `json:"prompt_hash"`
+
+	Options Options `json:"options"`
+
+	// Refinements is the number of follow-up requests made to fix the code
+	Refinements int    `json:"refinements"`
+	OutputHash  string `json:"output_hash,omitempty"`
+}
+
+// Input identifies the script the code was generated from
+type Input struct {
+	Path       string `json:"path"`
+	Hash       string `json:"hash"`
+	ScriptType string `json:"script_type"`
+	Dialect    string `json:"dialect,omitempty"`
+}
+
+// Options are the settings that shaped the prompt and the checks
+type Options struct {
+	GoVersion    string   `json:"go_version,omitempty"`
+	Dependencies string   `json:"dependencies,omitempty"`
+	Modules      []string `json:"modules,omitempty"`
+	CLIStyle     string   `json:"cli_style,omitempty"`
+	Redact       bool     `json:"redact,omitempty"`
+	RedactPolicy string   `json:"redact_policy,omitempty"`
+	Examples     []string `json:"examples,omitempty"`
+	Conventions  *File    `json:"conventions,omitempty"`
+	Instructions []string `json:"instructions,omitempty"`
+}
+
+// File identifies a file that was included in the prompt
+type File struct {
+	Path string `json:"path"`
+	Hash string `json:"hash"`
+}
+
+// ToolVersion returns the version of the running GopherScript binary
+func ToolVersion() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	return info.Main.Version
+}
+
+// PathFor returns the sidecar metadata path of a generated Go file
+func PathFor(goFile string) string {
+	return strings.TrimSuffix(goFile, ".go") + ".meta.json"
+}
+
+// Write writes the metadata as indented JSON
+func Write(path string, m *Metadata) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal metadata: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write metadata file: %s: %w", path, err)
+	}
+	return nil
+}
+
+// Read reads a metadata file
+func Read(path string) (*Metadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata file: %s: %w", path, err)
+	}
+
+	var m Metadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse metadata file: %s: %w", path, err)
+	}
+	return &m, nil
+}
@@ -0,0 +1,46 @@
+package metadata
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/bonzonkim/gopher-script/internal/llm"
+)
+
+func TestPathFor(t *testing.T) {
+	if got := PathFor(filepath.Join("out", "deploy.go")); got != filepath.Join("out", "deploy.meta.json") {
+		t.Errorf("PathFor() = %q", got)
+	}
+}
+
+func TestWriteRead(t *testing.T) {
+	m := &Metadata{
+		GeneratedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Input:       Input{Path: "deploy.sh", Hash: "sha256:abc", ScriptType: "shell", Dialect: "bash"},
+		Provider:    "claude",
+		Model:       "claude-sonnet",
+		Prompts:     []llm.TemplateInfo{llm.DefaultPromptTemplates().Info(llm.TemplateTranspile)},
+		PromptHash:  "sha256:def",
+		Options: Options{
+			GoVersion:   "1.22",
+			Examples:    []string{"trap_cleanup"},
+			Conventions: &File{Path: "GOPHERSCRIPT.md", Hash: "sha256:123"},
+		},
+		Refinements: 1,
+	}
+
+	path := filepath.Join(t.TempDir(), "deploy.meta.json")
+	if err := Write(path, m); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := Read(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, m) {
+		t.Errorf("Read() = %+v, want %+v", got, m)
+	}
+}
@@ -0,0 +1,227 @@
+package notebook
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Notebook is a Jupyter notebook converted into a Python program
+type Notebook struct {
+	// Script is the Python program: the code cells in order, with the
+	// markdown cells as comments
+	Script string
+	// CodeCells is the number of code cells in the program
+	CodeCells int
+	// Shell are the !command lines and shell cells, which the program
+	// runs with subprocess
+	Shell []Line
+	// Skipped are the IPython magics and help lines left out
+	Skipped []Line
+}
+
+// Line is a line of a notebook cell. Cells are numbered from 1 in
+// notebook order, counting every cell.
+type Line struct {
+	Cell int    `json:"cell"`
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+func (l Line) String() string {
+	return fmt.Sprintf("cell %d, line %d: %s", l.Cell, l.Line, l.Text)
+}
+
+var (
+	// shellAssignment matches "files = !ls", which captures the output
+	shellAssignment = regexp.MustCompile(`^([A-Za-z_]\w*(?:\s*,\s*[A-Za-z_]\w*)*)\s*=\s*!(.*)$`)
+	// installLine matches package installs, which set up the kernel rather
+	// than being part of the job
+	installLine = regexp.MustCompile(`^[!%](?:pip3?|conda|mamba)\s+(?:install|uninstall)\b`)
+	// helpLine matches IPython help requests such as "df.merge?"
+	helpLine = regexp.MustCompile(`^\?{0,2}[A-Za-z_][\w.]*\?{0,2}$`)
+)
+
+// keptMagics are the magics whose code still runs without them
+var keptMagics = map[string]bool{"time": true, "timeit": true, "prun": true, "capture": true}
+
+// file is the nbformat 4 JSON document
+type file struct {
+	NBFormat int `json:"nbformat"`
+	Metadata struct {
+		Kernelspec struct {
+			Name     string `json:"name"`
+			Language string `json:"language"`
+		} `json:"kernelspec"`
+		LanguageInfo struct {
+			Name string `json:"name"`
+		} `json:"language_info"`
+	} `json:"metadata"`
+	Cells []cell `json:"cells"`
+}
+
+type cell struct {
+	CellType string `json:"cell_type"`
+	Source   source `json:"source"`
+}
+
+// source is cell text, stored as a string or a list of lines
+type source string
+
+func (s *source) UnmarshalJSON(data []byte) error {
+	var lines []string
+	if err := json.Unmarshal(data, &lines); err == nil {
+		*s = source(strings.Join(lines, ""))
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*s = source(text)
+	return nil
+}
+
+// Parse converts the JSON of a notebook named name into a Python program
+func Parse(data []byte, name string) (*Notebook, error) {
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("failed to decode notebook: %w", err)
+	}
+	if f.NBFormat < 4 {
+		return nil, fmt.Errorf("notebook format %d is not supported; upgrade it with jupyter nbconvert --to notebook", f.NBFormat)
+	}
+
+	language := f.Metadata.Kernelspec.Language
+	if language == "" {
+		language = f.Metadata.LanguageInfo.Name
+	}
+	if language != "" && !strings.EqualFold(language, "python") {
+		return nil, fmt.Errorf("notebook kernel language is %s, not Python", language)
+	}
+
+	nb := &Notebook{}
+	var body strings.Builder
+	for i, c := range f.Cells {
+		text := strings.TrimRight(string(c.Source), "\n")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		switch c.CellType {
+		case "markdown":
+			body.WriteString("\n# %% [markdown]\n")
+			for _, line := range strings.Split(text, "\n") {
+				body.WriteString(strings.TrimRight("# "+line, " ") + "\n")
+			}
+		case "code":
+			nb.CodeCells++
+			body.WriteString("\n# %%\n")
+			body.WriteString(nb.code(i+1, text))
+		}
+	}
+
+	interpreter := "python3"
+	if strings.HasPrefix(f.Metadata.Kernelspec.Name, "python2") {
+		interpreter = "python2"
+	}
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "#!/usr/bin/env %s\n", interpreter)
+	fmt.Fprintf(&sb, "# Program generated by gopherscript from the notebook %s: its code\n", name)
+	sb.WriteString("# cells in order, with markdown cells as comments. Cell outputs are not\n")
+	sb.WriteString("# included, and IPython magics are left out.\n")
+	if len(nb.Shell) > 0 {
+		sb.WriteString("import subprocess\n")
+	}
+	sb.WriteString(body.String())
+	nb.Script = sb.String()
+	return nb, nil
+}
+
+// code converts the text of the code cell numbered n
+func (nb *Notebook) code(n int, text string) string {
+	lines := strings.Split(text, "\n")
+
+	// Cell magics apply to the whole cell
+	if strings.HasPrefix(lines[0], "%%") {
+		fields := strings.Fields(lines[0][2:])
+		magic := ""
+		if len(fields) > 0 {
+			magic = fields[0]
+		}
+		cellBody := strings.Join(lines[1:], "\n")
+		switch {
+		case keptMagics[magic]:
+			return "# IPython cell magic: " + lines[0] + "\n" + nb.lines(n, lines[1:], 2)
+		case magic == "bash" || magic == "sh" || magic == "script" && len(fields) > 1 && (fields[1] == "bash" || fields[1] == "sh"):
+			nb.Shell = append(nb.Shell, Line{Cell: n, Line: 1, Text: lines[0]})
+			return fmt.Sprintf("# IPython cell magic: %s\nsubprocess.run([%q, \"-c\", %s])\n", lines[0], shellOf(fields), strconv.Quote(cellBody))
+		case magic == "writefile" && len(fields) > 1:
+			mode, path := "w", fields[len(fields)-1]
+			if fields[1] == "-a" || fields[1] == "--append" {
+				mode = "a"
+			}
+			return fmt.Sprintf("# IPython cell magic: %s\nwith open(%s, %q) as f:\n    f.write(%s)\n", lines[0], strconv.Quote(path), mode, strconv.Quote(cellBody+"\n"))
+		}
+		nb.Skipped = append(nb.Skipped, Line{Cell: n, Line: 1, Text: lines[0]})
+		return fmt.Sprintf("# IPython cell magic %s skipped\n", lines[0])
+	}
+	return nb.lines(n, lines, 1)
+}
+
+// lines converts the lines of a code cell, the first being line first
+func (nb *Notebook) lines(n int, lines []string, first int) string {
+	var sb strings.Builder
+	for i, line := range lines {
+		number := first + i
+		trimmed := strings.TrimLeft(line, " \t")
+		indent := line[:len(line)-len(trimmed)]
+
+		switch {
+		case installLine.MatchString(trimmed):
+			nb.Skipped = append(nb.Skipped, Line{Cell: n, Line: number, Text: trimmed})
+			fmt.Fprintf(&sb, "%s# package install skipped: %s\n", indent, trimmed)
+		case strings.HasPrefix(trimmed, "!"):
+			nb.Shell = append(nb.Shell, Line{Cell: n, Line: number, Text: trimmed})
+			fmt.Fprintf(&sb, "%ssubprocess.run(%s, shell=True)  # %s\n", indent, command(trimmed[1:]), trimmed)
+		case shellAssignment.MatchString(trimmed):
+			m := shellAssignment.FindStringSubmatch(trimmed)
+			nb.Shell = append(nb.Shell, Line{Cell: n, Line: number, Text: trimmed})
+			fmt.Fprintf(&sb, "%s%s = subprocess.run(%s, shell=True, capture_output=True, text=True).stdout.splitlines()  # %s\n", indent, m[1], command(m[2]), trimmed)
+		case strings.HasPrefix(trimmed, "%"):
+			fields := strings.Fields(trimmed[1:])
+			if len(fields) > 1 && keptMagics[fields[0]] {
+				// %time and %timeit only measure the statement after them
+				sb.WriteString(indent + strings.TrimSpace(strings.TrimPrefix(trimmed[1:], fields[0])) + "\n")
+				continue
+			}
+			nb.Skipped = append(nb.Skipped, Line{Cell: n, Line: number, Text: trimmed})
+			fmt.Fprintf(&sb, "%s# IPython magic skipped: %s\n", indent, trimmed)
+		case helpLine.MatchString(trimmed) && strings.Contains(trimmed, "?"):
+			nb.Skipped = append(nb.Skipped, Line{Cell: n, Line: number, Text: trimmed})
+			fmt.Fprintf(&sb, "%s# IPython help skipped: %s\n", indent, trimmed)
+		default:
+			sb.WriteString(line + "\n")
+		}
+	}
+	return sb.String()
+}
+
+// command returns the Python string for a shell command, an f-string when
+// it interpolates {expressions} like IPython does
+func command(cmd string) string {
+	cmd = strings.TrimSpace(cmd)
+	if strings.Contains(cmd, "{") {
+		return "f" + strconv.Quote(cmd)
+	}
+	return strconv.Quote(cmd)
+}
+
+// shellOf returns the shell a shell cell magic runs
+func shellOf(fields []string) string {
+	if fields[0] == "script" {
+		return fields[1]
+	}
+	return fields[0]
+}
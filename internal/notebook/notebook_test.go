@@ -0,0 +1,103 @@
+package notebook
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testNotebook = `{
+ "cells": [
+  {"cell_type": "markdown", "metadata": {}, "source": ["# Sales ETL\n", "\n", "Loads the export."]},
+  {"cell_type": "code", "metadata": {}, "outputs": [], "source": ["%matplotlib inline\n", "!pip install requests\n", "import csv"]},
+  {"cell_type": "code", "metadata": {}, "outputs": [{"output_type": "stream", "name": "stdout", "text": ["secret-token\n"]}], "source": ["files = !ls data\n", "for f in files:\n", "    !wc -l {f}\n", "    %time rows = list(csv.reader(open(f)))"]},
+  {"cell_type": "raw", "metadata": {}, "source": "not code"},
+  {"cell_type": "code", "metadata": {}, "outputs": [], "source": "%%bash\nmkdir -p out"},
+  {"cell_type": "code", "metadata": {}, "outputs": [], "source": "%%html\n<b>hi</b>"},
+  {"cell_type": "code", "metadata": {}, "outputs": [], "source": "%%writefile out/config.json\n{}"},
+  {"cell_type": "code", "metadata": {}, "outputs": [], "source": ["csv.reader?\n", "print(len(rows))"]},
+  {"cell_type": "code", "metadata": {}, "outputs": [], "source": []}
+ ],
+ "metadata": {"kernelspec": {"language": "python", "name": "python3"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}`
+
+func TestParse(t *testing.T) {
+	nb, err := Parse([]byte(testNotebook), "etl.ipynb")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if nb.CodeCells != 6 {
+		t.Errorf("CodeCells = %d, want 6", nb.CodeCells)
+	}
+
+	for _, want := range []string{
+		"#!/usr/bin/env python3\n",
+		"import subprocess\n",
+		"# %% [markdown]\n# # Sales ETL\n#\n# Loads the export.\n",
+		"# IPython magic skipped: %matplotlib inline\n# package install skipped: !pip install requests\nimport csv\n",
+		`files = subprocess.run("ls data", shell=True, capture_output=True, text=True).stdout.splitlines()`,
+		"    subprocess.run(f\"wc -l {f}\", shell=True)  # !wc -l {f}\n",
+		"    rows = list(csv.reader(open(f)))\n",
+		`subprocess.run(["bash", "-c", "mkdir -p out"])`,
+		"# IPython cell magic %%html skipped\n",
+		"with open(\"out/config.json\", \"w\") as f:\n    f.write(\"{}\\n\")\n",
+		"# IPython help skipped: csv.reader?\nprint(len(rows))\n",
+	} {
+		if !strings.Contains(nb.Script, want) {
+			t.Errorf("Script does not contain %q:\n%s", want, nb.Script)
+		}
+	}
+	for _, unwanted := range []string{"secret-token", "not code", "<b>hi</b>"} {
+		if strings.Contains(nb.Script, unwanted) {
+			t.Errorf("Script contains %q", unwanted)
+		}
+	}
+
+	wantShell := []Line{
+		{Cell: 3, Line: 1, Text: "files = !ls data"},
+		{Cell: 3, Line: 3, Text: "!wc -l {f}"},
+		{Cell: 5, Line: 1, Text: "%%bash"},
+	}
+	if !reflect.DeepEqual(nb.Shell, wantShell) {
+		t.Errorf("Shell = %+v, want %+v", nb.Shell, wantShell)
+	}
+
+	wantSkipped := []Line{
+		{Cell: 2, Line: 1, Text: "%matplotlib inline"},
+		{Cell: 2, Line: 2, Text: "!pip install requests"},
+		{Cell: 6, Line: 1, Text: "%%html"},
+		{Cell: 8, Line: 1, Text: "csv.reader?"},
+	}
+	if !reflect.DeepEqual(nb.Skipped, wantSkipped) {
+		t.Errorf("Skipped = %+v, want %+v", nb.Skipped, wantSkipped)
+	}
+}
+
+func TestParse_Python2Kernel(t *testing.T) {
+	nb, err := Parse([]byte(`{"cells": [{"cell_type": "code", "source": "print 'hi'"}], "metadata": {"kernelspec": {"name": "python2"}}, "nbformat": 4}`), "old.ipynb")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !strings.HasPrefix(nb.Script, "#!/usr/bin/env python2\n") {
+		t.Errorf("Script does not start with a python2 shebang:\n%s", nb.Script)
+	}
+	if strings.Contains(nb.Script, "import subprocess") {
+		t.Error("Script imports subprocess without shell commands")
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := map[string]string{
+		"not JSON":     "cells:",
+		"old format":   `{"nbformat": 3, "worksheets": []}`,
+		"other kernel": `{"cells": [], "metadata": {"kernelspec": {"language": "R"}}, "nbformat": 4}`,
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data), "x.ipynb"); err == nil {
+			t.Errorf("%s: Parse() error = nil", name)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package parser
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/bonzonkim/gopher-script/internal/taskfile"
+)
+
+// Dialect is the specific language variant of a script, such as bash for
+// shell scripts or python3 for Python scripts
+type Dialect string
+
+const (
+	DialectSh      Dialect = "sh"
+	DialectBash    Dialect = "bash"
+	DialectZsh     Dialect = "zsh"
+	DialectKsh     Dialect = "ksh"
+	DialectPython2 Dialect = "python2"
+	DialectPython3 Dialect = "python3"
+	DialectPerl    Dialect = "perl"
+	DialectRuby    Dialect = "ruby"
+	DialectRake    Dialect = "rake"
+	DialectJS      Dialect = "javascript"
+	DialectTS      Dialect = "typescript"
+	DialectPwsh    Dialect = "powershell"
+	DialectAwk     Dialect = "awk"
+	DialectGawk    Dialect = "gawk"
+	DialectSed     Dialect = "sed"
+	DialectMake    Dialect = "make"
+	DialectJust    Dialect = "just"
+)
+
+var (
+	// bashisms are constructs that POSIX sh does not support
+	bashisms = regexp.MustCompile(`(?m)\[\[|^\s*(?:declare|local|shopt|mapfile|readarray)\b|^\s*function\s+\w+|\w+=\(|\$\{\w+\[|pipefail|\$\{!\w+|<\(|\$'`)
+	// typeAnnotations are TypeScript-only declarations
+	typeAnnotations = regexp.MustCompile(`(?m)^\s*(?:export\s+)?(?:interface|type)\s+\w+|\b(?:const|let|var)\s+\w+\s*:\s*[A-Za-z]|\)\s*:\s*(?:Promise<|void\b|string\b|number\b)`)
+	// gawkisms are GNU awk extensions
+	gawkisms = regexp.MustCompile(`\b(?:BEGINFILE|ENDFILE|gensub|asorti?|PROCINFO|strftime|systime|mktime|IGNORECASE|FPAT|FIELDWIDTHS)\b|(?m)^\s*@(?:include|load|namespace)\b`)
+	// python2isms are constructs that only Python 2 accepts or uses
+	python2isms = regexp.MustCompile(`(?m)^\s*print\s+[^\s(=]|^\s*except\s+[\w.]+\s*,\s*\w+\s*:|\bxrange\(|\braw_input\(|\.iteritems\(|\.has_key\(|\bbasestring\b|\bunicode\(|<>|^\s*exec\s+["']`)
+)
+
+// ShebangInterpreter returns the interpreter named by the script's shebang
+// line, resolving "/usr/bin/env" indirection, or "" when there is none
+func ShebangInterpreter(content string) string {
+	firstLine, _, _ := strings.Cut(content, "\n")
+	firstLine = strings.TrimSpace(firstLine)
+	if !strings.HasPrefix(firstLine, "#!") {
+		return ""
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(firstLine, "#!"))
+	if len(fields) == 0 {
+		return ""
+	}
+
+	interpreter := filepath.Base(fields[0])
+	if interpreter == "env" {
+		interpreter = ""
+		for _, field := range fields[1:] {
+			if strings.HasPrefix(field, "-") || strings.Contains(field, "=") {
+				continue
+			}
+			interpreter = filepath.Base(field)
+			break
+		}
+	}
+
+	return interpreter
+}
+
+// detectDialect determines the dialect of a script from its shebang,
+// extension and, failing those, the constructs it uses
+func detectDialect(scriptType ScriptType, filePath, content string) Dialect {
+	interpreter := ShebangInterpreter(content)
+	ext := strings.ToLower(filepath.Ext(filePath))
+
+	switch scriptType {
+	case ScriptTypeShell:
+		switch taskfile.Detect(filePath) {
+		case taskfile.KindMake:
+			return DialectMake
+		case taskfile.KindJust:
+			return DialectJust
+		}
+
+		switch {
+		case interpreter == "bash":
+			return DialectBash
+		case interpreter == "zsh":
+			return DialectZsh
+		case strings.HasSuffix(interpreter, "ksh"):
+			return DialectKsh
+		case interpreter == "sh" || interpreter == "dash" || interpreter == "ash":
+			return DialectSh
+		}
+
+		switch ext {
+		case ".bash":
+			return DialectBash
+		case ".zsh":
+			return DialectZsh
+		case ".ksh":
+			return DialectKsh
+		}
+
+		if bashisms.MatchString(content) {
+			return DialectBash
+		}
+		return DialectSh
+
+	case ScriptTypePython:
+		switch {
+		case strings.HasPrefix(interpreter, "python2"):
+			return DialectPython2
+		case strings.HasPrefix(interpreter, "python3"):
+			return DialectPython3
+		}
+
+		if python2isms.MatchString(content) {
+			return DialectPython2
+		}
+		return DialectPython3
+
+	case ScriptTypePerl:
+		return DialectPerl
+
+	case ScriptTypeRuby:
+		if ext == ".rake" || strings.EqualFold(filepath.Base(filePath), "Rakefile") {
+			return DialectRake
+		}
+		return DialectRuby
+
+	case ScriptTypeNode:
+		switch ext {
+		case ".ts", ".mts", ".cts":
+			return DialectTS
+		case ".js", ".mjs", ".cjs":
+			return DialectJS
+		}
+
+		// Runners such as "npx tsx" hide behind env, so check the whole line
+		shebang, _, _ := strings.Cut(content, "\n")
+		if strings.HasPrefix(shebang, "#!") && (strings.Contains(shebang, "ts-node") || strings.Contains(shebang, "tsx")) {
+			return DialectTS
+		}
+		if typeAnnotations.MatchString(content) {
+			return DialectTS
+		}
+		return DialectJS
+
+	case ScriptTypePwsh:
+		return DialectPwsh
+
+	case ScriptTypeAwk:
+		if interpreter == "gawk" || gawkisms.MatchString(content) {
+			return DialectGawk
+		}
+		return DialectAwk
+
+	case ScriptTypeSed:
+		return DialectSed
+	}
+
+	return ""
+}
@@ -1,10 +1,14 @@
 package parser
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/bonzonkim/gopher-script/internal/notebook"
+	"github.com/bonzonkim/gopher-script/internal/taskfile"
 )
 
 // ScriptType represents the type of script file
@@ -13,6 +17,12 @@ type ScriptType string
 const (
 	ScriptTypePython  ScriptType = "python"
 	ScriptTypeShell   ScriptType = "shell"
+	ScriptTypePerl    ScriptType = "perl"
+	ScriptTypeRuby    ScriptType = "ruby"
+	ScriptTypeNode    ScriptType = "node"
+	ScriptTypePwsh    ScriptType = "powershell"
+	ScriptTypeAwk     ScriptType = "awk"
+	ScriptTypeSed     ScriptType = "sed"
 	ScriptTypeUnknown ScriptType = "unknown"
 )
 
@@ -21,7 +31,17 @@ type ParseResult struct {
 	FilePath   string
 	FileName   string
 	ScriptType ScriptType
+	Dialect    Dialect
 	Content    string
+	// Source is the file as read. It differs from Content for Makefiles,
+	// justfiles and notebooks, whose Content is generated from it.
+	Source string
+	// Tasks is the parsed Makefile or justfile that Content was generated
+	// from, or nil for other scripts
+	Tasks *taskfile.File
+	// Notebook is the Jupyter notebook that Content was generated from, or
+	// nil for other scripts
+	Notebook *notebook.Notebook
 }
 
 // Parser handles script file parsing
@@ -57,24 +77,71 @@ func (p *Parser) Parse(filePath string) (*ParseResult, error) {
 	// Detect script type
 	scriptType := p.detectScriptType(filePath, string(content))
 
-	return &ParseResult{
+	result := &ParseResult{
 		FilePath:   filePath,
 		FileName:   filepath.Base(filePath),
 		ScriptType: scriptType,
 		Content:    string(content),
-	}, nil
+		Source:     string(content),
+	}
+
+	// Makefiles and justfiles are converted through a bash script that runs
+	// their tasks like make or just
+	if kind := taskfile.Detect(filePath); kind != "" {
+		tasks, err := taskfile.Parse(kind, result.Content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s file: %s: %w", kind, filePath, err)
+		}
+		result.Tasks = tasks
+		result.Content = tasks.Script(result.FileName)
+	}
+
+	// Notebooks are converted as the Python program of their code cells
+	if isNotebook(filePath) {
+		nb, err := notebook.Parse(content, result.FileName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse notebook: %s: %w", filePath, err)
+		}
+		result.Notebook = nb
+		result.Content = nb.Script
+	}
+
+	result.Dialect = detectDialect(scriptType, filePath, result.Content)
+	return result, nil
 }
 
 // detectScriptType determines the script type based on extension and content
 func (p *Parser) detectScriptType(filePath string, content string) ScriptType {
 	ext := strings.ToLower(filepath.Ext(filePath))
 
+	// Task files run their recipes with the shell
+	if taskfile.Detect(filePath) != "" {
+		return ScriptTypeShell
+	}
+
+	// Rakefiles have no extension
+	if strings.EqualFold(filepath.Base(filePath), "Rakefile") {
+		return ScriptTypeRuby
+	}
+
 	// Check by extension first
 	switch ext {
-	case ".py":
+	case ".py", ".ipynb":
 		return ScriptTypePython
-	case ".sh", ".bash", ".zsh":
+	case ".sh", ".bash", ".zsh", ".ksh":
 		return ScriptTypeShell
+	case ".pl", ".pm":
+		return ScriptTypePerl
+	case ".rb", ".rake":
+		return ScriptTypeRuby
+	case ".js", ".mjs", ".cjs", ".ts", ".mts", ".cts":
+		return ScriptTypeNode
+	case ".ps1":
+		return ScriptTypePwsh
+	case ".awk":
+		return ScriptTypeAwk
+	case ".sed":
+		return ScriptTypeSed
 	}
 
 	// Check by shebang
@@ -85,6 +152,27 @@ func (p *Parser) detectScriptType(filePath string, content string) ScriptType {
 			if strings.Contains(firstLine, "python") {
 				return ScriptTypePython
 			}
+			if strings.Contains(firstLine, "perl") {
+				return ScriptTypePerl
+			}
+			if strings.Contains(firstLine, "ruby") {
+				return ScriptTypeRuby
+			}
+			if strings.Contains(firstLine, "node") || strings.Contains(firstLine, "tsx") || strings.Contains(firstLine, "deno") {
+				return ScriptTypeNode
+			}
+			// awk and sed are matched by interpreter name, since they are
+			// substrings of too many others
+			switch interpreter := ShebangInterpreter(content); {
+			case strings.HasSuffix(interpreter, "awk"):
+				return ScriptTypeAwk
+			case interpreter == "sed" || interpreter == "gsed":
+				return ScriptTypeSed
+			}
+			// Checked before shell, since "pwsh" contains "sh"
+			if strings.Contains(firstLine, "pwsh") || strings.Contains(firstLine, "powershell") {
+				return ScriptTypePwsh
+			}
 			if strings.Contains(firstLine, "bash") || strings.Contains(firstLine, "sh") || strings.Contains(firstLine, "zsh") {
 				return ScriptTypeShell
 			}
@@ -94,7 +182,96 @@ func (p *Parser) detectScriptType(filePath string, content string) ScriptType {
 	return ScriptTypeUnknown
 }
 
+// SourcePosition returns the 1-based line and column in Source of text
+// found at line and column of Content. Generated text is looked up in the
+// file, JSON-encoded for notebooks; the position is kept when it is not
+// found there.
+func (r *ParseResult) SourcePosition(text string, line, column int) (int, int) {
+	if r.Source == r.Content || text == "" {
+		return line, column
+	}
+
+	offset := offsetOf(r.Content, line, column)
+	if offset < 0 {
+		return line, column
+	}
+	n := strings.Count(r.Content[:offset], text)
+
+	// A value spanning lines of a notebook cell is split across JSON strings
+	for _, candidate := range []string{text, strings.SplitN(text, "\n", 2)[0]} {
+		if r.Notebook != nil {
+			candidate = jsonString(candidate)
+		}
+		if candidate == "" {
+			continue
+		}
+		if i := nthIndex(r.Source, candidate, n); i >= 0 {
+			return position(r.Source, i)
+		}
+	}
+	return line, column
+}
+
+// offsetOf returns the offset of a 1-based line and column in content, or
+// -1 if it is outside content
+func offsetOf(content string, line, column int) int {
+	offset := 0
+	for l := 1; l < line; l++ {
+		i := strings.IndexByte(content[offset:], '\n')
+		if i < 0 {
+			return -1
+		}
+		offset += i + 1
+	}
+	if offset += column - 1; offset < 0 || offset > len(content) {
+		return -1
+	}
+	return offset
+}
+
+// nthIndex returns the offset of the n-th (0-based) occurrence of substr
+// in s, the last one when there are fewer, or -1 if there is none
+func nthIndex(s, substr string, n int) int {
+	index := strings.Index(s, substr)
+	for ; n > 0 && index >= 0; n-- {
+		next := strings.Index(s[index+len(substr):], substr)
+		if next < 0 {
+			break
+		}
+		index += len(substr) + next
+	}
+	return index
+}
+
+// jsonString returns text as it is written inside a JSON string
+func jsonString(text string) string {
+	var b strings.Builder
+	encoder := json.NewEncoder(&b)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(text); err != nil {
+		return ""
+	}
+	encoded := strings.TrimSpace(b.String())
+	return encoded[1 : len(encoded)-1]
+}
+
+// position returns the 1-based line and column of offset in content
+func position(content string, offset int) (int, int) {
+	line := strings.Count(content[:offset], "\n") + 1
+	column := offset - strings.LastIndex(content[:offset], "\n")
+	return line, column
+}
+
+// isNotebook reports whether the file is a Jupyter notebook
+func isNotebook(filePath string) bool {
+	return strings.EqualFold(filepath.Ext(filePath), ".ipynb")
+}
+
 // IsSupportedType checks if the script type is supported for transpilation
 func (p *Parser) IsSupportedType(scriptType ScriptType) bool {
-	return scriptType == ScriptTypePython || scriptType == ScriptTypeShell
+	switch scriptType {
+	case ScriptTypePython, ScriptTypeShell, ScriptTypePerl, ScriptTypeRuby, ScriptTypeNode, ScriptTypePwsh, ScriptTypeAwk, ScriptTypeSed:
+		return true
+	}
+	return false
 }
@@ -3,6 +3,7 @@ package parser
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -101,6 +102,241 @@ print("test")
 	}
 }
 
+func TestParser_DetectPerl(t *testing.T) {
+	p := NewParser()
+
+	tests := []struct {
+		filePath string
+		content  string
+	}{
+		{"report.pl", "print \"hi\\n\";\n"},
+		{"Util.pm", "package Util;\n1;\n"},
+		{"report", "#!/usr/bin/perl -w\nprint \"hi\\n\";\n"},
+		{"report", "#!/usr/bin/env perl\nprint \"hi\\n\";\n"},
+	}
+
+	for _, tt := range tests {
+		if got := p.detectScriptType(tt.filePath, tt.content); got != ScriptTypePerl {
+			t.Errorf("detectScriptType(%q) = %s, expected %s", tt.filePath, got, ScriptTypePerl)
+		}
+		if got := detectDialect(ScriptTypePerl, tt.filePath, tt.content); got != DialectPerl {
+			t.Errorf("detectDialect(%q) = %s, expected %s", tt.filePath, got, DialectPerl)
+		}
+	}
+}
+
+func TestParser_DetectRuby(t *testing.T) {
+	p := NewParser()
+
+	tests := []struct {
+		filePath string
+		content  string
+		dialect  Dialect
+	}{
+		{"deploy.rb", "puts 'hi'\n", DialectRuby},
+		{"deploy", "#!/usr/bin/env ruby\nputs 'hi'\n", DialectRuby},
+		{"Rakefile", "task :default => [:test]\n", DialectRake},
+		{"db.rake", "namespace :db do\nend\n", DialectRake},
+	}
+
+	for _, tt := range tests {
+		if got := p.detectScriptType(tt.filePath, tt.content); got != ScriptTypeRuby {
+			t.Errorf("detectScriptType(%q) = %s, expected %s", tt.filePath, got, ScriptTypeRuby)
+		}
+		if got := detectDialect(ScriptTypeRuby, tt.filePath, tt.content); got != tt.dialect {
+			t.Errorf("detectDialect(%q) = %s, expected %s", tt.filePath, got, tt.dialect)
+		}
+	}
+}
+
+func TestParser_DetectNode(t *testing.T) {
+	p := NewParser()
+
+	tests := []struct {
+		filePath string
+		content  string
+		dialect  Dialect
+	}{
+		{"sync.js", "console.log('hi');\n", DialectJS},
+		{"sync.mjs", "import fs from 'node:fs';\n", DialectJS},
+		{"sync.ts", "console.log('hi');\n", DialectTS},
+		{"sync", "#!/usr/bin/env node\nconsole.log('hi');\n", DialectJS},
+		{"sync", "#!/usr/bin/env -S npx tsx\nconsole.log('hi');\n", DialectTS},
+		{"sync", "#!/usr/bin/env node\nfunction add(a: number, b: number): number {\n  return a + b;\n}\n", DialectTS},
+	}
+
+	for _, tt := range tests {
+		if got := p.detectScriptType(tt.filePath, tt.content); got != ScriptTypeNode {
+			t.Errorf("detectScriptType(%q) = %s, expected %s", tt.filePath, got, ScriptTypeNode)
+		}
+		if got := detectDialect(ScriptTypeNode, tt.filePath, tt.content); got != tt.dialect {
+			t.Errorf("detectDialect(%q) = %s, expected %s", tt.filePath, got, tt.dialect)
+		}
+	}
+}
+
+func TestParser_DetectPowerShell(t *testing.T) {
+	p := NewParser()
+
+	tests := []struct {
+		filePath string
+		content  string
+	}{
+		{"cleanup.ps1", "Get-ChildItem\n"},
+		{"CLEANUP.PS1", "Get-ChildItem\n"},
+		{"cleanup", "#!/usr/bin/env pwsh\nGet-ChildItem\n"},
+		{"cleanup", "#!/opt/microsoft/powershell/7/pwsh -NoProfile\nGet-ChildItem\n"},
+	}
+
+	for _, tt := range tests {
+		if got := p.detectScriptType(tt.filePath, tt.content); got != ScriptTypePwsh {
+			t.Errorf("detectScriptType(%q) = %s, expected %s", tt.filePath, got, ScriptTypePwsh)
+		}
+		if got := detectDialect(ScriptTypePwsh, tt.filePath, tt.content); got != DialectPwsh {
+			t.Errorf("detectDialect(%q) = %s, expected %s", tt.filePath, got, DialectPwsh)
+		}
+	}
+}
+
+func TestParser_DetectAwkSed(t *testing.T) {
+	p := NewParser()
+
+	tests := []struct {
+		filePath   string
+		content    string
+		scriptType ScriptType
+		dialect    Dialect
+	}{
+		{"totals.awk", "{ s += $2 }\nEND { print s }\n", ScriptTypeAwk, DialectAwk},
+		{"totals", "#!/usr/bin/awk -f\n{ s += $2 }\n", ScriptTypeAwk, DialectAwk},
+		{"totals", "#!/usr/bin/env -S gawk -f\n{ s += $2 }\n", ScriptTypeAwk, DialectGawk},
+		{"totals.awk", "{ print gensub(/a/, \"b\", \"g\") }\n", ScriptTypeAwk, DialectGawk},
+		{"strip.sed", "s/[[:space:]]*$//\n", ScriptTypeSed, DialectSed},
+		{"strip", "#!/bin/sed -nf\n/^#/!p\n", ScriptTypeSed, DialectSed},
+	}
+
+	for _, tt := range tests {
+		if got := p.detectScriptType(tt.filePath, tt.content); got != tt.scriptType {
+			t.Errorf("detectScriptType(%q) = %s, expected %s", tt.filePath, got, tt.scriptType)
+		}
+		if got := detectDialect(tt.scriptType, tt.filePath, tt.content); got != tt.dialect {
+			t.Errorf("detectDialect(%q) = %s, expected %s", tt.filePath, got, tt.dialect)
+		}
+	}
+}
+
+func TestParser_DetectTaskFile(t *testing.T) {
+	p := NewParser()
+
+	tests := []struct {
+		filePath string
+		dialect  Dialect
+	}{
+		{"Makefile", DialectMake},
+		{"GNUmakefile", DialectMake},
+		{"rules.mk", DialectMake},
+		{"justfile", DialectJust},
+		{".justfile", DialectJust},
+	}
+
+	for _, tt := range tests {
+		if got := p.detectScriptType(tt.filePath, "all:\n"); got != ScriptTypeShell {
+			t.Errorf("detectScriptType(%q) = %s, expected %s", tt.filePath, got, ScriptTypeShell)
+		}
+		if got := detectDialect(ScriptTypeShell, tt.filePath, "all:\n"); got != tt.dialect {
+			t.Errorf("detectDialect(%q) = %s, expected %s", tt.filePath, got, tt.dialect)
+		}
+	}
+}
+
+func TestParser_Parse_Makefile(t *testing.T) {
+	makefile := filepath.Join(t.TempDir(), "Makefile")
+	if err := os.WriteFile(makefile, []byte("all: build\n\nbuild:\n\tgo build ./...\n"), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := NewParser().Parse(makefile)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if result.Tasks == nil || result.Tasks.Default != "all" {
+		t.Fatalf("Parse() Tasks = %+v, expected the default target all", result.Tasks)
+	}
+	if !strings.Contains(result.Content, `run_line build 4 "" "go build ./..."`) {
+		t.Errorf("Parse() Content does not run the build recipe:\n%s", result.Content)
+	}
+}
+
+func TestParser_Parse_Notebook(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "etl.ipynb")
+	content := `{"cells": [{"cell_type": "code", "source": ["import sys\n", "print(sys.argv)"]}], "metadata": {}, "nbformat": 4}`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	result, err := NewParser().Parse(path)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if result.ScriptType != ScriptTypePython || result.Dialect != DialectPython3 {
+		t.Errorf("Parse() = %s/%s, expected %s/%s", result.ScriptType, result.Dialect, ScriptTypePython, DialectPython3)
+	}
+	if result.Notebook == nil || !strings.Contains(result.Content, "import sys\nprint(sys.argv)\n") {
+		t.Errorf("Parse() Content is not the notebook's code:\n%s", result.Content)
+	}
+}
+
+func TestParseResult_SourcePosition(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"Makefile": "all: notify\n\nnotify:\n\techo start\n\tmail -s done ops@example.com\n",
+		"etl.ipynb": `{
+ "cells": [
+  {"cell_type": "markdown", "source": ["# ETL"]},
+  {"cell_type": "code", "source": [
+    "import os\n",
+    "TOKEN = \"ghp_abc\"\n",
+    "print(TOKEN)"
+  ]}
+ ],
+ "metadata": {},
+ "nbformat": 4
+}`,
+	}
+
+	tests := []struct {
+		file, text        string
+		wantLine, wantCol int
+	}{
+		{"Makefile", "ops@example.com", 5, 15},
+		{"etl.ipynb", `TOKEN = "ghp_abc"`, 6, 6},
+		{"etl.ipynb", "print(TOKEN)", 7, 6},
+	}
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.file)
+		if err := os.WriteFile(path, []byte(files[tt.file]), 0644); err != nil {
+			t.Fatalf("Failed to create test file: %v", err)
+		}
+		result, err := NewParser().Parse(path)
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+
+		offset := strings.Index(result.Content, tt.text)
+		if offset < 0 {
+			t.Fatalf("%s: %q not in Content:\n%s", tt.file, tt.text, result.Content)
+		}
+		line, col := position(result.Content, offset)
+
+		gotLine, gotCol := result.SourcePosition(tt.text, line, col)
+		if gotLine != tt.wantLine || gotCol != tt.wantCol {
+			t.Errorf("%s: SourcePosition(%q) = %d:%d, want %d:%d", tt.file, tt.text, gotLine, gotCol, tt.wantLine, tt.wantCol)
+		}
+	}
+}
+
 func TestParser_IsSupportedType(t *testing.T) {
 	p := NewParser()
 
@@ -110,6 +346,12 @@ func TestParser_IsSupportedType(t *testing.T) {
 	}{
 		{ScriptTypePython, true},
 		{ScriptTypeShell, true},
+		{ScriptTypePerl, true},
+		{ScriptTypeRuby, true},
+		{ScriptTypeNode, true},
+		{ScriptTypePwsh, true},
+		{ScriptTypeAwk, true},
+		{ScriptTypeSed, true},
 		{ScriptTypeUnknown, false},
 	}
 
@@ -120,3 +362,35 @@ func TestParser_IsSupportedType(t *testing.T) {
 		}
 	}
 }
+
+func TestDetectDialect(t *testing.T) {
+	tests := []struct {
+		name       string
+		filePath   string
+		scriptType ScriptType
+		content    string
+		expected   Dialect
+	}{
+		{"sh shebang", "run.sh", ScriptTypeShell, "#!/bin/sh\necho hi\n", DialectSh},
+		{"bash via env", "run.sh", ScriptTypeShell, "#!/usr/bin/env bash\necho hi\n", DialectBash},
+		{"zsh shebang", "run", ScriptTypeShell, "#!/bin/zsh\necho hi\n", DialectZsh},
+		{"ksh shebang", "run", ScriptTypeShell, "#!/bin/mksh\necho hi\n", DialectKsh},
+		{"bash extension", "run.bash", ScriptTypeShell, "echo hi\n", DialectBash},
+		{"bashisms without shebang", "run.sh", ScriptTypeShell, "set -euo pipefail\nfiles=(a b)\n", DialectBash},
+		{"posix without shebang", "run.sh", ScriptTypeShell, "set -e\n[ -f x ] && echo x\n", DialectSh},
+		{"python2 shebang", "run.py", ScriptTypePython, "#!/usr/bin/python2.7\nprint('hi')\n", DialectPython2},
+		{"python3 via env", "run.py", ScriptTypePython, "#!/usr/bin/env -S python3 -u\nprint('hi')\n", DialectPython3},
+		{"python2 print statement", "run.py", ScriptTypePython, "#!/usr/bin/env python\nprint \"hi\"\n", DialectPython2},
+		{"python2 except syntax", "run.py", ScriptTypePython, "try:\n    pass\nexcept IOError, e:\n    pass\n", DialectPython2},
+		{"python default", "run.py", ScriptTypePython, "print('hi')\n", DialectPython3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := detectDialect(tt.scriptType, tt.filePath, tt.content)
+			if result != tt.expected {
+				t.Errorf("detectDialect() = %s, expected %s", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,254 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// Policy decides which LLM providers a script may be sent to. Rules are
+// evaluated in order and the first matching rule wins; scripts that match
+// no rule may go to any provider.
+type Policy struct {
+	Rules []Rule `json:"rules"`
+
+	// Root is the directory rule paths are relative to: the project holding
+	// the policy file, or the file's own directory
+	Root string `json:"-"`
+	// File is the policy file the rules were read from
+	File string `json:"-"`
+}
+
+// Rule maps scripts to the providers they may be sent to. A script matches
+// when it matches one of Paths (if any) and one of Content (if any). An
+// empty Providers list allows no provider at all.
+type Rule struct {
+	Name      string   `json:"name"`
+	Paths     []string `json:"paths,omitempty"`
+	Content   []string `json:"content,omitempty"`
+	Providers []string `json:"providers"`
+
+	content []*regexp.Regexp
+}
+
+// DeniedError is returned when a policy rule does not allow the provider
+type DeniedError struct {
+	Rule     string
+	Path     string
+	Provider string
+	Allowed  []string
+}
+
+// OutsideError is returned when a script lies outside the root of the
+// policy that applies to it, so the policy's path rules cannot be checked
+type OutsideError struct {
+	Rule   string
+	Path   string
+	Policy string
+	Root   string
+}
+
+func (e *OutsideError) Error() string {
+	return fmt.Sprintf("egress policy %s cannot check rule '%s' for %s, which is outside %s", e.Policy, e.Rule, e.Path, e.Root)
+}
+
+func (e *DeniedError) Error() string {
+	allowed := "none"
+	if len(e.Allowed) > 0 {
+		allowed = strings.Join(e.Allowed, ", ")
+	}
+	return fmt.Sprintf("policy rule '%s' does not allow sending %s to provider '%s' (allowed: %s)", e.Rule, e.Path, e.Provider, allowed)
+}
+
+// Load reads a policy file whose rule paths are relative to the file's
+// directory. A missing file yields a policy without rules.
+func Load(path string) (*Policy, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve policy file: %s: %w", path, err)
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &Policy{Root: filepath.Dir(abs), File: abs}, nil
+		}
+		return nil, fmt.Errorf("failed to read policy file: %s: %w", path, err)
+	}
+
+	p := Policy{Root: filepath.Dir(abs), File: abs}
+	if err := json.Unmarshal(data, &p); err != nil {
+		return nil, fmt.Errorf("failed to parse policy file: %s: %w", path, err)
+	}
+
+	if err := p.compile(); err != nil {
+		return nil, fmt.Errorf("invalid policy file: %s: %w", path, err)
+	}
+
+	return &p, nil
+}
+
+// compile validates the rules and compiles their content patterns
+func (p *Policy) compile() error {
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("#%d", i+1)
+		}
+
+		for _, pattern := range rule.Paths {
+			if _, err := filepath.Match(strings.ReplaceAll(pattern, "**", "*"), ""); err != nil {
+				return fmt.Errorf("rule '%s': invalid path glob '%s': %w", rule.Name, pattern, err)
+			}
+		}
+
+		rule.content = make([]*regexp.Regexp, len(rule.Content))
+		for j, pattern := range rule.Content {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("rule '%s': invalid content pattern '%s': %w", rule.Name, pattern, err)
+			}
+			rule.content[j] = re
+		}
+	}
+	return nil
+}
+
+// Match returns the first rule that applies to the script, or nil. A rule
+// with path globs cannot be checked for a script outside Root; Match
+// returns an *OutsideError when it reaches one.
+func (p *Policy) Match(path, content string) (*Rule, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	rel, inside := p.relative(path)
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if len(rule.Paths) > 0 && !inside {
+			return nil, &OutsideError{Rule: rule.Name, Path: path, Policy: p.File, Root: p.Root}
+		}
+		if rule.matches(rel, content) {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// Check returns a *DeniedError when the script may not be sent to provider
+func (p *Policy) Check(path, content, provider string) error {
+	rule, err := p.Match(path, content)
+	if err != nil {
+		return err
+	}
+	if rule == nil || rule.Allows(provider) {
+		return nil
+	}
+
+	return &DeniedError{
+		Rule:     rule.Name,
+		Path:     path,
+		Provider: provider,
+		Allowed:  rule.Providers,
+	}
+}
+
+// Allows reports whether the rule allows the provider
+func (r *Rule) Allows(provider string) bool {
+	for _, p := range r.Providers {
+		if p == provider || p == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *Rule) matches(path, content string) bool {
+	if len(r.Paths) > 0 {
+		matched := false
+		for _, pattern := range r.Paths {
+			if matchGlob(pattern, path) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(r.content) > 0 {
+		for _, re := range r.content {
+			if re.MatchString(content) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// relative returns path as a slash-separated path relative to Root, and
+// whether it lies inside Root. Symbolic links are resolved on both sides, so
+// a script is matched the same way from any working directory.
+func (p *Policy) relative(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(realPath(p.Root), realPath(abs))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// realPath resolves the symbolic links in path, or in its longest existing
+// parent when it does not exist
+func realPath(path string) string {
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path
+	}
+	return filepath.Join(realPath(parent), filepath.Base(path))
+}
+
+// matchGlob matches a slash-separated path against a glob where "**"
+// matches any number of path segments. Patterns without a slash are
+// matched against the base name.
+func matchGlob(pattern, path string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := filepath.Match(pattern, filepath.Base(path))
+		return ok
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(path, "/"))
+}
+
+func matchSegments(pattern, path []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(path); i++ {
+				if matchSegments(pattern[1:], path[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(path) == 0 {
+			return false
+		}
+		if ok, _ := filepath.Match(pattern[0], path[0]); !ok {
+			return false
+		}
+		pattern, path = pattern[1:], path[1:]
+	}
+	return len(path) == 0
+}
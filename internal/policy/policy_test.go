@@ -0,0 +1,199 @@
+package policy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		want    bool
+	}{
+		{"infra/**", "infra/deploy.sh", true},
+		{"infra/**", "infra/k8s/apply.sh", true},
+		{"infra/**", "tools/infra.sh", false},
+		{"**/secrets/**", "app/secrets/rotate.py", true},
+		{"**/secrets/**", "secrets/rotate.py", true},
+		{"scripts/*.sh", "scripts/build.sh", true},
+		{"scripts/*.sh", "scripts/sub/build.sh", false},
+		{"*.py", "tools/helper.py", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern+"_"+tt.path, func(t *testing.T) {
+			if got := matchGlob(tt.pattern, tt.path); got != tt.want {
+				t.Errorf("matchGlob(%q, %q) = %v, want %v", tt.pattern, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "policy.json")
+	data := `{
+  "rules": [
+    {"name": "infra", "paths": ["infra/**", "secrets/**"], "providers": ["azure-openai"]},
+    {"name": "customer-data", "content": ["(?i)customer_id"], "providers": []},
+    {"name": "open-source", "paths": ["tools/**"], "providers": ["*"]}
+  ]
+}`
+	if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		path     string
+		content  string
+		provider string
+		rule     string
+	}{
+		{"infra denied", "infra/deploy.sh", "echo hi", "openai", "infra"},
+		{"infra allowed", "./infra/deploy.sh", "echo hi", "azure-openai", ""},
+		{"content denied", "tools/export.py", "print(CUSTOMER_ID)", "azure-openai", "customer-data"},
+		{"open source", "tools/fmt.sh", "echo hi", "gemini", ""},
+		{"no rule", "misc/a.sh", "echo hi", "claude", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := p.Check(filepath.Join(dir, tt.path), tt.content, tt.provider)
+			if tt.rule == "" {
+				if err != nil {
+					t.Errorf("Check() error = %v, want nil", err)
+				}
+				return
+			}
+
+			var denied *DeniedError
+			if !errors.As(err, &denied) {
+				t.Fatalf("Check() error = %v, want *DeniedError", err)
+			}
+			if denied.Rule != tt.rule {
+				t.Errorf("DeniedError.Rule = %q, want %q", denied.Rule, tt.rule)
+			}
+		})
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	dir := t.TempDir()
+	p, err := Load(filepath.Join(dir, "policy.json"))
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if err := p.Check(filepath.Join(dir, "infra/a.sh"), "", "openai"); err != nil {
+		t.Errorf("Check() error = %v, want nil", err)
+	}
+}
+
+func TestLoadInvalidPattern(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "policy.json")
+	if err := os.WriteFile(path, []byte(`{"rules": [{"name": "bad", "content": ["("]}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := Load(path); err == nil {
+		t.Error("Load() error = nil, want error")
+	}
+}
+
+// writeProject creates a project whose policy denies openai for infra/**
+func writeProject(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	for _, dir := range []string{".gopherscript", "infra", "tools"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := `{"rules": [{"name": "infra", "paths": ["infra/**"], "providers": ["azure-openai"]}]}`
+	if err := os.WriteFile(filepath.Join(root, ".gopherscript", "policy.json"), []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func TestResolverCheck(t *testing.T) {
+	root := writeProject(t)
+	script := filepath.Join(root, "infra", "deploy.sh")
+
+	tests := []struct {
+		name   string
+		wd     string
+		script string
+	}{
+		{"from project root", root, "infra/deploy.sh"},
+		{"from script directory", filepath.Join(root, "infra"), "deploy.sh"},
+		{"from other directory", filepath.Join(root, "tools"), "../infra/deploy.sh"},
+		{"absolute path", t.TempDir(), script},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Chdir(tt.wd)
+			r := &Resolver{Name: filepath.Join(".gopherscript", "policy.json")}
+
+			var denied *DeniedError
+			if err := r.Check(tt.script, "echo hi", "openai"); !errors.As(err, &denied) {
+				t.Fatalf("Check() error = %v, want *DeniedError", err)
+			}
+			if err := r.Check(tt.script, "echo hi", "azure-openai"); err != nil {
+				t.Errorf("Check() error = %v, want nil", err)
+			}
+			if err := r.Check(filepath.Join(root, "tools", "fmt.sh"), "echo hi", "openai"); err != nil {
+				t.Errorf("Check() outside infra error = %v, want nil", err)
+			}
+		})
+	}
+}
+
+func TestResolverCheckOutsideRoot(t *testing.T) {
+	root := writeProject(t)
+	t.Chdir(root)
+
+	outside := filepath.Join(t.TempDir(), "deploy.sh")
+	r := &Resolver{Name: filepath.Join(".gopherscript", "policy.json")}
+
+	var outsideErr *OutsideError
+	if err := r.Check(outside, "echo hi", "openai"); !errors.As(err, &outsideErr) {
+		t.Fatalf("Check() error = %v, want *OutsideError", err)
+	}
+
+	explicit := &Resolver{File: filepath.Join(root, ".gopherscript", "policy.json"), Name: filepath.Join(".gopherscript", "policy.json")}
+	if err := explicit.Check(outside, "echo hi", "openai"); !errors.As(err, &outsideErr) {
+		t.Errorf("Check() with explicit file error = %v, want *OutsideError", err)
+	}
+	if err := explicit.Check(filepath.Join(root, "infra", "x.sh"), "echo hi", "azure-openai"); err != nil {
+		t.Errorf("Check() with explicit file error = %v, want nil", err)
+	}
+}
+
+func TestResolverMissingFile(t *testing.T) {
+	dir := t.TempDir()
+
+	r := &Resolver{File: filepath.Join(dir, "polcy.json"), Name: filepath.Join(".gopherscript", "policy.json")}
+	if err := r.Check(filepath.Join(dir, "deploy.sh"), "echo hi", "openai"); err == nil {
+		t.Error("Check() error = nil, want an error for a missing explicit policy file")
+	}
+}
+
+func TestResolverNoPolicy(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+
+	r := &Resolver{Name: filepath.Join(".gopherscript", "policy.json")}
+	if err := r.Check("infra/deploy.sh", "echo hi", "openai"); err != nil {
+		t.Errorf("Check() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,105 @@
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Resolver finds the policy that applies to a script. Without an explicit
+// file, the project policy is looked up in the directories above the
+// script, then in those above the working directory.
+type Resolver struct {
+	// File is a policy file to use for every script, which must exist. When
+	// it lies at Name inside a project, its rule paths are relative to that
+	// project.
+	File string
+	// Name is the path of the policy file inside a project, such as
+	// .gopherscript/policy.json. An absolute Name is used like File.
+	Name string
+
+	policies map[string]*Policy
+}
+
+// Policy returns the policy that applies to the script, or nil when there
+// is none
+func (r *Resolver) Policy(script string) (*Policy, error) {
+	file, root := r.File, ""
+	if file == "" && filepath.IsAbs(r.Name) {
+		file = r.Name
+	}
+
+	if file != "" {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve policy file: %s: %w", file, err)
+		}
+		file = abs
+		// A policy given explicitly must exist, or a typo would turn the
+		// gate off
+		if r.File != "" {
+			if _, err := os.Stat(file); err != nil {
+				return nil, fmt.Errorf("policy file not found: %s: %w", r.File, err)
+			}
+		}
+		if r.Name != "" && !filepath.IsAbs(r.Name) && strings.HasSuffix(file, string(filepath.Separator)+filepath.Clean(r.Name)) {
+			root = strings.TrimSuffix(file, string(filepath.Separator)+filepath.Clean(r.Name))
+		}
+	} else {
+		abs, err := filepath.Abs(script)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve script path: %s: %w", script, err)
+		}
+		if root = findRoot(filepath.Dir(abs), r.Name); root == "" {
+			if wd, err := os.Getwd(); err == nil {
+				root = findRoot(wd, r.Name)
+			}
+		}
+		if root == "" {
+			return nil, nil
+		}
+		file = filepath.Join(root, r.Name)
+	}
+
+	if p, ok := r.policies[file]; ok {
+		return p, nil
+	}
+
+	p, err := Load(file)
+	if err != nil {
+		return nil, err
+	}
+	if root != "" {
+		p.Root = root
+	}
+
+	if r.policies == nil {
+		r.policies = map[string]*Policy{}
+	}
+	r.policies[file] = p
+	return p, nil
+}
+
+// Check finds the policy for the script and checks it
+func (r *Resolver) Check(script, content, provider string) error {
+	p, err := r.Policy(script)
+	if err != nil {
+		return err
+	}
+	return p.Check(script, content, provider)
+}
+
+// findRoot returns the nearest directory at or above dir that holds name
+func findRoot(dir, name string) string {
+	for {
+		if info, err := os.Stat(filepath.Join(dir, name)); err == nil && !info.IsDir() {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
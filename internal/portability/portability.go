@@ -0,0 +1,90 @@
+package portability
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Issue is a command used by a script that has no portable Go equivalent
+type Issue struct {
+	Line    int    `json:"line"`
+	Command string `json:"command"`
+	Reason  string `json:"reason"`
+}
+
+// rule matches the commands that share one portability problem
+type rule struct {
+	reason  string
+	pattern *regexp.Regexp
+}
+
+var powershellRules = []rule{
+	{"WMI and CIM are only available on Windows", regexp.MustCompile(`(?i)\b(?:Get-WmiObject|gwmi|Invoke-WmiMethod|Set-WmiInstance|Remove-WmiObject|Register-WmiEvent|Get-CimInstance|Get-CimClass|Invoke-CimMethod|Set-CimInstance|New-CimSession)\b`)},
+	{"the registry is only available on Windows", regexp.MustCompile(`(?i)\bHK(?:LM|CU|CR|U|CC):|\bRegistry::`)},
+	{"Windows services are managed through the service control manager", regexp.MustCompile(`(?i)\b(?:Get|Start|Stop|Restart|Set|New|Remove|Suspend|Resume)-Service\b`)},
+	{"event logs are only available on Windows", regexp.MustCompile(`(?i)\b(?:(?:Get|Write|New|Clear|Limit|Remove)-EventLog|(?:Get|New)-WinEvent)\b`)},
+	{"the Active Directory module only runs on Windows", regexp.MustCompile(`(?i)\b(?:Get|Set|New|Remove|Enable|Disable|Unlock|Add|Search|Move)-AD[A-Z]\w*`)},
+	{"Windows ACLs have no portable equivalent", regexp.MustCompile(`(?i)\b(?:Get|Set)-Acl\b`)},
+	{"COM objects are only available on Windows", regexp.MustCompile(`(?i)\bNew-Object\s+-Com(?:Object)?\b`)},
+	{"scheduled tasks are managed by the Windows Task Scheduler", regexp.MustCompile(`(?i)\b(?:(?:Get|Set|New|Register|Unregister|Start|Stop|Enable|Disable)-ScheduledTask\w*|schtasks)\b`)},
+	{"local users and groups are Windows accounts", regexp.MustCompile(`(?i)\b(?:Get|Set|New|Remove|Enable|Disable|Rename|Add)-Local(?:User|Group|GroupMember)\b`)},
+	{"network configuration cmdlets only run on Windows", regexp.MustCompile(`(?i)\b(?:(?:Get|Set|New|Remove|Enable|Disable)-Net(?:Adapter|IPAddress|IPConfiguration|FirewallRule|Route|TCPConnection)\w*|Test-NetConnection)\b`)},
+	{"performance counters are only available on Windows", regexp.MustCompile(`(?i)\bGet-Counter\b`)},
+	{"PowerShell remoting runs commands on other machines", regexp.MustCompile(`(?i)\b(?:(?:Enter|New|Remove|Get)-PSSession|Invoke-Command)\b`)},
+	{"Windows GUI frameworks have no portable equivalent", regexp.MustCompile(`(?i)\bSystem\.Windows\.Forms\b|\bPresentationFramework\b|\bOut-GridView\b`)},
+	{"Win32 API calls have no portable equivalent", regexp.MustCompile(`\bDllImport\b`)},
+}
+
+// PowerShell returns the cmdlets and Windows features in a PowerShell
+// script that cannot be converted into portable Go, ordered by line.
+// Comments are ignored.
+func PowerShell(content string) []Issue {
+	var issues []Issue
+	inComment := false
+	for i, line := range strings.Split(content, "\n") {
+		line, inComment = stripComments(line, inComment)
+		for _, r := range powershellRules {
+			for _, m := range r.pattern.FindAllString(line, -1) {
+				issues = append(issues, Issue{Line: i + 1, Command: strings.Join(strings.Fields(m), " "), Reason: r.reason})
+			}
+		}
+	}
+
+	sort.SliceStable(issues, func(i, j int) bool {
+		return issues[i].Line < issues[j].Line
+	})
+	return issues
+}
+
+// stripComments removes "#" line comments and "<# ... #>" block comments
+// from line. inComment reports whether line starts inside a block comment.
+func stripComments(line string, inComment bool) (string, bool) {
+	var sb strings.Builder
+	for line != "" {
+		if inComment {
+			end := strings.Index(line, "#>")
+			if end < 0 {
+				return sb.String(), true
+			}
+			line = line[end+2:]
+			inComment = false
+			continue
+		}
+
+		start := strings.Index(line, "#")
+		if start < 0 {
+			sb.WriteString(line)
+			break
+		}
+		if start > 0 && line[start-1] == '<' {
+			sb.WriteString(line[:start-1])
+			line = line[start+1:]
+			inComment = true
+			continue
+		}
+		sb.WriteString(line[:start])
+		break
+	}
+	return sb.String(), inComment
+}
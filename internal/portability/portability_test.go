@@ -0,0 +1,41 @@
+package portability
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPowerShell(t *testing.T) {
+	content := `#!/usr/bin/env pwsh
+param([string]$Name = 'spooler')
+$ErrorActionPreference = 'Stop'
+
+# Get-Service is only called below
+<# Get-WmiObject in a
+   block comment #>
+$svc = Get-Service -Name $Name
+$os = gwmi Win32_OperatingSystem
+$path = Get-ItemProperty 'HKLM:\SOFTWARE\Vendor' <# inline #> | Select-Object InstallDir
+$ie = New-Object  -ComObject InternetExplorer.Application
+Get-ChildItem -Recurse | Where-Object { $_.Length -gt 1MB }
+Get-ADUser -Filter * | Export-Csv users.csv
+`
+
+	want := []Issue{
+		{Line: 8, Command: "Get-Service", Reason: "Windows services are managed through the service control manager"},
+		{Line: 9, Command: "gwmi", Reason: "WMI and CIM are only available on Windows"},
+		{Line: 10, Command: "HKLM:", Reason: "the registry is only available on Windows"},
+		{Line: 11, Command: "New-Object -ComObject", Reason: "COM objects are only available on Windows"},
+		{Line: 13, Command: "Get-ADUser", Reason: "the Active Directory module only runs on Windows"},
+	}
+	if got := PowerShell(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("PowerShell() = %v, want %v", got, want)
+	}
+}
+
+func TestPowerShellPortableScript(t *testing.T) {
+	content := "param([switch]$Force)\nGet-ChildItem *.log | Remove-Item -Force:$Force\nInvoke-RestMethod https://example.com/api | ConvertTo-Json\n"
+	if issues := PowerShell(content); len(issues) != 0 {
+		t.Errorf("PowerShell() = %v, want none", issues)
+	}
+}
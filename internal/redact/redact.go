@@ -0,0 +1,279 @@
+package redact
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/bonzonkim/gopher-script/internal/detect"
+)
+
+// Kind classifies a redacted value
+type Kind string
+
+const (
+	KindPrivateKey Kind = "private-key"
+	KindToken      Kind = "token"
+	KindSecret     Kind = "secret"
+	KindEmail      Kind = "email"
+	KindHost       Kind = "host"
+	KindIP         Kind = "ip"
+)
+
+// Redaction records a value that was replaced by a placeholder
+type Redaction struct {
+	Placeholder string
+	Kind        Kind
+	Value       string
+	// Name is the variable the value was assigned to, when known
+	Name string
+	// Line is the first line the value appears on
+	Line int
+	// Count is the number of occurrences replaced
+	Count int
+}
+
+// EnvVar returns the environment variable the value is read from under the env policy
+func (r Redaction) EnvVar() string {
+	if r.Name != "" {
+		return strings.ToUpper(r.Name)
+	}
+	return strings.Trim(r.Placeholder, "_")
+}
+
+// Masked returns the value with all but its first characters hidden, for reports
+func (r Redaction) Masked() string {
+	if r.Kind == KindHost || r.Kind == KindIP {
+		return r.Value
+	}
+
+	first := strings.SplitN(r.Value, "\n", 2)[0]
+	if len(first) <= 4 {
+		return "****"
+	}
+	return first[:4] + "****"
+}
+
+// Result is the outcome of redacting a script
+type Result struct {
+	Content    string
+	Redactions []Redaction
+}
+
+// detector finds values of one kind. When group is set only that submatch
+// is redacted, and nameGroup, if set, names the assigned variable.
+type detector struct {
+	kind      Kind
+	pattern   *regexp.Regexp
+	group     int
+	nameGroup int
+}
+
+// minRepeatedValueLength is the shortest value replaced wherever it appears
+const minRepeatedValueLength = 8
+
+// secretName matches variable names that usually hold credentials
+const secretName = `(?:passw(?:or)?d|secret|token|api_?key|access_?key|private_?key|credentials?)`
+
+var (
+	// extraDetectors find values the scanner does not report on its own,
+	// such as passwords in assignments and private IP addresses
+	extraDetectors = []detector{
+		// user:password@ in URLs
+		{kind: KindSecret, pattern: regexp.MustCompile(`://[^/\s:@"']+:([^/\s@"'$]+)@`), group: 1},
+		// PASSWORD="...", "api_key": '...', token := "..."
+		{kind: KindSecret, pattern: regexp.MustCompile(`(?i)\b([a-z0-9_]*` + secretName + `[a-z0-9_]*)["']?\s*(?::=|=|:)\s*["']([^"'\s$]{4,})["']`), group: 2, nameGroup: 1},
+		// shell style TOKEN=abc123
+		{kind: KindSecret, pattern: regexp.MustCompile(`(?im)\b([a-z0-9_]*` + secretName + `[a-z0-9_]*)=([^"'\s$;#()\[\]{}]{4,})(?:[\s;#]|$)`), group: 2, nameGroup: 1},
+		{kind: KindIP, pattern: regexp.MustCompile(`\b(?:10\.\d{1,3}|172\.(?:1[6-9]|2\d|3[01])|192\.168)\.\d{1,3}\.\d{1,3}\b`)},
+	}
+
+	// ruleKinds classifies the values found by the scanner's rules; other
+	// rules find tokens
+	ruleKinds = map[string]Kind{
+		detect.PrivateKeyRuleID:     KindPrivateKey,
+		detect.EmailRuleID:          KindEmail,
+		detect.InternalDomainRuleID: KindHost,
+		"aws-secret-access-key":     KindSecret,
+		"azure-storage-key":         KindSecret,
+		"gcp-service-account":       KindSecret,
+	}
+
+	// placeholderPattern matches placeholders produced by the Redactor
+	placeholderPattern = regexp.MustCompile(`__GS_[A-Z_]+_\d+__`)
+)
+
+// Redactor replaces secrets, tokens, private keys, email addresses,
+// internal hostnames and private IP addresses with stable placeholders. It
+// covers everything the sensitive-data scanner reports.
+type Redactor struct {
+	detectors []detector
+}
+
+// NewRedactor creates a Redactor. internalDomains lists additional domains,
+// such as "corp.example.com", whose hosts are treated as internal.
+func NewRedactor(internalDomains []string) *Redactor {
+	var detectors []detector
+	for _, rule := range detect.Rules(internalDomains) {
+		kind, ok := ruleKinds[rule.ID]
+		if !ok {
+			kind = KindToken
+		}
+		detectors = append(detectors, detector{kind: kind, pattern: rule.Pattern, group: rule.Group})
+	}
+	detectors = append(detectors, extraDetectors...)
+
+	return &Redactor{detectors: detectors}
+}
+
+// span is a matched value within the content
+type span struct {
+	start, end int
+	kind       Kind
+	name       string
+}
+
+// Redact replaces every detected value in content with a placeholder.
+// The same value always maps to the same placeholder, including occurrences
+// that were not themselves detected, such as a password echoed later on.
+func (r *Redactor) Redact(content string) *Result {
+	var detected []span
+	for _, d := range r.detectors {
+		for _, m := range d.pattern.FindAllStringSubmatchIndex(content, -1) {
+			s := span{start: m[0], end: m[1], kind: d.kind}
+			if d.group > 0 {
+				s.start, s.end = m[2*d.group], m[2*d.group+1]
+			}
+			if d.nameGroup > 0 {
+				s.name = content[m[2*d.nameGroup]:m[2*d.nameGroup+1]]
+			}
+			if s.start >= 0 && s.end > s.start && !continuesHostname(content, s) {
+				detected = append(detected, s)
+			}
+		}
+	}
+	// High-entropy strings the patterns did not cover are secrets too
+	for _, m := range detect.HighEntropy(content) {
+		if !overlapsSpan(detected, m[0], m[1]) {
+			detected = append(detected, span{start: m[0], end: m[1], kind: KindSecret})
+		}
+	}
+	sortSpans(detected)
+
+	// Assign placeholders to distinct values in order of appearance
+	var (
+		redactions  []Redaction
+		occurrences []span
+		byValue     = map[string]int{}
+		counters    = map[Kind]int{}
+		usedNames   = map[string]bool{}
+		last        int
+	)
+	for _, s := range detected {
+		if s.start < last {
+			continue
+		}
+		last = s.end
+
+		value := content[s.start:s.end]
+		if placeholderPattern.MatchString(value) {
+			continue
+		}
+
+		occurrences = append(occurrences, s)
+		if _, ok := byValue[value]; ok {
+			continue
+		}
+
+		// Names become environment variables, so each may only be used once
+		if usedNames[strings.ToUpper(s.name)] {
+			s.name = ""
+		}
+		usedNames[strings.ToUpper(s.name)] = s.name != ""
+
+		counters[s.kind]++
+		redactions = append(redactions, Redaction{
+			Placeholder: placeholder(s.kind, counters[s.kind]),
+			Kind:        s.kind,
+			Value:       value,
+			Name:        s.name,
+			Line:        strings.Count(content[:s.start], "\n") + 1,
+		})
+		byValue[value] = len(redactions) - 1
+	}
+
+	// Replace other occurrences of each value too. Short values are only
+	// replaced where they were detected, so common words stay intact.
+	for value := range byValue {
+		if len(value) < minRepeatedValueLength {
+			continue
+		}
+		for i := 0; ; {
+			j := strings.Index(content[i:], value)
+			if j < 0 {
+				break
+			}
+			occurrences = append(occurrences, span{start: i + j, end: i + j + len(value)})
+			i += j + len(value)
+		}
+	}
+	sortSpans(occurrences)
+
+	var b strings.Builder
+	last = 0
+	for _, s := range occurrences {
+		if s.start < last {
+			continue
+		}
+
+		idx := byValue[content[s.start:s.end]]
+		redactions[idx].Count++
+
+		b.WriteString(content[last:s.start])
+		b.WriteString(redactions[idx].Placeholder)
+		last = s.end
+	}
+	b.WriteString(content[last:])
+
+	return &Result{
+		Content:    b.String(),
+		Redactions: redactions,
+	}
+}
+
+// sortSpans orders spans so that the earliest, then longest, span comes first
+func sortSpans(spans []span) {
+	sort.Slice(spans, func(i, j int) bool {
+		if spans[i].start != spans[j].start {
+			return spans[i].start < spans[j].start
+		}
+		return spans[i].end > spans[j].end
+	})
+}
+
+// overlapsSpan reports whether start..end overlaps one of the spans
+func overlapsSpan(spans []span, start, end int) bool {
+	for _, s := range spans {
+		if start < s.end && s.start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// continuesHostname reports whether a host match is only the start of a
+// longer hostname, like "build.corp" in "build.corp.example.com"
+func continuesHostname(content string, s span) bool {
+	if s.kind != KindHost || s.end+1 >= len(content) || content[s.end] != '.' {
+		return false
+	}
+	c := content[s.end+1]
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// placeholder returns the placeholder for the n-th value of a kind
+func placeholder(kind Kind, n int) string {
+	name := strings.ToUpper(strings.ReplaceAll(string(kind), "-", "_"))
+	return fmt.Sprintf("__GS_%s_%d__", name, n)
+}
@@ -0,0 +1,215 @@
+package redact
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Policy controls how placeholders are resolved in generated code
+type Policy string
+
+const (
+	// PolicyRestore puts the original values back into the generated code
+	PolicyRestore Policy = "restore"
+	// PolicyEnv replaces placeholders in string literals with os.Getenv lookups
+	PolicyEnv Policy = "env"
+)
+
+// IsValid checks if the policy is valid
+func (p Policy) IsValid() bool {
+	return p == PolicyRestore || p == PolicyEnv
+}
+
+// edit replaces the source between start and end offsets
+type edit struct {
+	start, end int
+	text       string
+}
+
+// Restore resolves the placeholders in generated Go code according to policy.
+// String literals are rewritten through the Go parser so restored values are
+// quoted correctly. Code that does not parse has its placeholders replaced
+// with the original values verbatim under the restore policy; under the env
+// policy the values are never written, and the code is returned with its
+// placeholders and an error.
+func Restore(goCode string, redactions []Redaction, policy Policy) (string, error) {
+	if len(redactions) == 0 {
+		return goCode, nil
+	}
+
+	byPlaceholder := make(map[string]Redaction, len(redactions))
+	for _, r := range redactions {
+		byPlaceholder[r.Placeholder] = r
+	}
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", goCode, parser.ParseComments)
+	if err != nil {
+		if policy == PolicyEnv {
+			return goCode, fmt.Errorf("generated code does not parse, so its redacted values cannot be read from the environment: %w", err)
+		}
+		return restoreText(goCode, byPlaceholder), nil
+	}
+
+	var (
+		edits   []edit
+		usesEnv bool
+	)
+
+	offset := func(pos token.Pos) int {
+		return fset.Position(pos).Offset
+	}
+
+	for _, decl := range file.Decls {
+		gen, isGen := decl.(*ast.GenDecl)
+		if isGen && gen.Tok == token.IMPORT {
+			continue
+		}
+
+		// os.Getenv calls are not constant, so constant declarations become variables
+		constRewritten := false
+
+		ast.Inspect(decl, func(n ast.Node) bool {
+			lit, ok := n.(*ast.BasicLit)
+			if !ok || lit.Kind != token.STRING || !placeholderPattern.MatchString(lit.Value) {
+				return true
+			}
+
+			value, err := strconv.Unquote(lit.Value)
+			if err != nil {
+				return true
+			}
+
+			var text string
+			if policy == PolicyEnv {
+				text = envExpression(value, byPlaceholder)
+				usesEnv = true
+				if isGen && gen.Tok == token.CONST && !constRewritten {
+					edits = append(edits, edit{offset(gen.TokPos), offset(gen.TokPos) + len("const"), "var"})
+					constRewritten = true
+				}
+			} else {
+				text = quoteLike(lit.Value, replacePlaceholders(value, byPlaceholder, PolicyRestore))
+			}
+
+			edits = append(edits, edit{offset(lit.Pos()), offset(lit.End()), text})
+			return true
+		})
+	}
+
+	// Placeholders left in comments are restored or named after their variable
+	for _, group := range file.Comments {
+		for _, c := range group.List {
+			if placeholderPattern.MatchString(c.Text) {
+				edits = append(edits, edit{offset(c.Pos()), offset(c.End()), replacePlaceholders(c.Text, byPlaceholder, policy)})
+			}
+		}
+	}
+
+	if usesEnv && !importsPackage(file, "os") {
+		edits = append(edits, importEdit(goCode, file, offset))
+	}
+
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start > edits[j].start })
+
+	result := goCode
+	for _, e := range edits {
+		result = result[:e.start] + e.text + result[e.end:]
+	}
+
+	return result, nil
+}
+
+// restoreText replaces placeholders with their original values outside of any syntax
+func restoreText(goCode string, byPlaceholder map[string]Redaction) string {
+	return placeholderPattern.ReplaceAllStringFunc(goCode, func(p string) string {
+		if r, ok := byPlaceholder[p]; ok {
+			return r.Value
+		}
+		return p
+	})
+}
+
+// replacePlaceholders substitutes placeholders in s with original values
+// (restore policy) or with $NAME references (env policy)
+func replacePlaceholders(s string, byPlaceholder map[string]Redaction, policy Policy) string {
+	return placeholderPattern.ReplaceAllStringFunc(s, func(p string) string {
+		r, ok := byPlaceholder[p]
+		if !ok {
+			return p
+		}
+		if policy == PolicyEnv {
+			return "$" + r.EnvVar()
+		}
+		return r.Value
+	})
+}
+
+// envExpression turns a string containing placeholders into a concatenation
+// of string literals and os.Getenv calls
+func envExpression(value string, byPlaceholder map[string]Redaction) string {
+	var parts []string
+	last := 0
+	for _, loc := range placeholderPattern.FindAllStringIndex(value, -1) {
+		r, ok := byPlaceholder[value[loc[0]:loc[1]]]
+		if !ok {
+			continue
+		}
+		if loc[0] > last {
+			parts = append(parts, strconv.Quote(value[last:loc[0]]))
+		}
+		parts = append(parts, fmt.Sprintf("os.Getenv(%q)", r.EnvVar()))
+		last = loc[1]
+	}
+	if last < len(value) {
+		parts = append(parts, strconv.Quote(value[last:]))
+	}
+
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return "(" + strings.Join(parts, " + ") + ")"
+}
+
+// quoteLike quotes value using the same literal style as original where possible
+func quoteLike(original, value string) string {
+	if strings.HasPrefix(original, "`") && !strings.Contains(value, "`") {
+		return "`" + value + "`"
+	}
+	return strconv.Quote(value)
+}
+
+// importsPackage reports whether file imports path
+func importsPackage(file *ast.File, path string) bool {
+	for _, imp := range file.Imports {
+		if p, err := strconv.Unquote(imp.Path.Value); err == nil && p == path {
+			return true
+		}
+	}
+	return false
+}
+
+// importEdit returns an edit adding an "os" import to file
+func importEdit(goCode string, file *ast.File, offset func(token.Pos) int) edit {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		if gen.Lparen.IsValid() {
+			pos := offset(gen.Lparen) + 1
+			return edit{pos, pos, "\n\t\"os\""}
+		}
+		// Turn a single import into a grouped one
+		spec := goCode[offset(gen.Specs[0].Pos()):offset(gen.Specs[0].End())]
+		return edit{offset(gen.Pos()), offset(gen.End()), "import (\n\t\"os\"\n\t" + spec + "\n)"}
+	}
+
+	pos := offset(file.Name.End())
+	return edit{pos, pos, "\n\nimport \"os\""}
+}
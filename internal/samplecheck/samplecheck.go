@@ -0,0 +1,167 @@
+package samplecheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Timeout bounds each run of the script or the Go program
+const Timeout = 30 * time.Second
+
+// Result compares the output of the script and the Go program on one
+// sample input
+type Result struct {
+	Input string `json:"input"`
+	Match bool   `json:"match"`
+	// Line is the first output line that differs, with the script's (Want)
+	// and the Go program's (Got) version of it
+	Line int    `json:"line,omitempty"`
+	Want string `json:"want,omitempty"`
+	Got  string `json:"got,omitempty"`
+	// WantStatus and GotStatus are the exit statuses
+	WantStatus int `json:"wantStatus"`
+	GotStatus  int `json:"gotStatus"`
+}
+
+// ScriptCommand returns the command line that runs the script at path: the
+// interpreter and arguments of its shebang, or fallback when it has none.
+// The path is made absolute, since the script runs in another directory.
+func ScriptCommand(path, content string, fallback []string) []string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+
+	firstLine, _, _ := strings.Cut(content, "\n")
+	fields := strings.Fields(strings.TrimPrefix(strings.TrimSpace(firstLine), "#!"))
+	if !strings.HasPrefix(firstLine, "#!") || len(fields) == 0 {
+		return append(append([]string(nil), fallback...), path)
+	}
+
+	// The interpreter is looked up in PATH, since /usr/bin/awk and /bin/sed
+	// are not where every system installs them
+	if filepath.Base(fields[0]) == "env" {
+		fields = fields[1:]
+		for len(fields) > 0 && (strings.HasPrefix(fields[0], "-") || strings.Contains(fields[0], "=")) {
+			fields = fields[1:]
+		}
+		if len(fields) == 0 {
+			return append(append([]string(nil), fallback...), path)
+		}
+	}
+
+	command := []string{filepath.Base(fields[0])}
+	command = append(command, fields[1:]...)
+	return append(command, path)
+}
+
+// Check runs script and binary on each input, fed through stdin, and
+// compares their standard output and exit status. Each run has its own
+// empty working directory so that files the programs write do not collide.
+func Check(script []string, binary string, inputs []string) ([]Result, error) {
+	results := make([]Result, 0, len(inputs))
+	for _, input := range inputs {
+		want, wantStatus, err := run(script, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run %s on %s: %w", script[0], input, err)
+		}
+		got, gotStatus, err := run([]string{binary}, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run the Go program on %s: %w", input, err)
+		}
+
+		r := Result{Input: input, WantStatus: wantStatus, GotStatus: gotStatus}
+		r.Match = want == got && wantStatus == gotStatus
+		if want != got {
+			r.Line, r.Want, r.Got = firstDifference(want, got)
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// run executes command with input on stdin and returns its standard output
+// and exit status. Relative paths are resolved before the command changes
+// to its own working directory.
+func run(command []string, input string) (string, int, error) {
+	command = append([]string(nil), command...)
+	if strings.ContainsRune(command[0], filepath.Separator) {
+		abs, err := filepath.Abs(command[0])
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to resolve %s: %w", command[0], err)
+		}
+		command[0] = abs
+	}
+
+	in, err := os.Open(input)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to open sample input: %w", err)
+	}
+	defer in.Close()
+
+	dir, err := os.MkdirTemp("", "gopherscript-sample-")
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to create working directory: %w", err)
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), Timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, command[0], command[1:]...)
+	cmd.Dir = dir
+	cmd.Stdin = in
+	cmd.Stdout = &stdout
+
+	err = cmd.Run()
+	if ctx.Err() != nil {
+		return "", 0, fmt.Errorf("timed out after %s", Timeout)
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return "", 0, err
+	}
+	return stdout.String(), 0, nil
+}
+
+// firstDifference returns the 1-based number of the first line that
+// differs between want and got, and both versions of it
+func firstDifference(want, got string) (int, string, string) {
+	wantLines := strings.SplitAfter(want, "\n")
+	gotLines := strings.SplitAfter(got, "\n")
+	for i := 0; ; i++ {
+		w, g := lineAt(wantLines, i), lineAt(gotLines, i)
+		if w != g {
+			return i + 1, describe(w), describe(g)
+		}
+	}
+}
+
+// lineAt returns line i including its newline, or "" past the end
+func lineAt(lines []string, i int) string {
+	if i < len(lines) {
+		return lines[i]
+	}
+	return ""
+}
+
+// describe makes a line printable, noting a missing final newline
+func describe(line string) string {
+	switch {
+	case line == "":
+		return "<end of output>"
+	case !strings.HasSuffix(line, "\n"):
+		return fmt.Sprintf("%q (no newline)", line)
+	}
+	return fmt.Sprintf("%q", strings.TrimSuffix(line, "\n"))
+}
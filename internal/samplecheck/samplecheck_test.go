@@ -0,0 +1,116 @@
+package samplecheck
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestScriptCommand(t *testing.T) {
+	fallback := []string{"awk", "-f"}
+
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"{ print $1 }\n", []string{"awk", "-f", "x.awk"}},
+		{"#!/usr/bin/awk -f\n{ print $1 }\n", []string{"awk", "-f", "x.awk"}},
+		{"#!/usr/bin/env -S gawk -F: -f\n{ print $1 }\n", []string{"gawk", "-F:", "-f", "x.awk"}},
+		{"#!/bin/sed -nf\np\n", []string{"sed", "-nf", "x.awk"}},
+	}
+
+	abs, err := filepath.Abs("x.awk")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tt := range tests {
+		tt.want[len(tt.want)-1] = abs
+		if got := ScriptCommand("x.awk", tt.content, fallback); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ScriptCommand(%q) = %v, want %v", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not available")
+	}
+
+	dir := t.TempDir()
+	input := filepath.Join(dir, "input.txt")
+	if err := os.WriteFile(input, []byte("a 1\nb 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	script := []string{sh, "-c", "cat"}
+	same := writeScript(t, dir, "same", "#!/bin/sh\ncat\n")
+	different := writeScript(t, dir, "different", "#!/bin/sh\nread line\necho \"$line\"\necho c 3\nexit 1\n")
+
+	results, err := Check(script, same, []string{input})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Result{{Input: input, Match: true}}; !reflect.DeepEqual(results, want) {
+		t.Errorf("Check(same) = %+v, want %+v", results, want)
+	}
+
+	results, err = Check(script, different, []string{input})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Result{{Input: input, Line: 2, Want: `"b 2"`, Got: `"c 3"`, GotStatus: 1}}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("Check(different) = %+v, want %+v", results, want)
+	}
+}
+
+func TestCheck_RelativePaths(t *testing.T) {
+	if _, err := exec.LookPath("awk"); err != nil {
+		t.Skip("awk not available")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "sum.awk"), []byte("{ n += $2 } END { print n }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "input.txt"), []byte("a 1\nb 2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	writeScript(t, dir, "sum", "#!/bin/sh\necho 3\n")
+	t.Chdir(dir)
+
+	script := ScriptCommand("sum.awk", "{ n += $2 } END { print n }\n", []string{"awk", "-f"})
+	results, err := Check(script, "./sum", []string{"input.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []Result{{Input: "input.txt", Match: true}}; !reflect.DeepEqual(results, want) {
+		t.Errorf("Check() = %+v, want %+v", results, want)
+	}
+}
+
+func TestFirstDifference(t *testing.T) {
+	line, want, got := firstDifference("a\nb\n", "a\n")
+	if line != 2 || want != `"b"` || got != "<end of output>" {
+		t.Errorf("firstDifference() = %d, %s, %s", line, want, got)
+	}
+
+	line, want, got = firstDifference("a\n", "a")
+	if line != 1 || want != `"a"` || got != `"a" (no newline)` {
+		t.Errorf("firstDifference() = %d, %s, %s", line, want, got)
+	}
+}
+
+// writeScript writes an executable shell script standing in for a Go binary
+func writeScript(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
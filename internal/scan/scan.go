@@ -0,0 +1,195 @@
+package scan
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/bonzonkim/gopher-script/internal/detect"
+)
+
+// Finding is a piece of sensitive data found in a script
+type Finding struct {
+	File        string `json:"file"`
+	Line        int    `json:"line"`
+	Column      int    `json:"column"`
+	RuleID      string `json:"rule"`
+	Description string `json:"description"`
+	Match       string `json:"match"`
+
+	// value is the unmasked match, kept out of reports
+	value string
+}
+
+// Value returns the unmasked matched text
+func (f Finding) Value() string {
+	return f.value
+}
+
+// AllowRule allowlists findings. Empty fields match anything; Path is a
+// glob matched against the file path and its base name.
+type AllowRule struct {
+	Rule  string `json:"rule,omitempty"`
+	Path  string `json:"path,omitempty"`
+	Value string `json:"value,omitempty"`
+}
+
+// Matches reports whether the rule allowlists the finding
+func (a AllowRule) Matches(f Finding) bool {
+	if a.Rule != "" && a.Rule != f.RuleID {
+		return false
+	}
+	if a.Value != "" && a.Value != f.value {
+		return false
+	}
+	if a.Path != "" {
+		full, _ := filepath.Match(a.Path, filepath.ToSlash(f.File))
+		base, _ := filepath.Match(a.Path, filepath.Base(f.File))
+		if !full && !base {
+			return false
+		}
+	}
+	return true
+}
+
+// Scanner finds sensitive data in script content
+type Scanner struct {
+	rules []detect.Rule
+	allow []AllowRule
+}
+
+// NewScanner creates a Scanner. internalDomains lists additional domains
+// reported as internal; allow lists findings that are accepted.
+func NewScanner(internalDomains []string, allow []AllowRule) *Scanner {
+	return &Scanner{
+		rules: detect.Rules(internalDomains),
+		allow: allow,
+	}
+}
+
+// Scan returns the findings in content that are not allowlisted, ordered by position
+func (s *Scanner) Scan(path, content string) []Finding {
+	var (
+		findings []Finding
+		covered  []span
+	)
+
+	add := func(rule, description string, start, end int) {
+		f := Finding{
+			File:        path,
+			RuleID:      rule,
+			Description: description,
+			value:       content[start:end],
+		}
+		f.Line, f.Column = position(content, start)
+		f.Match = mask(f.value)
+
+		for _, a := range s.allow {
+			if a.Matches(f) {
+				return
+			}
+		}
+		findings = append(findings, f)
+	}
+
+	for _, rule := range s.rules {
+		for _, m := range rule.Pattern.FindAllStringSubmatchIndex(content, -1) {
+			start, end := m[0], m[1]
+			if rule.Group > 0 {
+				start, end = m[2*rule.Group], m[2*rule.Group+1]
+			}
+			// A match inside an earlier, more specific match is not reported again
+			if within(covered, start, end) {
+				continue
+			}
+			covered = append(covered, span{start, end})
+			add(rule.ID, rule.Description, start, end)
+		}
+	}
+
+	for _, m := range detect.HighEntropy(content) {
+		if !overlaps(covered, m[0], m[1]) {
+			add(detect.EntropyRuleID, "High-entropy string (possible secret)", m[0], m[1])
+		}
+	}
+
+	sort.SliceStable(findings, func(i, j int) bool {
+		if findings[i].Line != findings[j].Line {
+			return findings[i].Line < findings[j].Line
+		}
+		return findings[i].Column < findings[j].Column
+	})
+
+	return findings
+}
+
+// span is a matched range of the content
+type span struct{ start, end int }
+
+// within reports whether start..end lies inside one of the spans
+func within(spans []span, start, end int) bool {
+	for _, s := range spans {
+		if s.start <= start && end <= s.end {
+			return true
+		}
+	}
+	return false
+}
+
+// overlaps reports whether start..end overlaps one of the spans
+func overlaps(spans []span, start, end int) bool {
+	for _, s := range spans {
+		if start < s.end && s.start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// position returns the 1-based line and column of offset in content
+func position(content string, offset int) (int, int) {
+	line := strings.Count(content[:offset], "\n") + 1
+	column := offset - strings.LastIndex(content[:offset], "\n")
+	return line, column
+}
+
+// mask hides all but the first characters of a value
+func mask(value string) string {
+	if len(value) <= 4 {
+		return "****"
+	}
+	return value[:4] + "****"
+}
+
+// BlockedError is returned when sensitive data stops a transpilation
+type BlockedError struct {
+	Findings []Finding
+}
+
+func (e *BlockedError) Error() string {
+	return fmt.Sprintf("%d sensitive finding(s) in script; pass --allow-sensitive or allowlist them to continue", len(e.Findings))
+}
+
+// WriteText writes findings in a compiler-like "file:line:column" format
+func WriteText(w io.Writer, findings []Finding) error {
+	for _, f := range findings {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s: %s (%s)\n", f.File, f.Line, f.Column, f.RuleID, f.Description, f.Match); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteJSON writes findings as a JSON array
+func WriteJSON(w io.Writer, findings []Finding) error {
+	if findings == nil {
+		findings = []Finding{}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(findings)
+}
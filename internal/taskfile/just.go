@@ -0,0 +1,285 @@
+package taskfile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// justAssignment matches a variable assignment
+	justAssignment = regexp.MustCompile(`^(export\s+)?([A-Za-z_][\w-]*)\s*:=\s*(.*)$`)
+	// justAlias matches an alias declaration
+	justAlias = regexp.MustCompile(`^alias\s+([A-Za-z_][\w-]*)\s*:=\s*([A-Za-z_][\w-]*)\s*$`)
+	// justSetting matches a setting, with or without a value
+	justSetting = regexp.MustCompile(`^set\s+([\w-]+)(?:\s*:=\s*(.*))?$`)
+	// justName matches recipe and parameter names
+	justName = regexp.MustCompile(`^[A-Za-z_][\w-]*$`)
+)
+
+// parseJust parses a justfile. Settings, attributes and imports other than
+// export and quiet are not kept, and a note records each of them.
+func parseJust(content string) (*File, error) {
+	f := &File{Kind: KindJust}
+	lines, numbers := logicalLines(content)
+
+	var (
+		current     *Target
+		indent      string
+		doc         string
+		attributes  []string
+		aliases     [][2]string
+		exportAll   bool
+		quietAll    bool
+		quietRecipe = make(map[*Target]bool)
+	)
+	for i, line := range lines {
+		number := numbers[i]
+		trimmed := strings.TrimSpace(line)
+
+		// Body lines are indented and belong to the last recipe
+		if current != nil && trimmed != "" && (line[0] == ' ' || line[0] == '\t') {
+			if indent == "" {
+				indent = line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+			}
+			text := strings.TrimPrefix(line, indent)
+			if len(current.Recipe) == 0 && current.Shebang == "" && strings.HasPrefix(text, "#!") {
+				current.Shebang = text
+				continue
+			}
+			if current.Shebang != "" {
+				current.Recipe = append(current.Recipe, RecipeLine{Text: text, Line: number})
+				continue
+			}
+			current.Recipe = append(current.Recipe, justRecipeLine(text, number))
+			continue
+		}
+		if trimmed == "" {
+			doc = ""
+			continue
+		}
+		current, indent = nil, ""
+
+		switch {
+		case strings.HasPrefix(trimmed, "#!") && number == 1:
+			continue
+		case strings.HasPrefix(trimmed, "#"):
+			doc = strings.TrimSpace(strings.TrimPrefix(trimmed, "#"))
+			continue
+		case strings.HasPrefix(trimmed, "["):
+			for _, attribute := range strings.Split(strings.Trim(trimmed, "[]"), ",") {
+				attribute = strings.TrimSpace(attribute)
+				if strings.HasPrefix(attribute, "doc(") {
+					doc = unquoteJust(strings.TrimSuffix(strings.TrimPrefix(attribute, "doc("), ")"))
+					continue
+				}
+				attributes = append(attributes, attribute)
+			}
+			continue
+		}
+		lineDoc := doc
+		doc = ""
+
+		if m := justSetting.FindStringSubmatch(trimmed); m != nil {
+			enabled := m[2] == "" || strings.TrimSpace(m[2]) == "true"
+			switch m[1] {
+			case "export":
+				exportAll = enabled
+			case "quiet":
+				quietAll = enabled
+			default:
+				f.note(number, "setting %q is not kept", trimmed)
+			}
+			continue
+		}
+		if m := justAlias.FindStringSubmatch(trimmed); m != nil {
+			aliases = append(aliases, [2]string{m[1], m[2]})
+			continue
+		}
+		if strings.HasPrefix(trimmed, "import ") || strings.HasPrefix(trimmed, "import? ") || strings.HasPrefix(trimmed, "mod ") || strings.HasPrefix(trimmed, "mod? ") {
+			f.note(number, "%q is not followed; its recipes are not part of this script", trimmed)
+			continue
+		}
+		if m := justAssignment.FindStringSubmatch(trimmed); m != nil {
+			f.Variables = append(f.Variables, Variable{
+				Name: m[2], Op: ":=", Value: strings.TrimSpace(m[3]),
+				Export: m[1] != "", Line: number,
+			})
+			continue
+		}
+
+		t, quiet, err := parseJustHeader(trimmed, number)
+		if err != nil {
+			return nil, err
+		}
+		if f.Target(t.Name) != nil {
+			return nil, fmt.Errorf("recipe %s on line %d is already defined", t.Name, number)
+		}
+		t.Doc = lineDoc
+		for _, attribute := range attributes {
+			f.note(number, "attribute [%s] of recipe %s is not kept", attribute, t.Name)
+		}
+		attributes = nil
+		quietRecipe[t] = quiet
+		f.Targets = append(f.Targets, t)
+		current = t
+	}
+
+	for _, alias := range aliases {
+		t := f.Target(alias[1])
+		if t == nil {
+			return nil, fmt.Errorf("alias %s refers to unknown recipe %s", alias[0], alias[1])
+		}
+		t.Aliases = append(t.Aliases, alias[0])
+	}
+	for i := range f.Variables {
+		f.Variables[i].Export = f.Variables[i].Export || exportAll
+	}
+	for _, t := range f.Targets {
+		// A quiet recipe (@name) prints only its @ lines, the reverse of
+		// the default
+		if quietRecipe[t] != quietAll {
+			for i := range t.Recipe {
+				t.Recipe[i].Quiet = !t.Recipe[i].Quiet
+			}
+		}
+		if exportAll {
+			for i := range t.Params {
+				t.Params[i].Export = true
+			}
+		}
+	}
+	if len(f.Targets) > 0 {
+		f.Default = f.Targets[0].Name
+	}
+	return f, nil
+}
+
+// parseJustHeader parses a recipe line: its name, parameters and
+// dependencies. quiet reports an @ before the name.
+func parseJustHeader(line string, number int) (*Target, bool, error) {
+	colon := -1
+	depth, quote := 0, byte(0)
+	for i := 0; i < len(line) && colon < 0; i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case c == ':' && depth == 0 && (i+1 == len(line) || line[i+1] != '='):
+			colon = i
+		}
+	}
+	if colon < 0 {
+		return nil, false, fmt.Errorf("line %d is not a recipe, variable or setting: %s", number, line)
+	}
+
+	words := splitJustWords(line[:colon])
+	quiet := strings.HasPrefix(words[0], "@")
+	t := &Target{Name: strings.TrimPrefix(words[0], "@"), Phony: true, Line: number}
+	if !justName.MatchString(t.Name) {
+		return nil, false, fmt.Errorf("invalid recipe name on line %d: %s", number, t.Name)
+	}
+	for _, word := range words[1:] {
+		var p Param
+		if strings.HasPrefix(word, "+") || strings.HasPrefix(word, "*") {
+			p.Variadic, word = word[:1], word[1:]
+		}
+		if strings.HasPrefix(word, "$") {
+			p.Export, word = true, word[1:]
+		}
+		if eq := strings.Index(word, "="); eq >= 0 {
+			word, p.Default, p.HasDefault = word[:eq], word[eq+1:], true
+		}
+		p.Name = word
+		if !justName.MatchString(p.Name) {
+			return nil, false, fmt.Errorf("invalid parameter of recipe %s on line %d: %s", t.Name, number, word)
+		}
+		t.Params = append(t.Params, p)
+	}
+
+	after := false
+	for _, word := range splitJustWords(line[colon+1:]) {
+		if word == "&&" {
+			after = true
+			continue
+		}
+		if after {
+			t.After = append(t.After, word)
+		} else {
+			t.Deps = append(t.Deps, word)
+		}
+	}
+	return t, quiet, nil
+}
+
+// justRecipeLine strips the @ and - prefixes of a recipe line
+func justRecipeLine(text string, number int) RecipeLine {
+	r := RecipeLine{Line: number}
+	for {
+		switch {
+		case strings.HasPrefix(text, "@"):
+			r.Quiet = true
+		case strings.HasPrefix(text, "-"):
+			r.IgnoreErrors = true
+		default:
+			r.Text = text
+			return r
+		}
+		text = text[1:]
+	}
+}
+
+// splitJustWords splits s at spaces outside quotes and parentheses
+func splitJustWords(s string) []string {
+	var words []string
+	var word strings.Builder
+	depth, quote := 0, byte(0)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'' || c == '`':
+			quote = c
+		case c == '(':
+			depth++
+		case c == ')':
+			depth--
+		case (c == ' ' || c == '\t') && depth == 0:
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			continue
+		}
+		word.WriteByte(c)
+	}
+	if word.Len() > 0 {
+		words = append(words, word.String())
+	}
+	return words
+}
+
+// unquoteJust returns the content of a just string literal, or s when it
+// is not one
+func unquoteJust(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) < 2 || s[0] != s[len(s)-1] || (s[0] != '"' && s[0] != '\'') {
+		return s
+	}
+	body := s[1 : len(s)-1]
+	if s[0] == '\'' {
+		return body
+	}
+	return strings.NewReplacer(`\n`, "\n", `\t`, "\t", `\"`, `"`, `\\`, `\`).Replace(body)
+}
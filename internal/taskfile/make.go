@@ -0,0 +1,372 @@
+package taskfile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// makeAssignment matches a variable assignment, with its optional
+	// export and override prefixes
+	makeAssignment = regexp.MustCompile(`^((?:(?:export|override)\s+)*)([^\s:#=?+!$()]+)\s*(::=|:=|\?=|\+=|!=|=)\s*(.*)$`)
+	// makeDefine matches the start of a multi-line variable
+	makeDefine = regexp.MustCompile(`^((?:(?:export|override)\s+)*)define\s+(\S+)\s*(::=|:=|\?=|\+=|!=|=)?\s*$`)
+	// makeConditional matches the directives of conditional parts
+	makeConditional = regexp.MustCompile(`^(ifeq|ifneq|ifdef|ifndef|else|endif)\b`)
+	// makeInclude matches include directives
+	makeInclude = regexp.MustCompile(`^-?s?include\s+`)
+)
+
+// makeSpecialNotes are the special targets that change how recipes run
+var makeSpecialNotes = map[string]string{
+	".ONESHELL":             "recipes of every target run in a single shell",
+	".SILENT":               "recipe lines are not printed",
+	".EXPORT_ALL_VARIABLES": "every variable is exported to recipes",
+	".IGNORE":               "recipe failures are ignored",
+	".NOTPARALLEL":          "targets are never built in parallel",
+}
+
+// parseMake parses a Makefile. Conditionals are not evaluated: the
+// assignments and rules in every branch are kept, and a note records each
+// directive.
+func parseMake(content string) (*File, error) {
+	f := &File{Kind: KindMake}
+	lines, numbers := logicalLines(content)
+
+	var (
+		current  []*Target
+		phony    []string
+		exported = make(map[string]bool)
+		values   = make(makeValues)
+		doc      string
+		goal     string
+	)
+	for i := 0; i < len(lines); i++ {
+		line, number := lines[i], numbers[i]
+
+		// Recipe lines start with a tab and belong to the last rule
+		if strings.HasPrefix(line, "\t") {
+			if current != nil {
+				recipe := makeRecipeLine(strings.ReplaceAll(line[1:], "\n\t", "\n"), number)
+				for _, t := range current {
+					t.Recipe = append(t.Recipe, recipe)
+				}
+			}
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			doc = ""
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") {
+			doc = strings.TrimSpace(strings.TrimLeft(trimmed, "#"))
+			continue
+		}
+		text, comment := splitMakeComment(trimmed)
+		text = strings.TrimSpace(strings.ReplaceAll(text, "\\\n", " "))
+		lineDoc := doc
+		doc = ""
+		if text == "" {
+			continue
+		}
+
+		if m := makeDefine.FindStringSubmatch(text); m != nil {
+			var body []string
+			for i+1 < len(lines) && strings.TrimSpace(lines[i+1]) != "endef" {
+				i++
+				body = append(body, lines[i])
+			}
+			i++ // endef
+			op := m[3]
+			if op == "" {
+				op = "="
+			}
+			f.Variables = append(f.Variables, Variable{
+				Name: m[2], Op: op, Value: strings.Join(body, "\n"),
+				Export: strings.Contains(m[1], "export"), Line: number,
+			})
+			values.assign(m[2], op, strings.Join(body, "\n"))
+			current = nil
+			continue
+		}
+
+		if m := makeConditional.FindStringSubmatch(text); m != nil {
+			if m[1] != "endif" && m[1] != "else" {
+				f.note(number, "conditional %q is not evaluated; the assignments and rules of every branch are kept", text)
+			}
+			continue
+		}
+		if makeInclude.MatchString(text) {
+			f.note(number, "%q is not followed; the included files are not part of this script", text)
+			current = nil
+			continue
+		}
+		if fields := strings.Fields(text); fields[0] == "export" || fields[0] == "unexport" {
+			if !strings.ContainsAny(text, "=:") {
+				if len(fields) == 1 {
+					f.note(number, "%q applies to every variable", text)
+				}
+				for _, name := range fields[1:] {
+					exported[name] = fields[0] == "export"
+				}
+				continue
+			}
+		}
+
+		if m := makeAssignment.FindStringSubmatch(text); m != nil {
+			if m[2] == ".DEFAULT_GOAL" {
+				goal = strings.TrimSpace(values.expand(m[4]))
+				continue
+			}
+			f.Variables = append(f.Variables, Variable{
+				Name: m[2], Op: m[3], Value: m[4],
+				Export: strings.Contains(m[1], "export"), Line: number,
+			})
+			values.assign(m[2], m[3], m[4])
+			current = nil
+			continue
+		}
+
+		colon := strings.Index(text, ":")
+		if colon < 0 {
+			f.note(number, "%q is not a rule or an assignment and is ignored", text)
+			current = nil
+			continue
+		}
+		var inline string
+		if semi := strings.Index(text[colon:], ";"); semi >= 0 {
+			text, inline = text[:colon+semi], strings.TrimSpace(text[colon+semi+1:])
+		}
+		// Like make, the target and prerequisite lists are expanded when the
+		// rule is read; recipes are expanded when they run
+		if strings.Contains(text, "$") {
+			expanded := values.expand(text)
+			if strings.Contains(strings.ReplaceAll(expanded, "$$", ""), "$") {
+				f.note(number, "rule %q uses variables or functions that are not defined in the Makefile; they are kept as written", text)
+			}
+			text = expanded
+			if colon = strings.Index(text, ":"); colon < 0 {
+				f.note(number, "%q is not a rule or an assignment and is ignored", text)
+				current = nil
+				continue
+			}
+		}
+		names := strings.Fields(text[:colon])
+		rest := strings.TrimPrefix(text[colon+1:], ":")
+		if makeAssignment.MatchString(strings.TrimSpace(rest)) {
+			f.note(number, "target-specific variable %q is not supported", text)
+			current = nil
+			continue
+		}
+		deps := strings.Fields(strings.ReplaceAll(rest, "|", " "))
+
+		if len(names) == 1 && names[0] == ".PHONY" {
+			phony = append(phony, deps...)
+			current = nil
+			continue
+		}
+		if len(names) > 0 && strings.HasPrefix(names[0], ".") && strings.ToUpper(names[0]) == names[0] {
+			if reason, ok := makeSpecialNotes[names[0]]; ok {
+				f.note(number, "%s: %s", names[0], reason)
+			}
+			current = nil
+			continue
+		}
+		if strings.Contains(text[:colon], "%") {
+			f.note(number, "pattern rule %q is not a target", strings.TrimSpace(text[:colon]))
+			current = nil
+			continue
+		}
+
+		if d := strings.TrimSpace(strings.TrimLeft(comment, "#")); d != "" {
+			lineDoc = d
+		}
+		current = nil
+		for _, name := range names {
+			t := f.Target(name)
+			if t == nil {
+				t = &Target{Name: name, Line: number}
+				f.Targets = append(f.Targets, t)
+			}
+			t.Deps = append(t.Deps, deps...)
+			if t.Doc == "" {
+				t.Doc = lineDoc
+			}
+			if inline != "" {
+				t.Recipe = append(t.Recipe, makeRecipeLine(inline, number))
+			}
+			current = append(current, t)
+		}
+	}
+
+	for i, v := range f.Variables {
+		if export, ok := exported[v.Name]; ok {
+			f.Variables[i].Export = export
+		}
+	}
+	for _, name := range phony {
+		if t := f.Target(name); t != nil {
+			t.Phony = true
+		}
+	}
+	f.Default = goal
+	if f.Default == "" {
+		for _, t := range f.Targets {
+			if !strings.HasPrefix(t.Name, ".") {
+				f.Default = t.Name
+				break
+			}
+		}
+	}
+	return f, nil
+}
+
+// makeValues are the values of the variables assigned so far, as make
+// sees them while reading a Makefile. Recursive (=) values are kept as
+// written and expanded when used.
+type makeValues map[string]makeValue
+
+type makeValue struct {
+	text      string
+	recursive bool
+	// unknown values come from the shell (!=) and cannot be expanded
+	unknown bool
+}
+
+// assign records an assignment with the operator op
+func (v makeValues) assign(name, op, value string) {
+	switch op {
+	case "=":
+		v[name] = makeValue{text: value, recursive: true}
+	case ":=", "::=":
+		v[name] = makeValue{text: v.expand(value)}
+	case "?=":
+		if _, ok := v[name]; !ok {
+			v[name] = makeValue{text: value, recursive: true}
+		}
+	case "+=":
+		old, ok := v[name]
+		switch {
+		case !ok:
+			v[name] = makeValue{text: value, recursive: true}
+		case old.recursive:
+			v[name] = makeValue{text: strings.TrimSpace(old.text + " " + value), recursive: true, unknown: old.unknown}
+		default:
+			v[name] = makeValue{text: strings.TrimSpace(old.text + " " + v.expand(value)), unknown: old.unknown}
+		}
+	case "!=":
+		v[name] = makeValue{unknown: true}
+	}
+}
+
+// expand replaces the references to known variables in text. References
+// to unknown variables and functions are kept as written.
+func (v makeValues) expand(text string) string {
+	return v.expandDepth(text, 0)
+}
+
+func (v makeValues) expandDepth(text string, depth int) string {
+	if depth > 16 {
+		return text
+	}
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		if text[i] != '$' || i+1 == len(text) {
+			sb.WriteByte(text[i])
+			continue
+		}
+		name, end := string(text[i+1]), i+1
+		switch text[i+1] {
+		case '$':
+			sb.WriteString("$$")
+			i++
+			continue
+		case '(', '{':
+			if end = closing(text, i+1); end < 0 {
+				sb.WriteString(text[i:])
+				return sb.String()
+			}
+			name = text[i+2 : end]
+		}
+		// $(VAR:from=to) is a substitution reference
+		var from, to string
+		if colon := strings.Index(name, ":"); colon > 0 {
+			if eq := strings.Index(name[colon:], "="); eq > 0 {
+				name, from, to = name[:colon], name[colon+1:colon+eq], name[colon+eq+1:]
+			}
+		}
+		value, ok := v[name]
+		if !ok || value.unknown || !makeVariableName.MatchString(name) {
+			sb.WriteString(text[i : end+1])
+			i = end
+			continue
+		}
+		expanded := value.text
+		if value.recursive {
+			expanded = v.expandDepth(value.text, depth+1)
+		}
+		if from != "" {
+			expanded = substitute(expanded, from, to)
+		}
+		sb.WriteString(expanded)
+		i = end
+	}
+	return sb.String()
+}
+
+// substitute replaces the suffix from of each word with to, or applies
+// them as patterns when from contains %
+func substitute(words, from, to string) string {
+	if !strings.Contains(from, "%") {
+		from, to = "%"+from, "%"+to
+	}
+	prefix, suffix, _ := strings.Cut(from, "%")
+	fields := strings.Fields(words)
+	for i, w := range fields {
+		if len(w) >= len(prefix)+len(suffix) && strings.HasPrefix(w, prefix) && strings.HasSuffix(w, suffix) {
+			stem := w[len(prefix) : len(w)-len(suffix)]
+			fields[i] = strings.Replace(to, "%", stem, 1)
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// makeRecipeLine strips the @, - and + prefixes of a recipe line
+func makeRecipeLine(text string, number int) RecipeLine {
+	r := RecipeLine{Line: number}
+	for {
+		text = strings.TrimLeft(text, " \t")
+		switch {
+		case strings.HasPrefix(text, "@"):
+			r.Quiet = true
+		case strings.HasPrefix(text, "-"):
+			r.IgnoreErrors = true
+		case strings.HasPrefix(text, "+"):
+		default:
+			r.Text = text
+			return r
+		}
+		text = text[1:]
+	}
+}
+
+// splitMakeComment splits a line at its first unescaped #
+func splitMakeComment(line string) (string, string) {
+	for i := 0; i < len(line); i++ {
+		if line[i] == '\\' {
+			i++
+			continue
+		}
+		if line[i] == '#' {
+			return line[:i], line[i:]
+		}
+	}
+	return line, ""
+}
+
+func (f *File) note(line int, format string, args ...any) {
+	f.Notes = append(f.Notes, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,765 @@
+package taskfile
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var (
+	// makeVariableName matches the inside of a plain variable reference
+	makeVariableName = regexp.MustCompile(`^[^\s:=,$()]+$`)
+	// justIdentifier matches a variable or parameter in a just expression
+	justIdentifier = regexp.MustCompile(`^[A-Za-z_][\w-]*`)
+	// shellSafe matches words that need no quoting in a shell script
+	shellSafe = regexp.MustCompile(`^[\w./+:@%-]+$`)
+)
+
+// makeBuiltins are the built-in make variables the script defines when the
+// Makefile uses them, with the operator that assigns them
+var makeBuiltins = []Variable{
+	{Name: "MAKE", Op: "=", Value: "make"},
+	{Name: "SHELL", Op: "=", Value: "/bin/sh"},
+	{Name: "RM", Op: "?=", Value: "rm -f"},
+	{Name: "CC", Op: "?=", Value: "cc"},
+	{Name: "CXX", Op: "?=", Value: "g++"},
+}
+
+// Script returns a bash script that runs the tasks of f like make or just
+// would, for conversion on the shell path. fileName is the name of the
+// task file, used in usage and error messages.
+func (f *File) Script(fileName string) string {
+	s := &script{f: f, fileName: fileName, functions: make(map[string]string)}
+	if f.Kind == KindJust {
+		s.just()
+	} else {
+		s.make()
+	}
+	return s.b.String()
+}
+
+type script struct {
+	f         *File
+	fileName  string
+	b         strings.Builder
+	notes     []string
+	functions map[string]string
+}
+
+func (s *script) printf(format string, args ...any) {
+	fmt.Fprintf(&s.b, format, args...)
+}
+
+func (s *script) note(line int, format string, args ...any) {
+	s.notes = append(s.notes, fmt.Sprintf("line %d: ", line)+fmt.Sprintf(format, args...))
+}
+
+// function returns the name of the function running a target, unique
+// within the script
+func (s *script) function(prefix, target string) string {
+	if name, ok := s.functions[target]; ok {
+		return name
+	}
+	name := prefix + identifier(target)
+	for taken := true; taken; {
+		taken = false
+		for _, other := range s.functions {
+			if other == name {
+				name += "_"
+				taken = true
+			}
+		}
+	}
+	s.functions[target] = name
+	return name
+}
+
+// header writes the shebang, the usage comment and the notes
+func (s *script) header(summary, usage, kind string, body string) {
+	s.printf("#!/usr/bin/env bash\n")
+	s.printf("# Task runner generated by gopherscript from %s.\n# %s\n", s.fileName, summary)
+	s.printf("#\n# Usage: %s %s\n#\n", s.fileName, usage)
+	s.printf("# %s (default: %s):\n", kind, s.f.Default)
+	width := 0
+	for _, t := range s.f.Targets {
+		width = max(width, len(t.Name))
+	}
+	for _, t := range s.f.Targets {
+		line := fmt.Sprintf("#   %-*s  %s", width, t.Name, t.Doc)
+		if len(t.Aliases) > 0 {
+			line += fmt.Sprintf(" (alias: %s)", strings.Join(t.Aliases, ", "))
+		}
+		s.printf("%s\n", strings.TrimRight(line, " "))
+	}
+	if notes := append(append([]string{}, s.f.Notes...), s.notes...); len(notes) > 0 {
+		s.printf("#\n# Not converted:\n")
+		for _, n := range notes {
+			s.printf("#   %s\n", n)
+		}
+	}
+	s.printf("\n%s", body)
+}
+
+// make writes the script of a Makefile
+func (s *script) make() {
+	var body script
+	body.f, body.fileName, body.functions = s.f, s.fileName, s.functions
+	body.makeBody()
+	s.notes = body.notes
+	s.header("Each target is a target_* function, and make_target runs a target's\n# prerequisites before its recipe and each target at most once.",
+		"[VAR=value ...] [target ...]", "Targets", body.b.String())
+}
+
+func (s *script) makeBody() {
+	var phony []string
+	for _, t := range s.f.Targets {
+		if t.Phony {
+			phony = append(phony, fmt.Sprintf("[%s]=1", shellQuote(t.Name)))
+		}
+	}
+	s.printf("declare -A _overrides=() _done=() _phony=(%s)\n", strings.Join(phony, " "))
+	s.printf(`_goals=()
+for _arg in "$@"; do
+	case $_arg in
+	-*) echo "make: unsupported option: $_arg" >&2; exit 2 ;;
+	*=*) _overrides[${_arg%%%%=*}]=1; export "$_arg" ;;
+	*) _goals+=("$_arg") ;;
+	esac
+done
+
+# assign sets a variable unless it was given on the command line, and
+# assign_default (?=) only sets a variable that is not set at all
+assign() { [[ -n ${_overrides[$1]:-} ]] || printf -v "$1" '%%s' "$2"; }
+assign_default() { [[ -v $1 ]] || printf -v "$1" '%%s' "$2"; }
+
+`)
+
+	var builtins []Variable
+	for _, v := range makeBuiltins {
+		if s.f.references(v.Name) && !s.f.defines(v.Name) {
+			builtins = append(builtins, v)
+		}
+	}
+	if len(builtins) > 0 {
+		s.printf("# Built-in make variables\n")
+		for _, v := range builtins {
+			s.makeAssignment(v)
+		}
+		s.printf("\n")
+	}
+	if len(s.f.Variables) > 0 {
+		s.printf("# Makefile variables\n")
+		for _, v := range s.f.Variables {
+			s.makeAssignment(v)
+		}
+		s.printf("\n")
+	}
+
+	s.printf(`# run_line prints a recipe line and runs it in its own shell, like make.
+# Flags: "@" does not print the line, "-" ignores a failure.
+run_line() {
+	local target=$1 number=$2 flags=$3 cmd=$4 status
+	[[ $flags == *@* ]] || printf '%%s\n' "$cmd"
+	sh -c "$cmd" && return 0
+	status=$?
+	if [[ $flags == *-* ]]; then
+		echo "make: [%[1]s:$number: $target] Error $status (ignored)" >&2
+		return 0
+	fi
+	echo "make: *** [%[1]s:$number: $target] Error $status" >&2
+	exit 2
+}
+
+# up_to_date reports whether the file named by a target exists and is newer
+# than its prerequisites; a phony prerequisite is never up to date
+up_to_date() {
+	local target=$1 prereq
+	shift
+	[[ -e $target ]] || return 1
+	for prereq; do
+		[[ -z ${_phony[$prereq]:-} && ! $prereq -nt $target ]] || return 1
+	done
+}
+
+# make_target runs a target once: its prerequisites, then its recipe
+make_target() {
+	[[ -z ${_done[$1]:-} ]] || return 0
+	_done[$1]=1
+	case $1 in
+`, s.fileName)
+	for _, t := range s.f.Targets {
+		s.printf("\t%s) %s ;;\n", shellQuote(t.Name), s.function("target_", t.Name))
+	}
+	s.printf(`	*)
+		[[ -e $1 ]] && return 0
+		echo "make: *** No rule to make target '$1'.  Stop." >&2
+		exit 2
+		;;
+	esac
+}
+`)
+
+	for _, t := range s.f.Targets {
+		s.printf("\n")
+		if t.Doc != "" {
+			s.printf("# %s\n", t.Doc)
+		}
+		s.printf("%s() {\n", s.function("target_", t.Name))
+		for _, dep := range t.Deps {
+			s.printf("\tmake_target %s\n", shellQuote(dep))
+		}
+		if !t.Phony && len(t.Recipe) > 0 {
+			words := []string{shellQuote(t.Name)}
+			for _, dep := range t.Deps {
+				words = append(words, shellQuote(dep))
+			}
+			s.printf("\tup_to_date %s && return 0\n", strings.Join(words, " "))
+		}
+		for _, r := range t.Recipe {
+			s.printf("\trun_line %s %d %q \"%s\"\n", shellQuote(t.Name), r.Line, flags(r), s.makeText(r.Text, t, r.Line, true))
+		}
+		if len(t.Deps) == 0 && len(t.Recipe) == 0 {
+			s.printf("\t:\n")
+		}
+		s.printf("}\n")
+	}
+
+	s.printf("\n")
+	if s.f.Default == "" {
+		s.printf("echo \"make: *** No targets.  Stop.\" >&2\nexit 2\n")
+		return
+	}
+	s.printf(`(( ${#_goals[@]} )) || _goals=(%s)
+for _goal in "${_goals[@]}"; do
+	make_target "$_goal"
+done
+`, shellQuote(s.f.Default))
+}
+
+// makeAssignment writes the assignment of a Makefile variable
+func (s *script) makeAssignment(v Variable) {
+	name := identifier(v.Name)
+	var value string
+	if v.Op == "!=" {
+		value = "$(" + s.makeText(v.Value, nil, v.Line, false) + ")"
+	} else {
+		value = s.makeText(v.Value, nil, v.Line, true)
+	}
+
+	switch v.Op {
+	case "?=":
+		s.printf("assign_default %s \"%s\"\n", name, value)
+	case "+=":
+		s.printf("assign %[1]s \"${%[1]s:+${%[1]s} }%[2]s\"\n", name, value)
+	case "=":
+		if strings.Contains(v.Value, "$") {
+			s.printf("# %s is expanded when used (=): it sees later changes to the variables it uses\n", name)
+		}
+		s.printf("assign %s \"%s\"\n", name, value)
+	default:
+		s.printf("assign %s \"%s\"\n", name, value)
+	}
+	if v.Export {
+		s.printf("export %s\n", name)
+	}
+}
+
+// makeText converts make text to bash. Variable references become ${NAME},
+// $(shell ...) command substitution and automatic variables the values
+// they have in t. When quoted, the result is the inside of a double-quoted
+// string, so the shell syntax of recipe lines is kept for sh -c.
+func (s *script) makeText(text string, t *Target, line int, quoted bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if c != '$' || i+1 == len(text) {
+			sb.WriteString(literal(c, quoted))
+			continue
+		}
+		i++
+		switch c = text[i]; c {
+		case '$':
+			sb.WriteString(literal('$', quoted))
+		case '(', '{':
+			end := closing(text, i)
+			if end < 0 {
+				sb.WriteString(literal('$', quoted) + text[i:])
+				return sb.String()
+			}
+			sb.WriteString(s.makeReference(text[i+1:end], t, line, quoted))
+			i = end
+		default:
+			sb.WriteString(s.makeReference(string(c), t, line, quoted))
+		}
+	}
+	return sb.String()
+}
+
+// makeReference converts the inside of a $(...) reference
+func (s *script) makeReference(inner string, t *Target, line int, quoted bool) string {
+	if value, ok := automatic(inner, t); ok {
+		return escape(value, quoted)
+	}
+	if cmd, ok := strings.CutPrefix(inner, "shell "); ok {
+		return "$(" + s.makeText(cmd, t, line, false) + ")"
+	}
+	if makeVariableName.MatchString(inner) {
+		if inner == "CURDIR" {
+			return "${PWD}"
+		}
+		return "${" + identifier(inner) + "}"
+	}
+	s.note(line, "make function $(%s) is kept as written", inner)
+	return literal('$', true) + "(" + escape(inner, quoted) + ")"
+}
+
+// automatic returns the value of an automatic variable in target t
+func automatic(name string, t *Target) (string, bool) {
+	if t == nil {
+		return "", false
+	}
+	first := ""
+	if len(t.Deps) > 0 {
+		first = t.Deps[0]
+	}
+	switch name {
+	case "@":
+		return t.Name, true
+	case "@D":
+		return dir(t.Name), true
+	case "@F":
+		return file(t.Name), true
+	case "<":
+		return first, true
+	case "<D":
+		return dir(first), true
+	case "<F":
+		return file(first), true
+	case "^", "?":
+		return strings.Join(unique(t.Deps), " "), true
+	case "+":
+		return strings.Join(t.Deps, " "), true
+	case "*", "%":
+		return "", true
+	}
+	return "", false
+}
+
+// just writes the script of a justfile
+func (s *script) just() {
+	var body script
+	body.f, body.fileName, body.functions = s.f, s.fileName, s.functions
+	body.justBody()
+	s.notes = body.notes
+	s.header("Each recipe is a recipe_* function, and run_recipe runs a recipe's\n# dependencies before its body and each recipe at most once for the same\n# arguments.",
+		"[NAME=value ...] [recipe [argument ...] ...]", "Recipes", body.b.String())
+}
+
+func (s *script) justBody() {
+	var minimum, maximum, aliases []string
+	for _, t := range s.f.Targets {
+		least, most := 0, len(t.Params)
+		for _, p := range t.Params {
+			if !p.HasDefault && p.Variadic != "*" {
+				least++
+			}
+			if p.Variadic != "" {
+				most = -1
+			}
+		}
+		minimum = append(minimum, fmt.Sprintf("[%s]=%d", t.Name, least))
+		maximum = append(maximum, fmt.Sprintf("[%s]=%d", t.Name, most))
+		for _, alias := range t.Aliases {
+			aliases = append(aliases, fmt.Sprintf("[%s]=%s", alias, t.Name))
+		}
+	}
+	s.printf(`declare -A _overrides=() _done=()
+declare -A _min_args=(%s) _max_args=(%s)
+declare -A _aliases=(%s)
+
+# NAME=value arguments before the first recipe override variables
+while (( $# )) && [[ $1 == *=* ]]; do
+	_overrides[${1%%%%=*}]=1
+	printf -v "${1%%%%=*}" '%%s' "${1#*=}"
+	shift
+done
+
+# assign sets a variable unless it was given on the command line
+assign() { [[ -n ${_overrides[$1]:-} ]] || printf -v "$1" '%%s' "$2"; }
+
+`, strings.Join(minimum, " "), strings.Join(maximum, " "), strings.Join(aliases, " "))
+
+	if len(s.f.Variables) > 0 {
+		s.printf("# justfile variables\n")
+		for _, v := range s.f.Variables {
+			s.printf("assign %s \"%s\"\n", identifier(v.Name), s.justExpression(v.Value, v.Line))
+			if v.Export {
+				s.printf("export %s\n", identifier(v.Name))
+			}
+		}
+		s.printf("\n")
+	}
+
+	s.printf(`# run_line prints a recipe line to stderr and runs it in its own shell,
+# like just. Flags: "@" does not print the line, "-" ignores a failure.
+run_line() {
+	local recipe=$1 number=$2 flags=$3 cmd=$4 status
+	[[ $flags == *@* ]] || printf '%%s\n' "$cmd" >&2
+	sh -cu "$cmd" && return 0
+	status=$?
+	[[ $flags == *-* ]] && return 0
+	echo "error: Recipe '$recipe' failed on line $number with exit code $status" >&2
+	exit "$status"
+}
+
+# run_script runs the body of a recipe that starts with #! as one script
+run_script() {
+	local recipe=$1 body=$2 file status=0
+	file=$(mktemp)
+	printf '%%s\n' "$body" >"$file"
+	chmod +x "$file"
+	"$file" || status=$?
+	rm -f "$file"
+	(( status == 0 )) && return 0
+	echo "error: Recipe '$recipe' failed with exit code $status" >&2
+	exit "$status"
+}
+
+# run_recipe runs a recipe once for the same arguments: its dependencies,
+# then its body
+run_recipe() {
+	local key="$*"
+	[[ -z ${_done[$key]:-} ]] || return 0
+	_done[$key]=1
+	local name=$1
+	shift
+	case $name in
+`)
+	for _, t := range s.f.Targets {
+		s.printf("\t%s) %s \"$@\" ;;\n", t.Name, s.function("recipe_", t.Name))
+	}
+	s.printf("\tesac\n}\n")
+
+	for _, t := range s.f.Targets {
+		s.printf("\n")
+		if t.Doc != "" {
+			s.printf("# %s\n", t.Doc)
+		}
+		s.printf("%s() {\n", s.function("recipe_", t.Name))
+		for i, p := range t.Params {
+			name := identifier(p.Name)
+			switch {
+			case p.Variadic != "":
+				s.printf("\tlocal %s=\"${*:%d}\"\n", name, i+1)
+				if p.HasDefault {
+					s.printf("\t(( $# >= %d )) || %s=\"%s\"\n", i+1, name, s.justExpression(p.Default, t.Line))
+				}
+			case p.HasDefault:
+				s.printf("\tlocal %s=\"%s\"\n\t(( $# < %d )) || %[1]s=$%[3]d\n", name, s.justExpression(p.Default, t.Line), i+1)
+			default:
+				s.printf("\tlocal %s=$%d\n", name, i+1)
+			}
+			if p.Export {
+				s.printf("\texport %s\n", name)
+			}
+		}
+		for _, dep := range t.Deps {
+			s.printf("\t%s\n", s.justDependency(dep, t.Line))
+		}
+		if t.Shebang != "" {
+			lines := []string{escape(t.Shebang, true)}
+			for _, r := range t.Recipe {
+				lines = append(lines, s.justText(r.Text, r.Line))
+			}
+			s.printf("\trun_script %s \"%s\"\n", t.Name, strings.Join(lines, "\n"))
+		}
+		for _, r := range t.Recipe {
+			if t.Shebang == "" {
+				s.printf("\trun_line %s %d %q \"%s\"\n", t.Name, r.Line, flags(r), s.justText(r.Text, r.Line))
+			}
+		}
+		for _, dep := range t.After {
+			s.printf("\t%s\n", s.justDependency(dep, t.Line))
+		}
+		if len(t.Params)+len(t.Deps)+len(t.Recipe)+len(t.After) == 0 && t.Shebang == "" {
+			s.printf("\t:\n")
+		}
+		s.printf("}\n")
+	}
+
+	s.printf("\n")
+	if s.f.Default == "" {
+		s.printf("echo \"error: Justfile contains no recipes.\" >&2\nexit 1\n")
+		return
+	}
+	s.printf(`# Each recipe takes as many of the arguments after it as it has parameters
+(( $# )) || set -- %s
+while (( $# )); do
+	_name=${_aliases[$1]:-$1}
+	shift
+	if [[ ! -v _max_args[$_name] ]]; then
+		echo "error: Justfile does not contain recipe '$_name'." >&2
+		exit 1
+	fi
+	_take=${_max_args[$_name]}
+	(( _take >= 0 && _take <= $# )) || _take=$#
+	if (( _take < _min_args[$_name] )); then
+		echo "error: Recipe '$_name' got $_take arguments but takes at least ${_min_args[$_name]}" >&2
+		exit 1
+	fi
+	run_recipe "$_name" "${@:1:_take}"
+	shift "$_take"
+done
+`, s.f.Default)
+}
+
+// justDependency returns the call running a dependency, written "name" or
+// "(name argument...)"
+func (s *script) justDependency(dep string, line int) string {
+	words := []string{dep}
+	if strings.HasPrefix(dep, "(") && strings.HasSuffix(dep, ")") {
+		words = splitJustWords(dep[1 : len(dep)-1])
+	}
+	call := "run_recipe " + words[0]
+	for _, arg := range words[1:] {
+		call += " \"" + s.justExpression(arg, line) + "\""
+	}
+	return call
+}
+
+// justText converts a recipe line to the inside of a double-quoted bash
+// string, replacing {{expression}} interpolations
+func (s *script) justText(text string, line int) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); i++ {
+		switch {
+		case strings.HasPrefix(text[i:], "{{{{"):
+			sb.WriteString("{{")
+			i += 3
+		case strings.HasPrefix(text[i:], "{{"):
+			end := strings.Index(text[i:], "}}")
+			if end < 0 {
+				sb.WriteString(escape(text[i:], true))
+				return sb.String()
+			}
+			sb.WriteString(s.justExpression(text[i+2:i+end], line))
+			i += end + 1
+		default:
+			sb.WriteString(literal(text[i], true))
+		}
+	}
+	return sb.String()
+}
+
+// justExpression converts a just expression to the inside of a
+// double-quoted bash string. Strings, backticks, variables, env_var
+// calls and the + and / operators are converted; other expressions are
+// kept as written.
+func (s *script) justExpression(expr string, line int) string {
+	var sb strings.Builder
+	rest := strings.TrimSpace(expr)
+	for rest != "" {
+		var term string
+		var ok bool
+		term, rest, ok = s.justTerm(rest)
+		if !ok {
+			s.note(line, "just expression %q is kept as written", strings.TrimSpace(expr))
+			return escape(strings.TrimSpace(expr), true)
+		}
+		sb.WriteString(term)
+		rest = strings.TrimSpace(rest)
+		switch {
+		case rest == "":
+		case rest[0] == '+':
+			rest = strings.TrimSpace(rest[1:])
+		case rest[0] == '/':
+			sb.WriteString("/")
+			rest = strings.TrimSpace(rest[1:])
+		default:
+			s.note(line, "just expression %q is kept as written", strings.TrimSpace(expr))
+			return escape(strings.TrimSpace(expr), true)
+		}
+	}
+	return sb.String()
+}
+
+// justTerm converts the term at the start of expr and returns the rest
+func (s *script) justTerm(expr string) (string, string, bool) {
+	switch expr[0] {
+	case '\'', '"', '`':
+		end := strings.IndexByte(expr[1:], expr[0])
+		for expr[0] == '"' && end > 0 && expr[end] == '\\' {
+			next := strings.IndexByte(expr[end+2:], '"')
+			if next < 0 {
+				return "", "", false
+			}
+			end += next + 1
+		}
+		if end < 0 {
+			return "", "", false
+		}
+		token, rest := expr[:end+2], expr[end+2:]
+		if expr[0] == '`' {
+			return "$(" + token[1:len(token)-1] + ")", rest, true
+		}
+		return escape(unquoteJust(token), true), rest, true
+	}
+
+	name := justIdentifier.FindString(expr)
+	if name == "" {
+		return "", "", false
+	}
+	rest := expr[len(name):]
+	if !strings.HasPrefix(rest, "(") {
+		if name == "if" {
+			return "", "", false
+		}
+		return "${" + identifier(name) + "}", rest, true
+	}
+	end := closing(rest, 0)
+	if end < 0 {
+		return "", "", false
+	}
+	var args []string
+	for _, arg := range strings.Split(rest[1:end], ",") {
+		args = append(args, unquoteJust(arg))
+	}
+	rest = rest[end+1:]
+	switch {
+	case (name == "env_var" || name == "env") && len(args) == 1:
+		return "${" + args[0] + "}", rest, true
+	case (name == "env_var_or_default" || name == "env") && len(args) == 2:
+		return "${" + args[0] + ":-" + escape(args[1], true) + "}", rest, true
+	case name == "invocation_directory" || name == "justfile_directory":
+		return "${PWD}", rest, true
+	}
+	return "", "", false
+}
+
+// references reports whether f uses the make variable name
+func (f *File) references(name string) bool {
+	refs := []string{"$(" + name + ")", "${" + name + "}"}
+	contains := func(text string) bool {
+		for _, ref := range refs {
+			if strings.Contains(text, ref) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, v := range f.Variables {
+		if contains(v.Value) {
+			return true
+		}
+	}
+	for _, t := range f.Targets {
+		for _, r := range t.Recipe {
+			if contains(r.Text) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// defines reports whether f assigns the variable name
+func (f *File) defines(name string) bool {
+	for _, v := range f.Variables {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// flags returns the run_line flags of a recipe line
+func flags(r RecipeLine) string {
+	var flags string
+	if r.Quiet {
+		flags += "@"
+	}
+	if r.IgnoreErrors {
+		flags += "-"
+	}
+	return flags
+}
+
+// closing returns the index of the bracket closing the one at open, or -1
+func closing(text string, open int) int {
+	pair := map[byte]byte{'(': ')', '{': '}'}[text[open]]
+	depth := 0
+	for i := open; i < len(text); i++ {
+		switch text[i] {
+		case text[open]:
+			depth++
+		case pair:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// literal returns c, escaped for a double-quoted string when quoted
+func literal(c byte, quoted bool) string {
+	if quoted && (c == '"' || c == '\\' || c == '`' || c == '$') {
+		return `\` + string(c)
+	}
+	return string(c)
+}
+
+// escape escapes s for a double-quoted string when quoted
+func escape(s string, quoted bool) string {
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		sb.WriteString(literal(s[i], quoted))
+	}
+	return sb.String()
+}
+
+// shellQuote quotes s as a single shell word
+func shellQuote(s string) string {
+	if shellSafe.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// identifier turns a variable or target name into a bash identifier
+func identifier(name string) string {
+	id := []byte(name)
+	for i, c := range id {
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9') {
+			id[i] = '_'
+		}
+	}
+	if len(id) == 0 || id[0] >= '0' && id[0] <= '9' {
+		return "_" + string(id)
+	}
+	return string(id)
+}
+
+func dir(path string) string {
+	if i := strings.LastIndex(path, "/"); i >= 0 {
+		return path[:i]
+	}
+	return "."
+}
+
+func file(path string) string {
+	return path[strings.LastIndex(path, "/")+1:]
+}
+
+func unique(words []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, w := range words {
+		if !seen[w] {
+			seen[w] = true
+			out = append(out, w)
+		}
+	}
+	return out
+}
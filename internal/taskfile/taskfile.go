@@ -0,0 +1,138 @@
+package taskfile
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// Kind is the task runner a file is written for
+type Kind string
+
+const (
+	KindMake Kind = "make"
+	KindJust Kind = "just"
+)
+
+// File is a parsed Makefile or justfile
+type File struct {
+	Kind      Kind
+	Variables []Variable
+	Targets   []*Target
+	// Default is the target run when none is named
+	Default string
+	// Notes describe constructs that were kept as written or ignored, with
+	// their line numbers
+	Notes []string
+}
+
+// Variable is a variable assignment
+type Variable struct {
+	Name string
+	// Op is the assignment operator: "=", ":=", "::=", "?=", "+=" or "!="
+	// for make, ":=" for just
+	Op     string
+	Value  string
+	Export bool
+	Line   int
+}
+
+// Target is a make target or a just recipe
+type Target struct {
+	Name string
+	// Deps are the prerequisites or dependencies, run first. A just
+	// dependency with arguments is written "(name arg...)".
+	Deps []string
+	// After are just dependencies run after the recipe (those after &&)
+	After  []string
+	Params []Param
+	Recipe []RecipeLine
+	// Phony targets do not name a file; every just recipe is phony
+	Phony bool
+	// Shebang is the interpreter line of a just recipe whose body is a
+	// script, run as a whole instead of line by line
+	Shebang string
+	Doc     string
+	Aliases []string
+	Line    int
+}
+
+// Param is a parameter of a just recipe
+type Param struct {
+	Name    string
+	Default string
+	// HasDefault is set when a default is given, which may be empty
+	HasDefault bool
+	// Variadic is "+" for one or more arguments and "*" for zero or more
+	Variadic string
+	// Export passes the parameter to the recipe lines' environment
+	Export bool
+}
+
+// RecipeLine is one command of a recipe, with continuation lines joined
+type RecipeLine struct {
+	Text string
+	// Quiet lines (@) are not printed before they run, and failures of
+	// IgnoreErrors lines (-) do not stop the run
+	Quiet        bool
+	IgnoreErrors bool
+	Line         int
+}
+
+// Detect returns the kind of task file at path from its name, or "" when
+// it is not one
+func Detect(path string) Kind {
+	base := filepath.Base(path)
+	switch {
+	case base == "Makefile" || base == "makefile" || base == "GNUmakefile" || strings.EqualFold(filepath.Ext(base), ".mk"):
+		return KindMake
+	case strings.EqualFold(base, "justfile") || strings.EqualFold(base, ".justfile") || strings.EqualFold(filepath.Ext(base), ".just"):
+		return KindJust
+	}
+	return ""
+}
+
+// Parse parses the content of a task file of the given kind
+func Parse(kind Kind, content string) (*File, error) {
+	if kind == KindJust {
+		return parseJust(content)
+	}
+	return parseMake(content)
+}
+
+// Target returns the target with the given name, or nil
+func (f *File) Target(name string) *Target {
+	for _, t := range f.Targets {
+		if t.Name == name {
+			return t
+		}
+	}
+	return nil
+}
+
+// TargetNames returns the names of the targets in file order
+func (f *File) TargetNames() []string {
+	names := make([]string, len(f.Targets))
+	for i, t := range f.Targets {
+		names[i] = t.Name
+	}
+	return names
+}
+
+// logicalLines splits content into lines, joining lines that end in a
+// backslash with the next. Each line keeps the number of its first
+// physical line.
+func logicalLines(content string) ([]string, []int) {
+	var lines []string
+	var numbers []int
+	physical := strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	for i := 0; i < len(physical); i++ {
+		line, number := physical[i], i+1
+		for strings.HasSuffix(line, `\`) && i+1 < len(physical) {
+			i++
+			line += "\n" + physical[i]
+		}
+		lines = append(lines, line)
+		numbers = append(numbers, number)
+	}
+	return lines, numbers
+}
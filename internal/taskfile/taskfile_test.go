@@ -0,0 +1,329 @@
+package taskfile
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testMakefile = `# Build settings
+GO ?= go
+VERSION := $(shell echo 1.2.3)
+LDFLAGS = -X main.version=$(VERSION)
+FLAGS = -v
+FLAGS += -race
+export GREETING = hello
+
+.PHONY: all build test
+
+all: build test ## Build and test
+
+## Compile the binary
+build: gen
+	@echo "building with $(LDFLAGS)"
+	-false
+
+gen:
+	echo gen $@ \
+	  done
+
+test:
+	@echo "testing with $(FLAGS) $$GREETING"
+
+ifeq ($(GO),go)
+%.o: %.c
+	cc -c $<
+endif
+`
+
+const testJustfile = `set export
+
+version := ` + "`echo 1.2.3`" + `
+image := "app:" + version
+
+alias b := build
+
+# Build everything
+default: (build "release") test
+
+build profile="debug": gen
+    @echo "building {{profile}} {{image}}"
+
+gen:
+    echo gen
+
+test +packages="./...": && gen
+    echo "test {{packages}}"
+
+@quiet name:
+    echo "hello {{name}}"
+    @echo loud
+
+script:
+    #!/usr/bin/env bash
+    echo "script {{version}}"
+`
+
+func TestDetect(t *testing.T) {
+	tests := map[string]Kind{
+		"Makefile":          KindMake,
+		"dir/makefile":      KindMake,
+		"GNUmakefile":       KindMake,
+		"rules.mk":          KindMake,
+		"justfile":          KindJust,
+		"Justfile":          KindJust,
+		".justfile":         KindJust,
+		"release.just":      KindJust,
+		"build.sh":          "",
+		"Makefile.template": "",
+	}
+	for path, want := range tests {
+		if got := Detect(path); got != want {
+			t.Errorf("Detect(%q) = %q, want %q", path, got, want)
+		}
+	}
+}
+
+func TestParse_Make(t *testing.T) {
+	f, err := Parse(KindMake, testMakefile)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if f.Default != "all" {
+		t.Errorf("Default = %q, want all", f.Default)
+	}
+	if got := f.TargetNames(); !reflect.DeepEqual(got, []string{"all", "build", "gen", "test"}) {
+		t.Errorf("TargetNames() = %v", got)
+	}
+
+	var ops []string
+	for _, v := range f.Variables {
+		ops = append(ops, v.Name+v.Op)
+	}
+	if want := []string{"GO?=", "VERSION:=", "LDFLAGS=", "FLAGS=", "FLAGS+=", "GREETING="}; !reflect.DeepEqual(ops, want) {
+		t.Errorf("Variables = %v, want %v", ops, want)
+	}
+	if !f.Variables[5].Export {
+		t.Error("GREETING is not exported")
+	}
+
+	all, build, gen := f.Target("all"), f.Target("build"), f.Target("gen")
+	if !reflect.DeepEqual(all.Deps, []string{"build", "test"}) || all.Doc != "Build and test" || !all.Phony {
+		t.Errorf("all = %+v", all)
+	}
+	if build.Doc != "Compile the binary" || len(build.Recipe) != 2 {
+		t.Fatalf("build = %+v", build)
+	}
+	if r := build.Recipe[0]; !r.Quiet || r.IgnoreErrors || r.Line != 15 {
+		t.Errorf("build recipe line 1 = %+v", r)
+	}
+	if r := build.Recipe[1]; r.Quiet || !r.IgnoreErrors || r.Text != "false" {
+		t.Errorf("build recipe line 2 = %+v", r)
+	}
+	if gen.Phony || len(gen.Recipe) != 1 || gen.Recipe[0].Text != "echo gen $@ \\\n  done" {
+		t.Errorf("gen = %+v", gen)
+	}
+
+	if len(f.Notes) != 2 || !strings.Contains(f.Notes[0], "ifeq") || !strings.Contains(f.Notes[1], "pattern rule") {
+		t.Errorf("Notes = %q", f.Notes)
+	}
+}
+
+func TestParse_MakeDefaultGoal(t *testing.T) {
+	f, err := Parse(KindMake, ".DEFAULT_GOAL := test\nbuild:\n\tgo build\ntest:\n\tgo test\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if f.Default != "test" {
+		t.Errorf("Default = %q, want test", f.Default)
+	}
+}
+
+func TestParse_Just(t *testing.T) {
+	f, err := Parse(KindJust, testJustfile)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if f.Default != "default" {
+		t.Errorf("Default = %q, want default", f.Default)
+	}
+	if len(f.Variables) != 2 || !f.Variables[1].Export || f.Variables[1].Value != `"app:" + version` {
+		t.Errorf("Variables = %+v", f.Variables)
+	}
+
+	def, build, test := f.Target("default"), f.Target("build"), f.Target("test")
+	if !reflect.DeepEqual(def.Deps, []string{`(build "release")`, "test"}) || def.Doc != "Build everything" {
+		t.Errorf("default = %+v", def)
+	}
+	if want := []Param{{Name: "profile", Default: `"debug"`, HasDefault: true, Export: true}}; !reflect.DeepEqual(build.Params, want) {
+		t.Errorf("build params = %+v, want %+v", build.Params, want)
+	}
+	if !reflect.DeepEqual(build.Aliases, []string{"b"}) {
+		t.Errorf("build aliases = %v", build.Aliases)
+	}
+	if test.Params[0].Variadic != "+" || !reflect.DeepEqual(test.After, []string{"gen"}) {
+		t.Errorf("test = %+v", test)
+	}
+
+	// A quiet recipe prints only its @ lines
+	quiet := f.Target("quiet").Recipe
+	if !quiet[0].Quiet || quiet[1].Quiet {
+		t.Errorf("quiet recipe = %+v", quiet)
+	}
+
+	script := f.Target("script")
+	if script.Shebang != "#!/usr/bin/env bash" || len(script.Recipe) != 1 {
+		t.Errorf("script = %+v", script)
+	}
+}
+
+func TestParse_JustErrors(t *testing.T) {
+	tests := map[string]string{
+		"duplicate recipe": "build:\n    echo a\nbuild:\n    echo b\n",
+		"unknown alias":    "alias b := build\n",
+		"not a recipe":     "echo hello\n",
+	}
+	for name, content := range tests {
+		if _, err := Parse(KindJust, content); err == nil {
+			t.Errorf("%s: Parse() error = nil", name)
+		}
+	}
+}
+
+// runScript writes the script of a task file and runs it with bash
+func runScript(t *testing.T, kind Kind, content string, args ...string) (string, error) {
+	t.Helper()
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+
+	f, err := Parse(kind, content)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "run.sh")
+	if err := os.WriteFile(path, []byte(f.Script("tasks")), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command("bash", append([]string{path}, args...)...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	return string(out), err
+}
+
+func TestScript_Make(t *testing.T) {
+	out, err := runScript(t, KindMake, testMakefile, "FLAGS=-x", "all", "build")
+	if err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+
+	want := `echo gen gen \
+  done
+gen gen done
+building with -X main.version=1.2.3
+false
+make: [tasks:16: build] Error 1 (ignored)
+testing with -x hello
+`
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestScript_MakeFailure(t *testing.T) {
+	out, err := runScript(t, KindMake, "all:\n\t@false\n\t@echo unreachable\n")
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 2 {
+		t.Fatalf("script error = %v, want exit status 2", err)
+	}
+	if out != "make: *** [tasks:2: all] Error 1\n" {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestScript_Just(t *testing.T) {
+	out, err := runScript(t, KindJust, testJustfile)
+	if err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+	want := "echo gen\ngen\nbuilding release app:1.2.3\necho \"test ./...\"\ntest ./...\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+
+	// build takes one optional argument, so it comes last
+	out, err = runScript(t, KindJust, testJustfile, "version=9", "quiet", "bob", "script", "b")
+	if err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+	want = "hello bob\necho loud\nloud\nscript 9\necho gen\ngen\nbuilding debug app:9\n"
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestScript_JustMissingArgument(t *testing.T) {
+	out, err := runScript(t, KindJust, testJustfile, "quiet")
+	if err == nil || !strings.Contains(out, "takes at least 1") {
+		t.Errorf("script error = %v, output = %q", err, out)
+	}
+}
+
+const variableMakefile = `BIN := app
+SRCS = main.c util.c
+OBJS = $(SRCS:.c=.o) extra.o
+OUT = $(BIN)
+
+all: $(OUT)
+
+$(BIN): main.o
+	@echo link $@ from $^
+
+main.o: ; @echo compile $@
+`
+
+func TestParseMake_VariableTargets(t *testing.T) {
+	f, err := Parse(KindMake, variableMakefile)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	if got := f.TargetNames(); !reflect.DeepEqual(got, []string{"all", "app", "main.o"}) {
+		t.Errorf("TargetNames() = %v", got)
+	}
+	if all := f.Target("all"); all == nil || !reflect.DeepEqual(all.Deps, []string{"app"}) {
+		t.Errorf("all = %+v, expected the prerequisite app", all)
+	}
+	if app := f.Target("app"); app == nil || len(app.Recipe) != 1 || app.Recipe[0].Text != "echo link $@ from $^" {
+		t.Errorf("app = %+v, expected its recipe unexpanded", app)
+	}
+
+	// Substitution references are expanded; variables the Makefile does not
+	// define, such as ones set in the environment, are kept with a note
+	f, err = Parse(KindMake, variableMakefile+"pack: $(OBJS) $(EXTRA)\n")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if pack := f.Target("pack"); pack == nil || !reflect.DeepEqual(pack.Deps, []string{"main.o", "util.o", "extra.o", "$(EXTRA)"}) {
+		t.Errorf("pack = %+v", pack)
+	}
+	if len(f.Notes) != 1 || !strings.Contains(f.Notes[0], "kept as written") {
+		t.Errorf("Notes = %v", f.Notes)
+	}
+}
+
+func TestScript_MakeVariableTargets(t *testing.T) {
+	out, err := runScript(t, KindMake, variableMakefile)
+	if err != nil {
+		t.Fatalf("script failed: %v\n%s", err, out)
+	}
+	if want := "compile main.o\nlink app from main.o\n"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}